@@ -1,21 +1,244 @@
 package commands
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"io"
+	"log"
 	"net"
+	"strings"
 	"time"
 
-	samqp "github.com/streadway/amqp"
+	"github.com/ipfs/go-cid"
 
+	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue/disk"
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
 	"github.com/ipfs-search/ipfs-search/utils"
 )
 
-// AddHash queues a single IPFS hash for indexing
-func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
+// maxPriority is the highest priority supported by the queues.
+const maxPriority = 9
+
+// progressInterval is the interval at which progress is reported while adding.
+const progressInterval = 10 * time.Second
+
+// AddOptions configures how resources are added.
+type AddOptions struct {
+	// Type hint; files and directories are added to their respective queues, resources of undefined type
+	// to the hashes queue. The hint only selects the queue: resources are still statted when crawled, so that
+	// their size is known.
+	Type t.ResourceType
+
+	// Priority of the added resources; higher number, higher priority.
+	Priority uint8
+}
+
+// addQueues holds publishers for the various types of resources.
+type addQueues struct {
+	Files       queue.Publisher
+	Directories queue.Publisher
+	Hashes      queue.Publisher
+}
+
+// get returns the publisher for resources of type rType.
+func (q *addQueues) get(rType t.ResourceType) queue.Publisher {
+	switch rType {
+	case t.FileType:
+		return q.Files
+	case t.DirectoryType:
+		return q.Directories
+	default:
+		return q.Hashes
+	}
+}
+
+// getAMQPAddQueues returns queues on a single channel in confirm mode, so that publishing returns once the server
+// has taken responsibility for the resource.
+func getAMQPAddQueues(ctx context.Context, cfg *config.Config, dialer *utils.RetryingDialer, i *instr.Instrumentation) (*addQueues, func() error, error) {
+	amqpConfig, err := amqp.DialConfig(dialer.Dial, cfg.AMQP.TLSConfig(), cfg.AMQP.CredentialsConfig())
+	if err != nil {
+		return nil, nil, fmt.Errorf("configuring AMQP: %w", err)
+	}
+
+	conn, err := amqp.NewConnection(ctx, cfg.AMQPConfig(), amqpConfig, i)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := conn.ConfirmChannel(ctx)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	var qs addQueues
+
+	if qs.Files, err = ch.Queue(ctx, cfg.Queues.Files.Name); err == nil {
+		if qs.Directories, err = ch.Queue(ctx, cfg.Queues.Directories.Name); err == nil {
+			qs.Hashes, err = ch.Queue(ctx, cfg.Queues.Hashes.Name)
+		}
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return &qs, conn.Close, nil
+}
+
+// getRedisAddQueues returns queues sharing a single Redis client.
+func getRedisAddQueues(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (*addQueues, func() error, error) {
+	redisConfig := cfg.RedisConfig()
+
+	client, err := redis.NewClient(ctx, redisConfig, i)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var qs addQueues
+
+	if qs.Files, err = redis.NewQueue(ctx, client, redisConfig, cfg.Queues.Files.Name, i); err == nil {
+		if qs.Directories, err = redis.NewQueue(ctx, client, redisConfig, cfg.Queues.Directories.Name, i); err == nil {
+			qs.Hashes, err = redis.NewQueue(ctx, client, redisConfig, cfg.Queues.Hashes.Name, i)
+		}
+	}
+
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	return &qs, client.Close, nil
+}
+
+// getDiskAddQueues returns queues in the database on local disk. As the crawler holds an exclusive lock on the
+// database, resources can only be added while it is stopped; they are crawled once it is started.
+func getDiskAddQueues(cfg *config.Config, i *instr.Instrumentation) (*addQueues, func() error, error) {
+	b, err := disk.Open(cfg.DiskQueueConfig(), i)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening %s (is the crawler running?): %w", cfg.Queues.Path, err)
+	}
+
+	var qs addQueues
+
+	if qs.Files, err = b.Queue(cfg.Queues.Files.Name); err == nil {
+		if qs.Directories, err = b.Queue(cfg.Queues.Directories.Name); err == nil {
+			qs.Hashes, err = b.Queue(cfg.Queues.Hashes.Name)
+		}
+	}
+
+	if err != nil {
+		b.Close()
+		return nil, nil, err
+	}
+
+	return &qs, b.Close, nil
+}
+
+// getAddQueues returns publishers to the configured queues on the configured backend, along with a function
+// closing their connection.
+func getAddQueues(ctx context.Context, cfg *config.Config, dialer *utils.RetryingDialer, i *instr.Instrumentation) (*addQueues, func() error, error) {
+	switch cfg.Queues.Backend {
+	case config.AMQPBackend:
+		return getAMQPAddQueues(ctx, cfg, dialer, i)
+	case config.RedisBackend:
+		return getRedisAddQueues(ctx, cfg, i)
+	case config.DiskBackend:
+		return getDiskAddQueues(cfg, i)
+	default:
+		return nil, nil, fmt.Errorf("adding is unsupported for queue backend '%s'", cfg.Queues.Backend)
+	}
+}
+
+// adder adds resources to the queues, keeping track of progress.
+type adder struct {
+	queues   *addQueues
+	resolver resolvingProtocol
+	opts     *AddOptions
+
+	start, reported time.Time
+	added, failed   int
+}
+
+// add resolves and validates a CID or path and publishes the resource to the queue for its type.
+func (a *adder) add(ctx context.Context, path string) error {
+	r, err := a.resolver.Resolve(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	if r.Protocol == t.IPFSProtocol {
+		if _, err := cid.Decode(r.ID); err != nil {
+			return fmt.Errorf("%w: %s: %v", t.ErrInvalidResource, r.ID, err)
+		}
+	}
+
+	// Resources resolved within a directory have a known type, otherwise it is left to the crawler.
+	rType := r.Type
+	if a.opts.Type != t.UndefinedType {
+		rType = a.opts.Type
+	}
+
+	return a.queues.get(rType).Publish(ctx, r, a.opts.Priority)
+}
+
+// report logs the number of added and failed resources and the rate at which they were added.
+func (a *adder) report() {
+	elapsed := time.Since(a.start)
+
+	log.Printf("Added %d resources in %s (%.1f/s), %d failed",
+		a.added, elapsed.Round(time.Millisecond), float64(a.added)/elapsed.Seconds(), a.failed)
+
+	a.reported = time.Now()
+}
+
+// run adds the resources read line by line from r, reporting progress periodically.
+func (a *adder) run(ctx context.Context, r io.Reader) error {
+	a.start = time.Now()
+	a.reported = a.start
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		path := strings.TrimSpace(scanner.Text())
+		if path == "" || strings.HasPrefix(path, "#") {
+			continue
+		}
+
+		if err := a.add(ctx, path); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			log.Printf("Error adding '%s': %v", path, err)
+			a.failed++
+		} else {
+			a.added++
+		}
+
+		if time.Since(a.reported) >= progressInterval {
+			a.report()
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Add queues the CIDs or IPFS paths, e.g. `/ipfs/<cid>/sub/path`, read line by line from r for crawling. With the
+// filesystem protocol backend, paths are relative to its root. Empty lines and lines starting with # are skipped.
+// Paths within a directory are resolved through the protocol, referencing their parent directory. Resources which cannot be added are reported, after which adding continues.
+func Add(ctx context.Context, cfg *config.Config, r io.Reader, opts *AddOptions) error {
+	if opts.Priority > maxPriority {
+		return fmt.Errorf("priority %d exceeds maximum of %d", opts.Priority, maxPriority)
+	}
+
 	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler add")
 	if err != nil {
 		return err
@@ -33,32 +256,33 @@ func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 		Context: ctx,
 	}
 
-	amqpConfig := &samqp.Config{
-		Dial: dialer.Dial,
-	}
-
-	f := amqp.PublisherFactory{
-		Config:          cfg.AMQPConfig(),
-		Queue:           "hashes",
-		AMQPConfig:      amqpConfig,
-		Instrumentation: i,
+	resolver, err := getProtocol(cfg, dialer, i)
+	if err != nil {
+		return err
 	}
 
-	queue, err := f.NewPublisher(ctx)
+	queues, closeQueues, err := getAddQueues(ctx, cfg, dialer, i)
 	if err != nil {
 		return err
 	}
+	defer closeQueues()
 
-	resource := &t.Resource{
-		Protocol: t.IPFSProtocol,
-		ID:       hash,
+	a := &adder{
+		queues:   queues,
+		resolver: resolver,
+		opts:     opts,
+	}
+
+	err = a.run(ctx, r)
+	a.report()
+
+	if err != nil {
+		return err
 	}
 
-	provider := t.Provider{
-		Resource: resource,
-		Date:     time.Now(),
+	if a.failed > 0 {
+		return fmt.Errorf("failed to add %d of %d resources", a.failed, a.added+a.failed)
 	}
 
-	// Add with highest priority, as this is supposed to be available
-	return queue.Publish(ctx, provider, 9)
+	return nil
 }
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/queue/disk"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+var errUnresolvable = errors.New("unresolvable")
+
+// pathResolver resolves paths to IPFS resources with the path as ID. Paths within a directory resolve to files
+// and "unresolvable" fails.
+type pathResolver struct {
+	protocol.Protocol
+}
+
+func (pathResolver) Resolve(ctx context.Context, path string) (*t.AnnotatedResource, error) {
+	if path == "unresolvable" {
+		return nil, errUnresolvable
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: path},
+	}
+
+	if i := strings.Index(path, "/"); i >= 0 {
+		r.ID = path[i+1:]
+		r.Type = t.FileType
+		r.Size = 5
+	}
+
+	return r, nil
+}
+
+// published is a resource published to a recordingPublisher.
+type published struct {
+	r        *t.AnnotatedResource
+	priority uint8
+}
+
+// recordingPublisher records published resources.
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published []published
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.published = append(p.published, published{params.(*t.AnnotatedResource), priority})
+
+	return nil
+}
+
+const (
+	cid1 = "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
+	cid2 = "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+)
+
+type AddTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	cfg *config.Config
+
+	files, directories, hashes *recordingPublisher
+	opts                       *AddOptions
+	adder                      *adder
+}
+
+func (s *AddTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.files = new(recordingPublisher)
+	s.directories = new(recordingPublisher)
+	s.hashes = new(recordingPublisher)
+	s.opts = &AddOptions{Priority: 9}
+
+	s.adder = &adder{
+		queues:   &addQueues{s.files, s.directories, s.hashes},
+		resolver: pathResolver{},
+		opts:     s.opts,
+	}
+
+	s.cfg = config.Default()
+	s.cfg.Queues.Backend = config.DiskBackend
+	s.cfg.Queues.Path = filepath.Join(s.T().TempDir(), "queues.db")
+}
+
+// queueLen returns the number of items in a disk queue.
+func (s *AddTestSuite) queueLen(b *disk.Broker, name string) int {
+	q, err := b.Queue(name)
+	s.Require().NoError(err)
+
+	n, err := q.Len()
+	s.Require().NoError(err)
+
+	return n
+}
+
+func (s *AddTestSuite) TestSkipsBlanksAndComments() {
+	input := "\n# A comment\n  " + cid1 + "  \n\t\n#" + cid2 + "\n"
+
+	s.NoError(s.adder.run(s.ctx, strings.NewReader(input)))
+
+	s.Equal(1, s.adder.added)
+	s.Equal(0, s.adder.failed)
+	s.Require().Len(s.hashes.published, 1)
+	s.Equal(cid1, s.hashes.published[0].r.ID)
+	s.Equal(uint8(9), s.hashes.published[0].priority)
+}
+
+func (s *AddTestSuite) TestRoutesByType() {
+	// Resolved within a directory: the file's type is known.
+	input := cid1 + "\n" + cid2 + "/" + cid1 + "\n"
+
+	s.NoError(s.adder.run(s.ctx, strings.NewReader(input)))
+
+	s.Len(s.hashes.published, 1)
+	s.Require().Len(s.files.published, 1)
+	s.Equal(t.FileType, s.files.published[0].r.Type)
+}
+
+func (s *AddTestSuite) TestTypeHint() {
+	s.opts.Type = t.FileType
+	s.opts.Priority = 3
+
+	s.NoError(s.adder.run(s.ctx, strings.NewReader(cid1)))
+
+	s.Require().Len(s.files.published, 1)
+	s.Empty(s.hashes.published)
+
+	// The hint only selects the queue; the crawler stats the resource to get its size.
+	p := s.files.published[0]
+	s.Equal(t.UndefinedType, p.r.Type)
+	s.Equal(uint8(3), p.priority)
+}
+
+func (s *AddTestSuite) TestCountsFailures() {
+	input := strings.Join([]string{cid1, "unresolvable", "notacid", cid2}, "\n")
+
+	s.NoError(s.adder.run(s.ctx, strings.NewReader(input)))
+
+	s.Equal(2, s.adder.added)
+	s.Equal(2, s.adder.failed)
+	s.Len(s.hashes.published, 2)
+}
+
+func (s *AddTestSuite) TestContextCanceled() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+
+	err := s.adder.run(ctx, strings.NewReader("unresolvable"))
+	s.Equal(context.Canceled, err)
+}
+
+func (s *AddTestSuite) TestPriorityBounds() {
+	err := Add(s.ctx, s.cfg, strings.NewReader(cid1), &AddOptions{Priority: maxPriority + 1})
+	s.Error(err)
+	s.Contains(err.Error(), "exceeds maximum")
+}
+
+func (s *AddTestSuite) TestDiskQueues() {
+	queues, closeQueues, err := getAddQueues(s.ctx, s.cfg, nil, instr.New())
+	s.Require().NoError(err)
+
+	a := &adder{
+		queues:   queues,
+		resolver: pathResolver{},
+		opts:     &AddOptions{Type: t.DirectoryType},
+	}
+
+	input := cid1 + "\n" + cid2 + "\n"
+	s.NoError(a.run(s.ctx, strings.NewReader(input)))
+	s.Equal(2, a.added)
+	s.NoError(closeQueues())
+
+	// Resources are available to a crawler started afterwards.
+	b, err := disk.Open(s.cfg.DiskQueueConfig(), instr.New())
+	s.Require().NoError(err)
+	defer b.Close()
+
+	s.Equal(2, s.queueLen(b, s.cfg.Queues.Directories.Name))
+	s.Equal(0, s.queueLen(b, s.cfg.Queues.Hashes.Name))
+}
+
+func (s *AddTestSuite) TestDiskQueuesLocked() {
+	// Held by a running crawler.
+	b, err := disk.Open(s.cfg.DiskQueueConfig(), instr.New())
+	s.Require().NoError(err)
+	defer b.Close()
+
+	_, _, err = getAddQueues(s.ctx, s.cfg, nil, instr.New())
+	s.Error(err)
+	s.Contains(err.Error(), "is the crawler running?")
+}
+
+func TestAddTestSuite(t *testing.T) {
+	suite.Run(t, new(AddTestSuite))
+}
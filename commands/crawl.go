@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 
+	"github.com/ipfs-search/ipfs-search/components/crawler/admin"
 	"github.com/ipfs-search/ipfs-search/components/crawler/worker"
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
@@ -10,8 +11,10 @@ import (
 	"log"
 )
 
-// Crawl configures and initializes crawling
-func Crawl(ctx context.Context, cfg *config.Config) error {
+// Crawl configures and initializes crawling. When the context closes, consumption stops and in-flight
+// crawls are given the configured grace period to finish before connections are closed.
+// Worker pools are resized to match configurations received on reloads.
+func Crawl(ctx context.Context, cfg *config.Config, reloads <-chan *config.Config) error {
 	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler")
 	if err != nil {
 		log.Fatal(err)
@@ -23,15 +26,56 @@ func Crawl(ctx context.Context, cfg *config.Config) error {
 	ctx, span := i.Tracer.Start(ctx, "commands.Crawl")
 	defer span.End()
 
-	c, err := worker.NewPool(ctx, cfg, i)
+	// Connections outlive ctx, allowing in-flight crawls to finish on shutdown.
+	poolCtx, closePool := context.WithCancel(context.Background())
+	defer closePool()
+
+	if err := instr.ServeMetrics(poolCtx, cfg.Instr.MetricsAddress); err != nil {
+		return err
+	}
+
+	c, err := worker.NewPool(poolCtx, cfg, i)
 	if err != nil {
 		return err
 	}
 
+	if cfg.Admin.Enabled {
+		if err := admin.New(&cfg.Admin, c, i).Serve(poolCtx); err != nil {
+			return err
+		}
+	}
+
 	c.Start(ctx)
 
+	go reconfigure(ctx, c, reloads)
+
 	// Context closure or panic is the only way to stop crawling
 	<-ctx.Done()
 
+	log.Printf("Shutting down, allowing %s for in-flight crawls to finish", cfg.Workers.ShutdownGrace)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Workers.ShutdownGrace)
+	defer cancel()
+
+	if err := c.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
 	return ctx.Err()
 }
+
+// reconfigure resizes the worker pools for reloaded configurations until ctx is done.
+func reconfigure(ctx context.Context, c *worker.Pool, reloads <-chan *config.Config) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cfg := <-reloads:
+			log.Println("Configuration reloaded; resizing worker pools. Other changes require a restart.")
+
+			if err := c.Reconfigure(ctx, &cfg.Workers); err != nil {
+				log.Printf("Error resizing worker pools: %v", err)
+			}
+		}
+	}
+}
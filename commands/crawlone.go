@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/extractor/tika"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/bleve"
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// errNotConsumable is returned when consuming from the queues of a single crawl.
+var errNotConsumable = errors.New("queue can only be published to")
+
+// printingQueue prints published resources instead of queueing them.
+type printingQueue struct {
+	name string
+}
+
+// Publish prints the published resource.
+func (q *printingQueue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	fmt.Printf("Child for queue %s (priority %d): %v\n", q.name, priority, params)
+	return nil
+}
+
+// Consume is not supported.
+func (q *printingQueue) Consume(ctx context.Context) (<-chan queue.Delivery, error) {
+	return nil, errNotConsumable
+}
+
+// Cancel is not supported.
+func (q *printingQueue) Cancel(ctx context.Context) error {
+	return errNotConsumable
+}
+
+// printingIndex prints documents written to an index, optionally skipping the write.
+type printingIndex struct {
+	idx    index.Index
+	name   string
+	dryRun bool
+}
+
+// print writes the operation and document as JSON to standard output.
+func (i *printingIndex) print(op string, id string, properties interface{}) error {
+	doc, err := json.MarshalIndent(properties, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	suffix := ""
+	if i.dryRun {
+		suffix = " (dry run, not written)"
+	}
+
+	fmt.Printf("%s %s in index %s%s:\n%s\n", op, id, i.name, suffix, doc)
+
+	return nil
+}
+
+// Index prints the document and indexes it unless dry running.
+func (i *printingIndex) Index(ctx context.Context, id string, properties interface{}) error {
+	if err := i.print("Index", id, properties); err != nil {
+		return err
+	}
+
+	if i.dryRun {
+		return nil
+	}
+
+	return i.idx.Index(ctx, id, properties)
+}
+
+// Update prints the updated properties and updates the document unless dry running.
+func (i *printingIndex) Update(ctx context.Context, id string, properties interface{}) error {
+	if err := i.print("Update", id, properties); err != nil {
+		return err
+	}
+
+	if i.dryRun {
+		return nil
+	}
+
+	return i.idx.Update(ctx, id, properties)
+}
+
+// Get retrieves the document from the index, such that existing documents are updated.
+func (i *printingIndex) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	return i.idx.Get(ctx, id, dst, fields...)
+}
+
+// getCrawlIndex returns a single index for crawling, without bulk indexing, and a function closing it.
+func getCrawlIndex(cfg *config.Config, c config.Index, esClient func() (*elastic.Client, error), i *instr.Instrumentation) (index.Index, func() error, error) {
+	switch c.Backend {
+	case config.ElasticsearchBackend:
+		es, err := esClient()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return elasticsearch.New(es, &elasticsearch.Config{Name: c.Name}, i), func() error { return nil }, nil
+
+	case config.BleveBackend:
+		idx, err := bleve.New(cfg.BleveConfig(c.Name), i)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return idx, idx.Close, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown backend '%s' for index %s", c.Backend, c.Name)
+	}
+}
+
+// getCrawlIndexes returns printing indexes for a single crawl and a function closing them.
+func getCrawlIndexes(cfg *config.Config, dialer *utils.RetryingDialer, dryRun bool, i *instr.Instrumentation) (*crawler.Indexes, func(), error) {
+	var (
+		es      *elastic.Client
+		closers []func() error
+	)
+
+	esClient := func() (*elastic.Client, error) {
+		if es != nil {
+			return es, nil
+		}
+
+		var err error
+		es, err = getElasticClient(cfg, dialer)
+
+		return es, err
+	}
+
+	closeAll := func() {
+		for _, f := range closers {
+			if err := f(); err != nil {
+				log.Printf("Error closing index: %v", err)
+			}
+		}
+	}
+
+	get := func(c config.Index) (index.Index, error) {
+		idx, closer, err := getCrawlIndex(cfg, c, esClient, i)
+		if err != nil {
+			return nil, err
+		}
+
+		closers = append(closers, closer)
+
+		return &printingIndex{idx, c.Name, dryRun}, nil
+	}
+
+	var (
+		indexes = new(crawler.Indexes)
+		err     error
+	)
+
+	if indexes.Files, err = get(cfg.Indexes.Files); err == nil {
+		if indexes.Directories, err = get(cfg.Indexes.Directories); err == nil {
+			indexes.Invalids, err = get(cfg.Indexes.Invalids)
+		}
+	}
+
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	return indexes, closeAll, nil
+}
+
+// CrawlOne crawls a single CID or path in-process, printing the resulting documents and the indexes they are
+// written to. Directory entries are printed instead of queued. With dryRun, nothing is written to the indexes.
+func CrawlOne(ctx context.Context, cfg *config.Config, path string, dryRun bool) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler crawl-one")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	p, err := getProtocol(cfg, dialer, i)
+	if err != nil {
+		return err
+	}
+
+	if s, ok := p.(server); ok {
+		if err := s.Serve(ctx); err != nil {
+			return err
+		}
+	}
+
+	tikaClient, err := utils.GetSecureHTTPClient(dialer.DialContext, 10, cfg.Tika.TLSConfig(), cfg.Tika.CredentialsConfig())
+	if err != nil {
+		return fmt.Errorf("configuring Tika client: %w", err)
+	}
+
+	extractor := tika.New(cfg.TikaConfig(), tikaClient, p, i)
+
+	indexes, closeIndexes, err := getCrawlIndexes(cfg, dialer, dryRun, i)
+	if err != nil {
+		return err
+	}
+	defer closeIndexes()
+
+	queues := &crawler.Queues{
+		Files:       &printingQueue{cfg.Queues.Files.Name},
+		Directories: &printingQueue{cfg.Queues.Directories.Name},
+		Hashes:      &printingQueue{cfg.Queues.Hashes.Name},
+	}
+
+	c := crawler.New(cfg.CrawlerConfig(), indexes, queues, p, extractor, i)
+
+	r, err := p.Resolve(ctx, path)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", path, err)
+	}
+
+	fmt.Printf("Crawling %s\n", r)
+
+	if err := c.Crawl(ctx, r); err != nil {
+		return fmt.Errorf("crawling %s: %w", r, err)
+	}
+
+	fmt.Printf("Crawled %s: %s\n", r, r.Type)
+
+	return nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ queue.Queue = &printingQueue{}
+	_ index.Index = &printingIndex{}
+)
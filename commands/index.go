@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch/schema"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// getElasticClient returns an Elasticsearch client, connecting with the configured TLS settings and credentials.
+func getElasticClient(cfg *config.Config, dialer *utils.RetryingDialer) (*elastic.Client, error) {
+	httpClient, err := utils.GetSecureHTTPClient(dialer.DialContext, 5,
+		cfg.ElasticSearch.TLSConfig(), cfg.ElasticSearch.CredentialsConfig())
+	if err != nil {
+		return nil, fmt.Errorf("configuring Elasticsearch client: %w", err)
+	}
+
+	return elastic.NewClient(
+		elastic.SetSniff(false),
+		elastic.SetURL(cfg.ElasticSearch.URL),
+		elastic.SetHttpClient(httpClient),
+	)
+}
+
+// getSchemaManager returns a schema manager and the Elasticsearch-backed indexes it manages.
+func getSchemaManager(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (*schema.Manager, []schema.Index, error) {
+	indexes := []schema.Index{}
+
+	for _, c := range []struct {
+		index   config.Index
+		mapping schema.Mapping
+	}{
+		{cfg.Indexes.Files, schema.Files},
+		{cfg.Indexes.Directories, schema.Directories},
+		{cfg.Indexes.Invalids, schema.Invalids},
+	} {
+		if c.index.Backend != config.ElasticsearchBackend {
+			log.Printf("Skipping index %s with backend %s", c.index.Name, c.index.Backend)
+			continue
+		}
+
+		indexes = append(indexes, schema.Index{
+			Alias:   c.index.Name,
+			Mapping: c.mapping,
+		})
+	}
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	es, err := getElasticClient(cfg, dialer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schema.New(es, i), indexes, nil
+}
+
+// IndexInit creates indexes which do not exist yet, with their current mappings.
+func IndexInit(ctx context.Context, cfg *config.Config) error {
+	m, indexes, err := getSchemaManager(ctx, cfg, instr.New())
+	if err != nil {
+		return err
+	}
+
+	for _, i := range indexes {
+		created, err := m.Init(ctx, i)
+		if err != nil {
+			return fmt.Errorf("initializing %s: %w", i.Alias, err)
+		}
+
+		if created {
+			fmt.Printf("Created index %s\n", i.Alias)
+		} else {
+			fmt.Printf("Index %s already exists\n", i.Alias)
+		}
+	}
+
+	return nil
+}
+
+// IndexMigrate migrates existing indexes to a new version with the current mappings.
+// The crawler should be stopped during migration, as documents indexed meanwhile might get lost.
+func IndexMigrate(ctx context.Context, cfg *config.Config) error {
+	m, indexes, err := getSchemaManager(ctx, cfg, instr.New())
+	if err != nil {
+		return err
+	}
+
+	for _, i := range indexes {
+		fmt.Printf("Migrating index %s\n", i.Alias)
+
+		previous, err := m.Migrate(ctx, i, func(p schema.Progress) {
+			fmt.Printf("%s: %d/%d documents\n", i.Alias, p.Done(), p.Total)
+		})
+		if err != nil {
+			if previous != "" {
+				fmt.Printf("Reindexing from %s failed; it can be retried as described in docs/indices\n", previous)
+			}
+
+			return fmt.Errorf("migrating %s: %w", i.Alias, err)
+		}
+
+		fmt.Printf("Migrated index %s; previous version %s can be removed after verification\n", i.Alias, previous)
+	}
+
+	return nil
+}
+
+// IndexStatus prints the current version and document count of indexes.
+func IndexStatus(ctx context.Context, cfg *config.Config) error {
+	m, indexes, err := getSchemaManager(ctx, cfg, instr.New())
+	if err != nil {
+		return err
+	}
+
+	for _, i := range indexes {
+		s, err := m.Status(ctx, i)
+		if err != nil {
+			return fmt.Errorf("getting status for %s: %w", i.Alias, err)
+		}
+
+		if s.Index == "" {
+			fmt.Printf("%s: not initialized\n", s.Alias)
+			continue
+		}
+
+		fmt.Printf("%s: %s (version %d), %d documents\n", s.Alias, s.Index, s.Version, s.Docs)
+	}
+
+	return nil
+}
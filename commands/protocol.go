@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/protocol/car"
+	"github.com/ipfs-search/ipfs-search/components/protocol/filesystem"
+	"github.com/ipfs-search/ipfs-search/components/protocol/gateway"
+	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// resolvingProtocol is a protocol which resolves paths given on the command line to resources.
+type resolvingProtocol interface {
+	protocol.Protocol
+	Resolve(context.Context, string) (*t.AnnotatedResource, error)
+}
+
+// server is implemented by protocols serving resource contents to the extractor.
+type server interface {
+	Serve(context.Context) error
+}
+
+// getProtocol returns the configured protocol backend.
+func getProtocol(cfg *config.Config, dialer *utils.RetryingDialer, i *instr.Instrumentation) (resolvingProtocol, error) {
+	switch cfg.Protocol.Backend {
+	case config.IPFSBackend:
+		client, err := utils.GetSecureHTTPClient(dialer.DialContext, 10, cfg.IPFS.TLSConfig(), cfg.IPFS.CredentialsConfig())
+		if err != nil {
+			return nil, fmt.Errorf("configuring IPFS client: %w", err)
+		}
+
+		return ipfs.New(cfg.IPFSConfig(), client, i), nil
+	case config.GatewayBackend:
+		return gateway.New(cfg.GatewayConfig(), utils.GetHTTPClient(dialer.DialContext, 10), i), nil
+	case config.FilesystemBackend:
+		return filesystem.New(cfg.FilesystemConfig(), i), nil
+	case config.CARBackend:
+		return car.New(cfg.CARConfig(), i)
+	default:
+		return nil, fmt.Errorf("unknown protocol backend '%s'", cfg.Protocol.Backend)
+	}
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue/disk"
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// requeuer moves dead-lettered messages back to a queue.
+type requeuer interface {
+	RequeueDead(context.Context) (int, error)
+}
+
+// RequeueDead moves dead-lettered resources back to their queues, for example after an outage.
+func RequeueDead(ctx context.Context, cfg *config.Config) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler queue requeue-dead")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	switch cfg.Queues.Backend {
+	case config.AMQPBackend:
+		return requeueDeadAMQP(ctx, cfg, i)
+	case config.DiskBackend:
+		return requeueDeadDisk(ctx, cfg, i)
+	case config.RedisBackend:
+		return requeueDeadRedis(ctx, cfg, i)
+	default:
+		return fmt.Errorf("requeueing dead-lettered messages is unsupported for queue backend '%s'", cfg.Queues.Backend)
+	}
+}
+
+func requeueDeadAMQP(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) error {
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	amqpConfig, err := amqp.DialConfig(dialer.Dial, cfg.AMQP.TLSConfig(), cfg.AMQP.CredentialsConfig())
+	if err != nil {
+		return fmt.Errorf("configuring AMQP: %w", err)
+	}
+
+	conn, err := amqp.NewConnection(ctx, cfg.AMQPConfig(), amqpConfig, i)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requeueDeadQueues(ctx, cfg, func(name string) (requeuer, error) {
+		return conn.NewChannelQueue(ctx, name, 1)
+	})
+}
+
+func requeueDeadDisk(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) error {
+	b, err := disk.Open(cfg.DiskQueueConfig(), i)
+	if err != nil {
+		return fmt.Errorf("opening %s (is the crawler running?): %w", cfg.Queues.Path, err)
+	}
+	defer b.Close()
+
+	return requeueDeadQueues(ctx, cfg, func(name string) (requeuer, error) {
+		return b.Queue(name)
+	})
+}
+
+func requeueDeadRedis(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) error {
+	redisConfig := cfg.RedisConfig()
+
+	client, err := redis.NewClient(ctx, redisConfig, i)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return requeueDeadQueues(ctx, cfg, func(name string) (requeuer, error) {
+		return redis.NewQueue(ctx, client, redisConfig, name, i)
+	})
+}
+
+// requeueDeadQueues requeues dead-lettered messages for all configured queues.
+func requeueDeadQueues(ctx context.Context, cfg *config.Config, getQueue func(string) (requeuer, error)) error {
+	for _, name := range []string{
+		cfg.Queues.Files.Name,
+		cfg.Queues.Directories.Name,
+		cfg.Queues.Hashes.Name,
+	} {
+		q, err := getQueue(name)
+		if err != nil {
+			return err
+		}
+
+		count, err := q.RequeueDead(ctx)
+		if err != nil {
+			return fmt.Errorf("requeueing %s: %w", name, err)
+		}
+
+		log.Printf("Requeued %d dead-lettered messages to %s", count, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+/*
+Package breaker implements circuit breakers, tracking the health of backends.
+
+When the ratio of requests failing because a backend is unavailable crosses a threshold, the breaker opens.
+While open, the backend is probed periodically and the breaker closes as soon as a probe succeeds.
+Consumers use Wait to pause work while breakers are open.
+*/
+package breaker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// State represents the state of a Breaker.
+type State int
+
+// Breaker states.
+const (
+	Closed State = iota // Backend healthy, requests flow.
+	Open                // Backend unavailable, work should pause.
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Probe checks the health of a backend, returning an error when it is unavailable.
+type Probe func(context.Context) error
+
+// Breaker tracks the health of a single backend. It is concurrency-safe.
+type Breaker struct {
+	name  string
+	cfg   *Config
+	probe Probe
+
+	mu          sync.Mutex
+	state       State
+	requests    int
+	failures    int
+	windowStart time.Time
+	closed      chan struct{} // Closed when the breaker is closed, replaced when it opens.
+
+	ctx    context.Context // Done when the breaker is shut down, stopping probes.
+	cancel context.CancelFunc
+	probes sync.WaitGroup
+
+	openCounter metric.BoundInt64UpDownCounter // 1 while open, 0 while closed.
+
+	*instr.Instrumentation
+}
+
+// New returns a new, closed, Breaker for the backend identified by name.
+func New(name string, cfg *Config, probe Probe, i *instr.Instrumentation) *Breaker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &Breaker{
+		name:            name,
+		cfg:             cfg,
+		probe:           probe,
+		windowStart:     time.Now(),
+		closed:          make(chan struct{}),
+		ctx:             ctx,
+		cancel:          cancel,
+		Instrumentation: i,
+	}
+
+	close(b.closed)
+
+	b.openCounter = metric.Must(i.Meter).NewInt64UpDownCounter(
+		"ipfs_search.breaker.open",
+		metric.WithDescription("Open circuit breakers per backend; 1 while the backend is unavailable."),
+	).Bind(label.String("backend", name))
+
+	return b
+}
+
+// String returns the name of the backend.
+func (b *Breaker) String() string {
+	return b.name
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// Record registers the result of a request to the backend, opening the breaker when the failure ratio crosses
+// the threshold. Only errors of class t.ErrBackendUnavailable count as failures.
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open {
+		return
+	}
+
+	if time.Since(b.windowStart) > b.cfg.Window {
+		b.requests, b.failures = 0, 0
+		b.windowStart = time.Now()
+	}
+
+	b.requests++
+	if t.ErrorClass(err) == t.ErrBackendUnavailable {
+		b.failures++
+	}
+
+	if b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.Threshold {
+		log.Printf("Opening circuit breaker for %s; %d of %d requests failed, last error: %v", b.name, b.failures, b.requests, err)
+
+		b.state = Open
+		b.closed = make(chan struct{})
+		b.openCounter.Add(context.Background(), 1)
+
+		if b.ctx.Err() == nil {
+			b.probes.Add(1)
+			go b.probeUntilHealthy()
+		}
+	}
+}
+
+// Close stops probing the backend, waiting for a running probe to return. An open breaker stays open.
+func (b *Breaker) Close() error {
+	b.mu.Lock()
+	b.cancel()
+	b.mu.Unlock()
+
+	b.probes.Wait()
+
+	return nil
+}
+
+// probeUntilHealthy probes the backend periodically until a probe succeeds, closing the breaker, or until the
+// breaker is shut down.
+func (b *Breaker) probeUntilHealthy() {
+	defer b.probes.Done()
+
+	ticker := time.NewTicker(b.cfg.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(b.ctx, b.cfg.ProbeInterval)
+		err := b.probe(ctx)
+		cancel()
+
+		if err == nil {
+			b.reset()
+			return
+		}
+
+		if b.ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Circuit breaker for %s open, probe failed: %v", b.name, err)
+	}
+}
+
+// reset closes the breaker.
+func (b *Breaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	log.Printf("Closing circuit breaker for %s; backend healthy", b.name)
+
+	b.state = Closed
+	b.requests, b.failures = 0, 0
+	b.windowStart = time.Now()
+	b.openCounter.Add(context.Background(), -1)
+	close(b.closed)
+}
+
+// Wait blocks until the breaker is closed or the context is done, in which case the context's error is returned.
+func (b *Breaker) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-closed:
+		return nil
+	}
+}
+
+// Group is a set of breakers.
+type Group []*Breaker
+
+// Wait blocks until all breakers in the group are closed or the context is done.
+func (g Group) Wait(ctx context.Context) error {
+	for _, b := range g {
+		if err := b.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
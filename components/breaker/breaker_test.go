@@ -0,0 +1,151 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type BreakerTestSuite struct {
+	suite.Suite
+
+	ctx     context.Context
+	cfg     *Config
+	healthy int32
+	probes  int32
+	b       *Breaker
+}
+
+func (s *BreakerTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.cfg = &Config{
+		Threshold:     0.5,
+		MinRequests:   4,
+		Window:        time.Minute,
+		ProbeInterval: time.Millisecond,
+	}
+
+	atomic.StoreInt32(&s.healthy, 0)
+	atomic.StoreInt32(&s.probes, 0)
+
+	probe := func(context.Context) error {
+		atomic.AddInt32(&s.probes, 1)
+
+		if atomic.LoadInt32(&s.healthy) == 1 {
+			return nil
+		}
+
+		return errors.New("unhealthy")
+	}
+
+	s.b = New("test", s.cfg, probe, instr.New())
+}
+
+func (s *BreakerTestSuite) TearDownTest() {
+	s.NoError(s.b.Close())
+}
+
+func (s *BreakerTestSuite) unavailable() error {
+	return t.BackendUnavailable(errors.New("connection refused"))
+}
+
+func (s *BreakerTestSuite) TestStaysClosed() {
+	s.b.Record(nil)
+	s.b.Record(s.unavailable())
+	s.b.Record(nil)
+	s.b.Record(nil)
+
+	s.Equal(Closed, s.b.State())
+	s.NoError(s.b.Wait(s.ctx))
+}
+
+func (s *BreakerTestSuite) TestMinRequests() {
+	s.b.Record(s.unavailable())
+	s.b.Record(s.unavailable())
+	s.b.Record(s.unavailable())
+
+	s.Equal(Closed, s.b.State())
+}
+
+func (s *BreakerTestSuite) TestOtherErrorsIgnored() {
+	for i := 0; i < 4; i++ {
+		s.b.Record(t.Transient(errors.New("timeout")))
+	}
+
+	s.Equal(Closed, s.b.State())
+}
+
+func (s *BreakerTestSuite) TestOpenAndRecover() {
+	s.b.Record(nil)
+	s.b.Record(nil)
+	s.b.Record(s.unavailable())
+	s.b.Record(s.unavailable())
+
+	s.Equal(Open, s.b.State())
+
+	// Wait blocks while open
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, s.b.Wait(ctx))
+
+	// Recovers when probe succeeds
+	atomic.StoreInt32(&s.healthy, 1)
+
+	ctx, cancel = context.WithTimeout(s.ctx, time.Second)
+	defer cancel()
+	s.NoError(s.b.Wait(ctx))
+	s.Equal(Closed, s.b.State())
+}
+
+func (s *BreakerTestSuite) TestCloseStopsProbes() {
+	for i := 0; i < 4; i++ {
+		s.b.Record(s.unavailable())
+	}
+
+	s.Eventually(func() bool { return atomic.LoadInt32(&s.probes) > 0 }, time.Second, time.Millisecond)
+
+	s.NoError(s.b.Close())
+
+	probes := atomic.LoadInt32(&s.probes)
+	time.Sleep(10 * s.cfg.ProbeInterval)
+	s.Equal(probes, atomic.LoadInt32(&s.probes))
+	s.Equal(Open, s.b.State())
+}
+
+func (s *BreakerTestSuite) TestNoProbesAfterClose() {
+	s.NoError(s.b.Close())
+
+	for i := 0; i < 4; i++ {
+		s.b.Record(s.unavailable())
+	}
+
+	time.Sleep(10 * s.cfg.ProbeInterval)
+	s.Equal(int32(0), atomic.LoadInt32(&s.probes))
+}
+
+func (s *BreakerTestSuite) TestGroup() {
+	healthy := New("healthy", s.cfg, nil, instr.New())
+	g := Group{healthy, s.b}
+
+	s.NoError(g.Wait(s.ctx))
+
+	for i := 0; i < 4; i++ {
+		s.b.Record(s.unavailable())
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, g.Wait(ctx))
+}
+
+func TestBreakerTestSuite(t *testing.T) {
+	suite.Run(t, new(BreakerTestSuite))
+}
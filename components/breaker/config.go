@@ -0,0 +1,23 @@
+package breaker
+
+import (
+	"time"
+)
+
+// Config specifies the configuration for circuit breakers.
+type Config struct {
+	Threshold     float64       // Ratio of failed requests within Window at which the breaker opens.
+	MinRequests   int           // Minimum number of requests within Window before the breaker can open.
+	Window        time.Duration // Period over which the failure ratio is determined.
+	ProbeInterval time.Duration // Interval at which an open breaker probes its backend.
+}
+
+// DefaultConfig returns the default configuration for circuit breakers.
+func DefaultConfig() *Config {
+	return &Config{
+		Threshold:     0.5,
+		MinRequests:   20,
+		Window:        time.Minute,
+		ProbeInterval: 10 * time.Second,
+	}
+}
@@ -0,0 +1,29 @@
+package breaker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// HTTPProbe returns a Probe requesting url, considering the backend healthy when it responds without server error.
+func HTTPProbe(client *http.Client, url string) Probe {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+
+		return nil
+	}
+}
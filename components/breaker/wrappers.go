@@ -0,0 +1,109 @@
+package breaker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Protocol wraps a Protocol, recording results in a Breaker.
+type Protocol struct {
+	p protocol.Protocol
+	b *Breaker
+}
+
+// NewProtocol returns a Protocol recording the results of p in b.
+func NewProtocol(p protocol.Protocol, b *Breaker) protocol.Protocol {
+	return &Protocol{p, b}
+}
+
+// GatewayURL returns the URL to request a resource from the gateway.
+func (p *Protocol) GatewayURL(r *t.AnnotatedResource) string {
+	return p.p.GatewayURL(r)
+}
+
+// Stat returns a AnnotatedResource with Type and Size populated.
+func (p *Protocol) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	err := p.p.Stat(ctx, r)
+	p.b.Record(err)
+
+	return err
+}
+
+// Ls returns a channel with AnnotatedResource's with Type and Size populated.
+func (p *Protocol) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	err := p.p.Ls(ctx, r, out)
+	p.b.Record(err)
+
+	return err
+}
+
+// Extractor wraps an Extractor, recording results in a Breaker.
+type Extractor struct {
+	e extractor.Extractor
+	b *Breaker
+}
+
+// NewExtractor returns an Extractor recording the results of e in b.
+func NewExtractor(e extractor.Extractor, b *Breaker) extractor.Extractor {
+	return &Extractor{e, b}
+}
+
+// Extract metadata from a (potentially) referenced resource, updating Metadata or returning an error.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	err := e.e.Extract(ctx, r, m)
+	e.b.Record(err)
+
+	return err
+}
+
+// Index wraps an Index, recording results in a Breaker.
+type Index struct {
+	i index.Index
+	b *Breaker
+}
+
+// NewIndex returns an Index recording the results of i in b.
+func NewIndex(i index.Index, b *Breaker) index.Index {
+	return &Index{i, b}
+}
+
+// String returns the name of the wrapped index, for convenient logging.
+func (i *Index) String() string {
+	return fmt.Sprint(i.i)
+}
+
+// Index a document's properties, identified by id.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	err := i.i.Index(ctx, id, properties)
+	i.b.Record(err)
+
+	return err
+}
+
+// Update a document's properties, given id.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	err := i.i.Update(ctx, id, properties)
+	i.b.Record(err)
+
+	return err
+}
+
+// Get retreives `fields` from document with `id` from the index.
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	found, err := i.i.Get(ctx, id, dst, fields...)
+	i.b.Record(err)
+
+	return found, err
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ protocol.Protocol   = &Protocol{}
+	_ extractor.Extractor = &Extractor{}
+	_ index.Index         = &Index{}
+)
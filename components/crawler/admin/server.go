@@ -0,0 +1,171 @@
+/*
+Package admin implements an HTTP server exposing health, readiness and status of the crawler, allowing consumption
+of queues to be paused and resumed at runtime and serving pprof profiles.
+*/
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/crawler/worker"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// Pool is the worker pool controlled by the admin server; implemented by worker.Pool.
+type Pool interface {
+	Status() *worker.Status
+	Pause(queue string) error
+	Resume(queue string) error
+	Resize(ctx context.Context, queue string, workers int) error
+	Ready(ctx context.Context) map[string]error
+}
+
+// Server serves the admin endpoints.
+type Server struct {
+	cfg  *config.Admin
+	pool Pool
+
+	*instr.Instrumentation
+}
+
+// New returns a new admin server for pool.
+func New(cfg *config.Admin, pool Pool, i *instr.Instrumentation) *Server {
+	return &Server{
+		cfg:             cfg,
+		pool:            pool,
+		Instrumentation: i,
+	}
+}
+
+// Handler returns the handler for the admin endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", s.healthz)
+	mux.HandleFunc("/readyz", s.readyz)
+	mux.HandleFunc("/status", s.status)
+	mux.HandleFunc("/pause/", s.control("/pause/", s.pause))
+	mux.HandleFunc("/resume/", s.control("/resume/", s.resume))
+	mux.HandleFunc("/resize/", s.control("/resize/", s.resize))
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return mux
+}
+
+// Serve serves the admin endpoints on the configured address until ctx is done.
+func (s *Server) Serve(ctx context.Context) error {
+	return utils.Serve(ctx, s.cfg.Address, s.Handler(), "admin endpoints")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing admin response: %v", err)
+	}
+}
+
+// healthz reports the process as alive.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+// readyz checks the backends of the pool, responding with 503 when any is unreachable.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.CheckTimeout)
+	defer cancel()
+
+	ctx, span := s.Tracer.Start(ctx, "crawler.admin.readyz")
+	defer span.End()
+
+	status := http.StatusOK
+	results := make(map[string]string)
+
+	for name, err := range s.pool.Ready(ctx) {
+		if err != nil {
+			status = http.StatusServiceUnavailable
+			results[name] = err.Error()
+		} else {
+			results[name] = "ok"
+		}
+	}
+
+	writeJSON(w, status, results)
+}
+
+// status reports the status of the pool.
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.pool.Status())
+}
+
+// errBadRequest is returned by controls for invalid requests.
+var errBadRequest = errors.New("bad request")
+
+// control returns a handler applying f to the queue named in the path after prefix.
+func (s *Server) control(prefix string, f func(*http.Request, string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, prefix)
+
+		if err := f(r, name); err != nil {
+			switch {
+			case errors.Is(err, worker.ErrUnknownQueue):
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			case errors.Is(err, errBadRequest), errors.Is(err, worker.ErrInvalidWorkers):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Admin request: %s %s", r.Method, r.URL.Path)
+
+		writeJSON(w, http.StatusOK, s.pool.Status())
+	}
+}
+
+func (s *Server) pause(r *http.Request, name string) error {
+	return s.pool.Pause(name)
+}
+
+func (s *Server) resume(r *http.Request, name string) error {
+	return s.pool.Resume(name)
+}
+
+// resize changes the number of workers for a queue to the `workers` query parameter.
+func (s *Server) resize(r *http.Request, name string) error {
+	workers, err := strconv.Atoi(r.URL.Query().Get("workers"))
+	if err != nil {
+		return fmt.Errorf("%w: workers: %v", errBadRequest, err)
+	}
+
+	return s.pool.Resize(r.Context(), name, workers)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ Pool = &worker.Pool{}
@@ -0,0 +1,207 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/crawler/worker"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type mockPool struct {
+	mock.Mock
+}
+
+func (p *mockPool) Status() *worker.Status {
+	args := p.Called()
+	return args.Get(0).(*worker.Status)
+}
+
+func (p *mockPool) Pause(queue string) error {
+	args := p.Called(queue)
+	return args.Error(0)
+}
+
+func (p *mockPool) Resume(queue string) error {
+	args := p.Called(queue)
+	return args.Error(0)
+}
+
+func (p *mockPool) Resize(ctx context.Context, queue string, workers int) error {
+	args := p.Called(ctx, queue, workers)
+	return args.Error(0)
+}
+
+func (p *mockPool) Ready(ctx context.Context) map[string]error {
+	args := p.Called(ctx)
+	return args.Get(0).(map[string]error)
+}
+
+type ServerTestSuite struct {
+	suite.Suite
+
+	pool *mockPool
+	srv  *httptest.Server
+}
+
+func (s *ServerTestSuite) SetupTest() {
+	cfg := config.Default()
+	s.pool = &mockPool{}
+	s.srv = httptest.NewServer(New(&cfg.Admin, s.pool, instr.New()).Handler())
+}
+
+func (s *ServerTestSuite) TearDownTest() {
+	s.srv.Close()
+	s.pool.AssertExpectations(s.T())
+}
+
+func (s *ServerTestSuite) get(path string) *http.Response {
+	resp, err := http.Get(s.srv.URL + path)
+	s.Require().NoError(err)
+
+	return resp
+}
+
+func (s *ServerTestSuite) post(path string) *http.Response {
+	resp, err := http.Post(s.srv.URL+path, "", nil)
+	s.Require().NoError(err)
+
+	return resp
+}
+
+func (s *ServerTestSuite) decode(resp *http.Response, v interface{}) {
+	defer resp.Body.Close()
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(v))
+}
+
+func (s *ServerTestSuite) TestHealthz() {
+	resp := s.get("/healthz")
+	resp.Body.Close()
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestReady() {
+	s.pool.On("Ready", mock.Anything).Return(map[string]error{
+		"ipfs": nil,
+		"amqp": nil,
+	}).Once()
+
+	resp := s.get("/readyz")
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	var results map[string]string
+	s.decode(resp, &results)
+	s.Equal(map[string]string{"ipfs": "ok", "amqp": "ok"}, results)
+}
+
+func (s *ServerTestSuite) TestNotReady() {
+	s.pool.On("Ready", mock.Anything).Return(map[string]error{
+		"ipfs": nil,
+		"tika": errors.New("connection refused"),
+	}).Once()
+
+	resp := s.get("/readyz")
+	s.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+
+	var results map[string]string
+	s.decode(resp, &results)
+	s.Equal("connection refused", results["tika"])
+}
+
+func (s *ServerTestSuite) TestStatus() {
+	status := &worker.Status{
+		Queues: []worker.QueueStatus{
+			{Queue: "files", Workers: 3, InFlight: []string{"ipfs://Qm"}},
+		},
+		Breakers: map[string]string{"ipfs": "closed"},
+	}
+	s.pool.On("Status").Return(status).Once()
+
+	resp := s.get("/status")
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	var result worker.Status
+	s.decode(resp, &result)
+	s.Equal(*status, result)
+}
+
+func (s *ServerTestSuite) TestPause() {
+	s.pool.On("Pause", "files").Return(nil).Once()
+	s.pool.On("Status").Return(&worker.Status{}).Once()
+
+	resp := s.post("/pause/files")
+	resp.Body.Close()
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestResume() {
+	s.pool.On("Resume", "files").Return(nil).Once()
+	s.pool.On("Status").Return(&worker.Status{}).Once()
+
+	resp := s.post("/resume/files")
+	resp.Body.Close()
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestResize() {
+	s.pool.On("Resize", mock.Anything, "files", 5).Return(nil).Once()
+	s.pool.On("Status").Return(&worker.Status{}).Once()
+
+	resp := s.post("/resize/files?workers=5")
+	resp.Body.Close()
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestResizeInvalid() {
+	resp := s.post("/resize/files?workers=many")
+	resp.Body.Close()
+
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	s.pool.On("Resize", mock.Anything, "files", -1).Return(fmt.Errorf("%w: -1", worker.ErrInvalidWorkers)).Once()
+
+	resp = s.post("/resize/files?workers=-1")
+	resp.Body.Close()
+
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestPauseUnknownQueue() {
+	s.pool.On("Pause", "nope").Return(fmt.Errorf("%w: nope", worker.ErrUnknownQueue)).Once()
+
+	resp := s.post("/pause/nope")
+	resp.Body.Close()
+
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestPauseRequiresPost() {
+	resp := s.get("/pause/files")
+	resp.Body.Close()
+
+	s.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestPprof() {
+	resp := s.get("/debug/pprof/")
+	resp.Body.Close()
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
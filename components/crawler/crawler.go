@@ -24,6 +24,7 @@ type Crawler struct {
 	queues    *Queues
 	protocol  protocol.Protocol
 	extractor extractor.Extractor
+	metrics   metrics
 
 	*instr.Instrumentation
 }
@@ -46,6 +47,11 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 
 	var err error
 
+	result := resultError
+	defer func() {
+		c.metrics.crawled.Add(ctx, 1, label.Stringer("type", r.Type), label.String("result", result))
+	}()
+
 	if r.Protocol == t.InvalidProtocol {
 		// Sending items with an invalid protocol to Crawl() is a programming error and
 		// should never happen.
@@ -66,6 +72,7 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 	if exists {
 		log.Printf("Not updating existing resource %v", r)
 		span.AddEvent(ctx, "Not updating existing resource")
+		result = resultExisting
 		return nil
 	}
 
@@ -75,7 +82,9 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 			log.Printf("Indexing invalid resource %v", r)
 			span.AddEvent(ctx, "Indexing invalid resource")
 
-			err = c.indexInvalid(ctx, r, err)
+			if err = c.indexInvalid(ctx, r, err); err == nil {
+				result = resultInvalid
+			}
 		}
 
 		// Errors from ensureType imply that no type could be found, hence we can't index.
@@ -89,6 +98,8 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 	err = c.index(ctx, r)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	} else {
+		result = resultIndexed
 	}
 	return err
 }
@@ -101,6 +112,7 @@ func New(config *Config, indexes *Indexes, queues *Queues, protocol protocol.Pro
 		queues,
 		protocol,
 		extractor,
+		newMetrics(i),
 		i,
 	}
 }
@@ -117,6 +129,11 @@ func (c *Crawler) ensureType(ctx context.Context, r *t.AnnotatedResource) error
 
 		err = c.protocol.Stat(ctx, r)
 		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				// Timeouts are common for resources which are (temporarily) unavailable; retry later.
+				err = t.Transient(err)
+			}
+
 			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		}
 	}
@@ -392,7 +392,8 @@ func (s *CrawlerTestSuite) TestCrawlStatTimeout() {
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
-	s.Equal(err, context.DeadlineExceeded)
+	s.True(errors.Is(err, context.DeadlineExceeded))
+	s.Equal(t.ErrTransient, t.ErrorClass(err))
 	s.assertExpectations()
 }
 
@@ -837,7 +838,8 @@ func (s *CrawlerTestSuite) TestCrawlDirEntryTimeout() {
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
-	s.Equal(err, context.DeadlineExceeded)
+	s.True(errors.Is(err, context.DeadlineExceeded))
+	s.Equal(t.ErrTransient, t.ErrorClass(err))
 	s.assertExpectations()
 }
 
@@ -43,6 +43,8 @@ func makeDocument(r *t.AnnotatedResource) indexTypes.Document {
 }
 
 func (c *Crawler) indexInvalid(ctx context.Context, r *t.AnnotatedResource, err error) error {
+	c.metrics.invalid.Add(ctx, 1, label.String("reason", invalidReason(err)))
+
 	// Index unsupported items as invalid.
 	return c.indexes.Invalids.Index(ctx, r.ID, &indexTypes.Invalid{
 		Error: err.Error(),
@@ -0,0 +1,54 @@
+package crawler
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/api/metric"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Results of crawls, as recorded in metrics.
+const (
+	resultIndexed  = "indexed"  // Indexed, possibly as invalid when its contents could not be processed.
+	resultExisting = "existing" // Already indexed.
+	resultInvalid  = "invalid"  // No valid type could be determined; indexed as invalid.
+	resultError    = "error"    // Not indexed.
+)
+
+// metrics holds the instruments recording the results of crawls.
+type metrics struct {
+	crawled metric.Int64Counter // Crawled resources, by type and result.
+	invalid metric.Int64Counter // Resources indexed as invalid, by reason.
+}
+
+func newMetrics(i *instr.Instrumentation) metrics {
+	m := metric.Must(i.Meter)
+
+	return metrics{
+		crawled: m.NewInt64Counter(
+			"ipfs_search.crawler.crawled",
+			metric.WithDescription("Crawled resources by type and result."),
+		),
+		invalid: m.NewInt64Counter(
+			"ipfs_search.crawler.invalid",
+			metric.WithDescription("Resources indexed as invalid by reason."),
+		),
+	}
+}
+
+// invalidReason returns the reason for a resource being invalid, as recorded in metrics.
+func invalidReason(err error) string {
+	switch {
+	case errors.Is(err, t.ErrUnsupportedType):
+		return "unsupported_type"
+	case errors.Is(err, extractor.ErrFileTooLarge):
+		return "file_too_large"
+	case errors.Is(err, ErrDirectoryTooLarge):
+		return "directory_too_large"
+	default:
+		return "other"
+	}
+}
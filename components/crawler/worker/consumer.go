@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/limiter"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// maxErrors is the number of most recent failures retained per consumer.
+const maxErrors = 10
+
+// consumer tracks the workers consuming a single queue, allowing consumption to be paused.
+type consumer struct {
+	queue      consumeQueue
+	deliveries <-chan queue.Delivery
+	limiter    *limiter.Limiter // Adaptive concurrency limit; nil when disabled.
+
+	mu       sync.Mutex
+	workers  int                  // Number of workers.
+	stops    []context.CancelFunc // Stop running workers, after their current crawl.
+	seq      int                  // Sequence number of the next worker, naming it.
+	resumed  chan struct{}        // Closed while consuming, replaced when paused.
+	inFlight map[string]string    // Resources being crawled, by worker name.
+	errors   []CrawlError         // Most recent failures, oldest first.
+}
+
+func newConsumer(q consumeQueue, deliveries <-chan queue.Delivery, workers int) *consumer {
+	c := &consumer{
+		queue:      q,
+		deliveries: deliveries,
+		workers:    workers,
+		resumed:    make(chan struct{}),
+		inFlight:   make(map[string]string),
+	}
+
+	close(c.resumed)
+
+	return c
+}
+
+func (c *consumer) String() string {
+	return c.queue.String()
+}
+
+// isPaused returns whether consumption is paused; must be called with mu held.
+func (c *consumer) isPaused() bool {
+	select {
+	case <-c.resumed:
+		return false
+	default:
+		return true
+	}
+}
+
+// pause stops workers from taking new deliveries; in-flight crawls continue.
+func (c *consumer) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.isPaused() {
+		c.resumed = make(chan struct{})
+	}
+}
+
+// resume lets workers take new deliveries again.
+func (c *consumer) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isPaused() {
+		close(c.resumed)
+	}
+}
+
+// wait blocks until consumption is resumed or the context is done, in which case the context's error is returned.
+func (c *consumer) wait(ctx context.Context) error {
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
+// acquire blocks until the concurrency limit allows another crawl, or the context is done.
+func (c *consumer) acquire(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+
+	return c.limiter.Acquire(ctx)
+}
+
+// release ends a crawl started after acquire.
+func (c *consumer) release() {
+	if c.limiter != nil {
+		c.limiter.Release()
+	}
+}
+
+// limitContext returns a context in which backend requests are reported to the concurrency limiter, if any.
+func (c *consumer) limitContext(ctx context.Context) context.Context {
+	if c.limiter == nil {
+		return ctx
+	}
+
+	return limiter.NewContext(ctx, c.limiter)
+}
+
+// start registers the resource being crawled by a worker.
+func (c *consumer) start(worker, resource string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight[worker] = resource
+}
+
+// done unregisters the resource crawled by a worker, recording err when not nil.
+func (c *consumer) done(worker string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resource, ok := c.inFlight[worker]
+	delete(c.inFlight, worker)
+
+	if err == nil {
+		return
+	}
+
+	if !ok {
+		// Failed before the resource was known, e.g. for invalid deliveries.
+		resource = ""
+	}
+
+	c.errors = append(c.errors, CrawlError{
+		Time:     time.Now(),
+		Resource: resource,
+		Error:    err.Error(),
+	})
+
+	if len(c.errors) > maxErrors {
+		c.errors = c.errors[len(c.errors)-maxErrors:]
+	}
+}
+
+// status returns the status of the consumer.
+func (c *consumer) status() QueueStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := QueueStatus{
+		Queue:    c.String(),
+		Workers:  c.workers,
+		Paused:   c.isPaused(),
+		InFlight: make([]string, 0, len(c.inFlight)),
+		Errors:   make([]CrawlError, len(c.errors)),
+	}
+
+	for _, r := range c.inFlight {
+		s.InFlight = append(s.InFlight, r)
+	}
+
+	if c.limiter != nil {
+		s.Limit = c.limiter.Limit()
+	}
+
+	sort.Strings(s.InFlight)
+	copy(s.Errors, c.errors)
+
+	return s
+}
@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/limiter"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type ConsumerTestSuite struct {
+	suite.Suite
+
+	c *consumer
+}
+
+func (s *ConsumerTestSuite) SetupTest() {
+	s.c = newConsumer(&mockQueue{}, nil, 2)
+}
+
+func (s *ConsumerTestSuite) TestWaitResumed() {
+	s.NoError(s.c.wait(context.Background()))
+	s.False(s.c.status().Paused)
+}
+
+func (s *ConsumerTestSuite) TestPauseResume() {
+	s.c.pause()
+	s.True(s.c.status().Paused)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.Equal(context.DeadlineExceeded, s.c.wait(ctx))
+
+	done := make(chan error)
+	go func() {
+		done <- s.c.wait(context.Background())
+	}()
+
+	s.c.resume()
+	s.NoError(<-done)
+	s.False(s.c.status().Paused)
+
+	// Resuming twice is harmless.
+	s.c.resume()
+}
+
+func (s *ConsumerTestSuite) TestInFlight() {
+	s.c.start("mock-0", "ipfs://Qm1")
+	s.c.start("mock-1", "ipfs://Qm0")
+
+	s.Equal([]string{"ipfs://Qm0", "ipfs://Qm1"}, s.c.status().InFlight)
+
+	s.c.done("mock-0", nil)
+	s.Equal([]string{"ipfs://Qm0"}, s.c.status().InFlight)
+	s.Empty(s.c.status().Errors)
+}
+
+func (s *ConsumerTestSuite) TestErrors() {
+	s.c.start("mock-0", "ipfs://Qm")
+	s.c.done("mock-0", errors.New("failed"))
+
+	errs := s.c.status().Errors
+	s.Require().Len(errs, 1)
+	s.Equal("ipfs://Qm", errs[0].Resource)
+	s.Equal("failed", errs[0].Error)
+
+	for i := 0; i < maxErrors+5; i++ {
+		s.c.done("mock-0", fmt.Errorf("error %d", i))
+	}
+
+	// Only the most recent errors are retained.
+	errs = s.c.status().Errors
+	s.Len(errs, maxErrors)
+	s.Equal(fmt.Sprintf("error %d", maxErrors+4), errs[maxErrors-1].Error)
+}
+
+func (s *ConsumerTestSuite) TestLimiter() {
+	ctx := context.Background()
+
+	// Without limiter, acquiring never blocks.
+	s.NoError(s.c.acquire(ctx))
+	s.c.release()
+	s.Zero(s.c.status().Limit)
+	s.Nil(limiter.FromContext(s.c.limitContext(ctx)))
+
+	cfg := limiter.DefaultConfig()
+	cfg.MaxLimit = 1
+	s.c.limiter = limiter.New("mock", cfg, instr.New())
+
+	s.Equal(1, s.c.status().Limit)
+	s.Equal(s.c.limiter, limiter.FromContext(s.c.limitContext(ctx)))
+
+	s.NoError(s.c.acquire(ctx))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	s.Equal(context.DeadlineExceeded, s.c.acquire(timeoutCtx))
+
+	s.c.release()
+	s.NoError(s.c.acquire(ctx))
+}
+
+func (s *ConsumerTestSuite) TestPoolPauseUnknownQueue() {
+	w := &Pool{consumers: []*consumer{s.c}}
+
+	s.NoError(w.Pause("mock"))
+	s.True(s.c.status().Paused)
+	s.NoError(w.Resume("mock"))
+
+	s.True(errors.Is(w.Pause("nope"), ErrUnknownQueue))
+}
+
+func TestConsumerTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsumerTestSuite))
+}
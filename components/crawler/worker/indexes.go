@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/breaker"
+	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/bleve"
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/components/metrics"
+
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+func (w *Pool) getElasticClient() (*elastic.Client, error) {
+	if w.esClient != nil {
+		return w.esClient, nil
+	}
+
+	httpClient, err := utils.GetSecureHTTPClient(w.dialer.DialContext, 5,
+		w.config.ElasticSearch.TLSConfig(), w.config.ElasticSearch.CredentialsConfig())
+	if err != nil {
+		return nil, fmt.Errorf("configuring Elasticsearch client: %w", err)
+	}
+
+	esClient, err := elastic.NewClient(
+		elastic.SetSniff(false),
+		elastic.SetURL(w.config.ElasticSearch.URL),
+		elastic.SetHttpClient(httpClient),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	w.esClient = esClient
+
+	return esClient, nil
+}
+
+func (w *Pool) getElasticBreaker(esClient *elastic.Client) *breaker.Breaker {
+	if w.esBreaker != nil {
+		return w.esBreaker
+	}
+
+	w.esBreaker = w.newBreaker("elasticsearch", func(ctx context.Context) error {
+		_, _, err := esClient.Ping(w.config.ElasticSearch.URL).Do(ctx)
+		return err
+	})
+
+	return w.esBreaker
+}
+
+func (w *Pool) getBulker(ctx context.Context, esClient *elastic.Client) (*elasticsearch.Bulker, error) {
+	if w.bulker != nil {
+		return w.bulker, nil
+	}
+
+	log.Println("Starting bulk indexer.")
+	bulker, err := elasticsearch.NewBulker(ctx, esClient, w.config.BulkConfig(), w.Instrumentation)
+	if err != nil {
+		return nil, err
+	}
+
+	// Flush and stop bulk indexer on shutdown
+	w.addCloser(func() error {
+		log.Printf("Closing bulk indexer")
+		return bulker.Close()
+	})
+
+	w.bulker = bulker
+
+	return bulker, nil
+}
+
+func (w *Pool) getElasticsearchIndex(ctx context.Context, name string) (index.Index, error) {
+	esClient, err := w.getElasticClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &elasticsearch.Config{Name: name}
+	b := w.getElasticBreaker(esClient)
+
+	if !w.config.Indexes.Bulk.Enabled {
+		return breaker.NewIndex(elasticsearch.New(esClient, cfg, w.Instrumentation), b), nil
+	}
+
+	bulker, err := w.getBulker(ctx, esClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return breaker.NewIndex(elasticsearch.NewBulk(esClient, cfg, bulker, w.Instrumentation), b), nil
+}
+
+func (w *Pool) getBleveIndex(ctx context.Context, name string) (index.Index, error) {
+	i, err := bleve.New(w.config.BleveConfig(name), w.Instrumentation)
+	if err != nil {
+		return nil, err
+	}
+
+	// Close index on shutdown
+	w.addCloser(func() error {
+		log.Printf("Closing index %s", i)
+		return i.Close()
+	})
+
+	return i, nil
+}
+
+func (w *Pool) getBackendIndex(ctx context.Context, cfg config.Index) (index.Index, error) {
+	switch cfg.Backend {
+	case config.ElasticsearchBackend:
+		return w.getElasticsearchIndex(ctx, cfg.Name)
+	case config.BleveBackend:
+		return w.getBleveIndex(ctx, cfg.Name)
+	default:
+		return nil, fmt.Errorf("unknown backend '%s' for index %s", cfg.Backend, cfg.Name)
+	}
+}
+
+func (w *Pool) getIndex(ctx context.Context, cfg config.Index) (index.Index, error) {
+	i, err := w.getBackendIndex(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics.NewIndex(i, cfg.Name, w.Instrumentation), nil
+}
+
+func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
+	var (
+		indexes = new(crawler.Indexes)
+		err     error
+	)
+
+	if indexes.Files, err = w.getIndex(ctx, w.config.Indexes.Files); err != nil {
+		return nil, err
+	}
+
+	if indexes.Directories, err = w.getIndex(ctx, w.config.Indexes.Directories); err != nil {
+		return nil, err
+	}
+
+	if indexes.Invalids, err = w.getIndex(ctx, w.config.Indexes.Invalids); err != nil {
+		return nil, err
+	}
+
+	return indexes, nil
+}
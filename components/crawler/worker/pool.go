@@ -6,19 +6,26 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sync"
 	"time"
 
+	goredis "github.com/go-redis/redis/v7"
 	"github.com/olivere/elastic/v7"
-	samqp "github.com/streadway/amqp"
 
+	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
 
+	"github.com/ipfs-search/ipfs-search/components/breaker"
 	"github.com/ipfs-search/ipfs-search/components/crawler"
 	"github.com/ipfs-search/ipfs-search/components/extractor/tika"
 	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
-	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
-	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/limiter"
+	"github.com/ipfs-search/ipfs-search/components/metrics"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/disk"
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
 
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
@@ -30,12 +37,29 @@ import (
 type Pool struct {
 	config       *config.Config
 	dialer       *utils.RetryingDialer
-	consumeChans struct {
-		Files       <-chan samqp.Delivery
-		Directories <-chan samqp.Delivery
-		Hashes      <-chan samqp.Delivery
-	}
-	crawler *crawler.Crawler
+	consumers    []*consumer // Consumers for files, hashes and directories.
+	crawler      *crawler.Crawler
+	esClient     *elastic.Client
+	bulker       *elasticsearch.Bulker
+	memoryBroker *memory.Broker
+	diskBroker   *disk.Broker
+	redisClient  *goredis.Client
+
+	// Breakers for the backends used by the crawler; consumption pauses while any is open.
+	breakers  breaker.Group
+	esBreaker *breaker.Breaker
+
+	checks map[string]breaker.Probe // Readiness checks by backend.
+
+	workers       sync.WaitGroup
+	resizeMu      sync.Mutex         // Serializes resizing, guarding consumeCtx.
+	consumeCtx    context.Context    // Context for consumption, done when shutdown starts.
+	stopConsuming context.CancelFunc // Stops workers from consuming.
+	workCtx       context.Context    // Context for crawls, outliving consumption during shutdown.
+	cancelWork    context.CancelFunc // Cancels in-flight crawls.
+	closers       []func() error     // Close resources on shutdown, in reverse order.
+
+	consumed metric.Int64Counter // Deliveries consumed, by queue and status.
 
 	*instr.Instrumentation
 }
@@ -57,109 +81,73 @@ func (w *Pool) makeCrawler(ctx context.Context) error {
 		return err
 	}
 
-	// Many stat/ls connections
-	ipfsClient := utils.GetHTTPClient(w.dialer.DialContext, 1000)
-	protocol := ipfs.New(w.config.IPFSConfig(), ipfsClient, w.Instrumentation)
-
-	// Limited Tika connections (as resources are generally known to be available by now)
-	tikaClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
-	extractor := tika.New(w.config.TikaConfig(), tikaClient, protocol, w.Instrumentation)
-
-	w.crawler = crawler.New(w.config.CrawlerConfig(), indexes, queues, protocol, extractor, w.Instrumentation)
-
-	return nil
-}
-
-func (w *Pool) getElasticClient() (*elastic.Client, error) {
-	httpClient := utils.GetHTTPClient(w.dialer.DialContext, 5)
+	backendProtocol, probe, err := w.getProtocol()
+	if err != nil {
+		return err
+	}
 
-	return elastic.NewClient(
-		elastic.SetSniff(false),
-		elastic.SetURL(w.config.ElasticSearch.URL),
-		elastic.SetHttpClient(httpClient),
-	)
-}
+	protocolBreaker := w.newBreaker(w.config.Protocol.Backend, probe)
+	protocol := metrics.NewProtocol(breaker.NewProtocol(backendProtocol, protocolBreaker), w.Instrumentation)
 
-func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
-	esClient, err := w.getElasticClient()
+	// Limited Tika connections (as resources are generally known to be available by now)
+	tikaClient, err := utils.GetSecureHTTPClient(w.dialer.DialContext, 100,
+		w.config.Tika.TLSConfig(), w.config.Tika.CredentialsConfig())
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("configuring Tika client: %w", err)
 	}
 
-	return &crawler.Indexes{
-		Files: elasticsearch.New(
-			esClient,
-			&elasticsearch.Config{Name: w.config.Indexes.Files.Name},
-			w.Instrumentation,
-		),
-		Directories: elasticsearch.New(
-			esClient,
-			&elasticsearch.Config{Name: w.config.Indexes.Directories.Name},
-			w.Instrumentation,
-		),
-		Invalids: elasticsearch.New(
-			esClient,
-			&elasticsearch.Config{Name: w.config.Indexes.Invalids.Name},
-			w.Instrumentation,
+	tikaBreaker := w.newBreaker("tika", breaker.HTTPProbe(tikaClient, w.config.Tika.TikaServerURL))
+	extractor := metrics.NewExtractor(
+		breaker.NewExtractor(
+			tika.New(w.config.TikaConfig(), tikaClient, protocol, w.Instrumentation),
+			tikaBreaker,
 		),
-	}, nil
-}
-
-func (w *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
-	amqpConfig := &samqp.Config{
-		Dial: w.dialer.Dial,
-	}
+		w.Instrumentation,
+	)
 
-	log.Println("Connecting to AMQP.")
-	amqpConnection, err := amqp.NewConnection(ctx, w.config.AMQPConfig(), amqpConfig, w.Instrumentation)
-	if err != nil {
-		return nil, err
+	if w.config.Limiter.Enabled {
+		// Report latency to the concurrency limiter of the queue being consumed.
+		protocol = limiter.NewProtocol(protocol)
+		extractor = limiter.NewExtractor(extractor)
 	}
 
-	log.Println("Creating AMQP channels.")
-	fq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Files.Name, w.config.Workers.FileWorkers)
-	if err != nil {
-		return nil, err
-	}
+	w.crawler = crawler.New(w.config.CrawlerConfig(), indexes, queues, protocol, extractor, w.Instrumentation)
 
-	dq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Directories.Name, w.config.Workers.DirectoryWorkers)
-	if err != nil {
-		return nil, err
-	}
+	return nil
+}
 
-	hq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Hashes.Name, w.config.Workers.HashWorkers)
-	if err != nil {
-		return nil, err
-	}
+// newBreaker returns a new circuit breaker for a backend, pausing consumption while it is open. Probing stops on
+// shutdown.
+func (w *Pool) newBreaker(name string, probe breaker.Probe) *breaker.Breaker {
+	b := breaker.New(name, w.config.BreakerConfig(), probe, w.Instrumentation)
+	w.breakers = append(w.breakers, b)
+	w.addCheck(name, probe)
+	w.addCloser(b.Close)
 
-	return &crawler.Queues{
-		Files:       fq,
-		Directories: dq,
-		Hashes:      hq,
-	}, nil
+	return b
 }
 
-func (w *Pool) crawlDelivery(ctx context.Context, d samqp.Delivery) error {
-	// TODO: Get SpanContext from Delivery.
-	// ctx = trace.ContextWithRemoteSpanContext(ctx, p.SpanContext)
-	ctx, span := w.Tracer.Start(ctx, "crawler.worker.crawlDelivery", trace.WithNewRoot())
+func (w *Pool) crawlDelivery(ctx context.Context, c *consumer, worker string, d queue.Delivery) error {
+	ctx, span := w.Tracer.Start(ctx, "crawler.worker.crawlDelivery")
 	defer span.End()
 
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{},
 	}
 
-	if err := json.Unmarshal(d.Body, r); err != nil {
+	if err := json.Unmarshal(d.Body(), r); err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return err
+		return t.Permanent(err)
 	}
 
 	if !r.IsValid() {
-		err := fmt.Errorf("Invalid resource: %v", r)
+		err := fmt.Errorf("%w: %v", t.ErrInvalidResource, r)
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
 
+	c.start(worker, r.String())
+
 	log.Printf("Crawling '%s'", r)
 	err := w.crawler.Crawl(ctx, r)
 	log.Printf("Done crawling '%s', result: %v", r, err)
@@ -171,82 +159,204 @@ func (w *Pool) crawlDelivery(ctx context.Context, d samqp.Delivery) error {
 	return err
 }
 
-func (w *Pool) startWorker(ctx context.Context, deliveries <-chan samqp.Delivery, name string) {
+// retryDelivery retries a failed delivery after a delay, until the maximum number of attempts is reached
+// after which it is dead-lettered.
+func (w *Pool) retryDelivery(ctx context.Context, q consumeQueue, d queue.Delivery, cause error) error {
+	attempts := queue.Attempts(d) + 1
+
+	if attempts >= w.config.Workers.MaxAttempts {
+		log.Printf("Dead-lettering delivery on %s after %d attempts: %v", q, attempts, cause)
+		return q.DeadLetter(ctx, d, cause)
+	}
+
+	return q.Retry(ctx, d, time.Duration(attempts)*w.config.Workers.RetryDelay)
+}
+
+// pause blocks for the configured pause time, until the context closes or shutdown starts.
+func (w *Pool) pause(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-w.consumeCtx.Done():
+	case <-time.After(w.config.Workers.PauseTime):
+	}
+}
+
+// handleFailure acts on a failed delivery according to the class of its error.
+func (w *Pool) handleFailure(ctx context.Context, q consumeQueue, d queue.Delivery, cause error) error {
+	if w.workCtx.Err() != nil {
+		// Crawl cancelled on shutdown; return to queue without counting an attempt.
+		return d.Nack(true)
+	}
+
+	switch t.ErrorClass(cause) {
+	case t.ErrBackendUnavailable:
+		// Return to queue without counting an attempt, pausing the worker to allow the backend to recover.
+		log.Printf("Backend unavailable, pausing worker on %s for %s: %v", q, w.config.Workers.PauseTime, cause)
+		err := d.Nack(true)
+		w.pause(ctx)
+		return err
+
+	case t.ErrTransient:
+		if err := w.retryDelivery(ctx, q, d, cause); err != nil {
+			// Retrying failed; return to the queue rather than losing the delivery.
+			if rejectErr := d.Nack(true); rejectErr != nil {
+				log.Printf("Error rejecting delivery: %v", rejectErr)
+			}
+
+			return err
+		}
+
+		return nil
+
+	default:
+		// Permanent errors and invalid resources which could not be indexed as such; drop.
+		return d.Nack(false)
+	}
+}
+
+// deliveryStatus returns the status of a delivery crawled with err, as recorded in metrics.
+func deliveryStatus(err error) string {
+	switch t.ErrorClass(err) {
+	case nil:
+		return "ok"
+	case t.ErrBackendUnavailable:
+		return "unavailable"
+	case t.ErrInvalidResource:
+		return "invalid"
+	case t.ErrTransient:
+		return "transient"
+	default:
+		return "permanent"
+	}
+}
+
+// handleDelivery crawls a delivery taken by worker from c, acknowledging it on success.
+func (w *Pool) handleDelivery(c *consumer, worker string, d queue.Delivery) {
+	// In-flight crawls are allowed to finish on shutdown.
+	ctx := c.limitContext(w.workCtx)
+
+	// Continue the trace in which the resource was published, e.g. by the sniffer or a parent directory. This has
+	// to precede the first span, as a local parent span takes precedence over the remote one.
+	ctx = queue.ExtractTrace(ctx, d)
+	ctx, span := w.Tracer.Start(ctx, "crawler.worker.handleDelivery", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	err := w.crawlDelivery(ctx, c, worker, d)
+	c.done(worker, err)
+	w.consumed.Add(ctx, 1, label.Stringer("queue", c), label.String("status", deliveryStatus(err)))
+
+	if err != nil {
+		span.RecordError(ctx, err)
+
+		if err := w.handleFailure(ctx, c.queue, d, err); err != nil {
+			span.RecordError(ctx, err)
+		}
+	} else {
+		if err := d.Ack(); err != nil {
+			span.RecordError(ctx, err)
+		}
+	}
+}
+
+func (w *Pool) startWorker(ctx context.Context, c *consumer, name string) {
+	defer w.workers.Done()
+
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.startWorker")
 	defer span.End()
 
 	for {
+		// Stop consuming while backends are unavailable.
+		if err := w.breakers.Wait(ctx); err != nil {
+			return
+		}
+
+		// Stop consuming while paused.
+		if err := c.wait(ctx); err != nil {
+			return
+		}
+
+		// Stop consuming while the adaptive concurrency limit is reached.
+		if err := c.acquire(ctx); err != nil {
+			return
+		}
+
 		select {
 		case <-ctx.Done():
+			c.release()
 			return
-		case d, ok := <-deliveries:
+		case d, ok := <-c.deliveries:
 			if !ok {
+				c.release()
+
+				if ctx.Err() != nil {
+					// Consumer cancelled on shutdown.
+					return
+				}
+
 				// This is a fatal error; it should never happen - crash the program!
 				panic("unexpected channel close")
 			}
-			if err := w.crawlDelivery(ctx, d); err != nil {
-				// By default, do not retry.
-				shouldRetry := false
 
-				span.RecordError(ctx, err)
-
-				if err := d.Reject(shouldRetry); err != nil {
-					span.RecordError(ctx, err)
-				}
-			} else {
-				if err := d.Ack(false); err != nil {
-					span.RecordError(ctx, err)
-				}
-			}
+			w.handleDelivery(c, name, d)
+			c.release()
 		}
 	}
 }
 
-func (w *Pool) startPool(ctx context.Context, deliveries <-chan samqp.Delivery, workers int, poolName string) {
+func (w *Pool) startPool(ctx context.Context, c *consumer) {
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.startPool")
 	defer span.End()
 
-	for i := 0; i < workers; i++ {
-		name := fmt.Sprintf("%s-%d", poolName, i)
-		go w.startWorker(ctx, deliveries, name)
-	}
+	c.mu.Lock()
+	workers := c.workers
+	c.mu.Unlock()
+
+	w.scale(ctx, c, workers)
 }
 
-// Start launches the workerpool.
+// Start launches the workerpool. Workers stop consuming when the context is done; use Shutdown to wait for them.
 func (w *Pool) Start(ctx context.Context) {
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.Start")
 	defer span.End()
 
-	log.Printf("Starting %d workers for files", w.config.Workers.FileWorkers)
-	w.startPool(ctx, w.consumeChans.Files, w.config.Workers.FileWorkers, "files")
+	ctx, w.stopConsuming = context.WithCancel(ctx)
 
-	log.Printf("Starting %d workers for hashes", w.config.Workers.HashWorkers)
-	w.startPool(ctx, w.consumeChans.Hashes, w.config.Workers.HashWorkers, "hashes")
+	w.resizeMu.Lock()
+	w.consumeCtx = ctx
+	w.resizeMu.Unlock()
 
-	log.Printf("Starting %d workers for directories", w.config.Workers.DirectoryWorkers)
-	w.startPool(ctx, w.consumeChans.Directories, w.config.Workers.DirectoryWorkers, "directories")
+	for _, c := range w.consumers {
+		log.Printf("Starting %d workers for %s", c.status().Workers, c)
+		w.startPool(ctx, c)
+	}
 }
 
 func (w *Pool) makeConsumeChans(ctx context.Context) error {
-	var (
-		queues *crawler.Queues
-		err    error
-	)
-
-	if queues, err = w.getQueues(ctx); err != nil {
+	queues, err := w.getBackendQueues(ctx)
+	if err != nil {
 		return err
 	}
 
-	if w.consumeChans.Files, err = queues.Files.Consume(ctx); err != nil {
-		return err
-	}
+	for _, p := range []struct {
+		q       consumeQueue
+		workers int
+	}{
+		{queues.Files, w.config.Workers.FileWorkers},
+		{queues.Hashes, w.config.Workers.HashWorkers},
+		{queues.Directories, w.config.Workers.DirectoryWorkers},
+	} {
+		deliveries, err := p.q.Consume(ctx)
+		if err != nil {
+			return err
+		}
 
-	if w.consumeChans.Directories, err = queues.Directories.Consume(ctx); err != nil {
-		return err
-	}
+		c := newConsumer(p.q, deliveries, p.workers)
 
-	if w.consumeChans.Hashes, err = queues.Hashes.Consume(ctx); err != nil {
-		return err
+		if w.config.Limiter.Enabled {
+			c.limiter = limiter.New(c.String(), w.config.LimiterConfig(), w.Instrumentation)
+		}
+
+		w.consumers = append(w.consumers, c)
 	}
 
 	return nil
@@ -271,13 +381,24 @@ func (w *Pool) init(ctx context.Context) error {
 	return w.makeConsumeChans(ctx)
 }
 
+// newConsumedCounter returns the counter for consumed deliveries.
+func newConsumedCounter(i *instr.Instrumentation) metric.Int64Counter {
+	return metric.Must(i.Meter).NewInt64Counter(
+		"ipfs_search.queue.consumed",
+		metric.WithDescription("Deliveries consumed by queue and status."),
+	)
+}
+
 // NewPool initializes and returns a new worker pool.
 func NewPool(ctx context.Context, c *config.Config, i *instr.Instrumentation) (*Pool, error) {
 	w := &Pool{
 		config:          c,
 		Instrumentation: i,
+		consumed:        newConsumedCounter(i),
 	}
 
+	w.workCtx, w.cancelWork = context.WithCancel(ctx)
+
 	err := w.init(ctx)
 
 	return w, err
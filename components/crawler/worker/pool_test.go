@@ -0,0 +1,221 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/propagators"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// mockQueue is a consumeQueue mock.
+type mockQueue struct {
+	queue.Mock
+}
+
+func (q *mockQueue) String() string {
+	return "mock"
+}
+
+type PoolTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	w   *Pool
+	q   *mockQueue
+	d   *queue.MockDelivery
+}
+
+func (s *PoolTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	cfg := config.Default()
+	cfg.Workers.PauseTime = time.Millisecond
+
+	i := instr.New()
+
+	s.w = &Pool{
+		config:          cfg,
+		consumeCtx:      s.ctx,
+		Instrumentation: i,
+		consumed:        newConsumedCounter(i),
+	}
+	s.w.workCtx, s.w.cancelWork = context.WithCancel(s.ctx)
+
+	s.q = &mockQueue{}
+	s.d = &queue.MockDelivery{}
+}
+
+func (s *PoolTestSuite) TearDownTest() {
+	s.w.cancelWork()
+}
+
+func (s *PoolTestSuite) assertExpectations() {
+	s.q.AssertExpectations(s.T())
+	s.d.AssertExpectations(s.T())
+}
+
+func (s *PoolTestSuite) TestUnavailableRequeued() {
+	s.d.On("Nack", true).Return(nil).Once()
+
+	err := s.w.handleFailure(s.ctx, s.q, s.d, t.BackendUnavailable(errors.New("down")))
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *PoolTestSuite) TestTransientRetried() {
+	cause := t.Transient(errors.New("timeout"))
+
+	s.d.On("Headers").Return(map[string]interface{}{queue.AttemptsHeader: int32(1)})
+	s.q.On("Retry", s.ctx, s.d, 2*s.w.config.Workers.RetryDelay).Return(nil).Once()
+
+	err := s.w.handleFailure(s.ctx, s.q, s.d, cause)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *PoolTestSuite) TestTransientDeadLettered() {
+	cause := t.Transient(errors.New("timeout"))
+	attempts := int32(s.w.config.Workers.MaxAttempts - 1)
+
+	s.d.On("Headers").Return(map[string]interface{}{queue.AttemptsHeader: attempts})
+	s.q.On("DeadLetter", s.ctx, s.d, cause).Return(nil).Once()
+
+	err := s.w.handleFailure(s.ctx, s.q, s.d, cause)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *PoolTestSuite) TestRetryFailedRequeued() {
+	retryErr := errors.New("retry failed")
+
+	s.d.On("Headers").Return(map[string]interface{}{})
+	s.q.On("Retry", s.ctx, s.d, mock.Anything).Return(retryErr).Once()
+	s.d.On("Nack", true).Return(nil).Once()
+
+	err := s.w.handleFailure(s.ctx, s.q, s.d, t.Transient(errors.New("timeout")))
+
+	s.Equal(retryErr, err)
+	s.assertExpectations()
+}
+
+func (s *PoolTestSuite) TestPermanentDropped() {
+	s.d.On("Nack", false).Return(nil).Once()
+
+	err := s.w.handleFailure(s.ctx, s.q, s.d, t.Permanent(errors.New("bad request")))
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *PoolTestSuite) TestCancelledRequeued() {
+	s.w.cancelWork()
+	s.d.On("Nack", true).Return(nil).Once()
+
+	err := s.w.handleFailure(s.ctx, s.q, s.d, t.Permanent(errors.New("cancelled")))
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *PoolTestSuite) TestInvalidDeliveryDropped() {
+	s.d.On("Headers").Return(map[string]interface{}{})
+	s.d.On("Body").Return([]byte("invalid json"))
+	s.d.On("Nack", false).Return(nil).Once()
+
+	c := newConsumer(s.q, nil, 1)
+	s.w.handleDelivery(c, "mock-0", s.d)
+
+	// Failure recorded without resource.
+	status := c.status()
+	s.Require().Len(status.Errors, 1)
+	s.Empty(status.Errors[0].Resource)
+
+	s.assertExpectations()
+}
+
+// spanRecorder records ended spans.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*export.SpanData
+}
+
+func (r *spanRecorder) OnStart(*export.SpanData) {}
+func (r *spanRecorder) Shutdown()                {}
+func (r *spanRecorder) ForceFlush()              {}
+
+func (r *spanRecorder) OnEnd(sd *export.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = append(r.spans, sd)
+}
+
+// span returns the recorded span with name.
+func (r *spanRecorder) span(name string) *export.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sd := range r.spans {
+		if sd.Name == name {
+			return sd
+		}
+	}
+
+	return nil
+}
+
+func (s *PoolTestSuite) TestDeliveryContinuesTrace() {
+	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	recorder := &spanRecorder{}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	s.w.Tracer = provider.Tracer("test")
+
+	// Published, e.g. by the sniffer.
+	ctx, published := s.w.Tracer.Start(s.ctx, "publish")
+	headers := queue.TraceHeaders(ctx)
+	published.End()
+
+	s.d.On("Headers").Return(headers)
+	s.d.On("Body").Return([]byte("invalid json"))
+	s.d.On("Nack", false).Return(nil).Once()
+
+	s.w.handleDelivery(newConsumer(s.q, nil, 1), "mock-0", s.d)
+
+	handled := recorder.span("crawler.worker.handleDelivery")
+	s.Require().NotNil(handled)
+	s.Equal(published.SpanContext().TraceID, handled.SpanContext.TraceID)
+	s.Equal(published.SpanContext().SpanID, handled.ParentSpanID)
+	s.True(handled.HasRemoteParent)
+
+	crawled := recorder.span("crawler.worker.crawlDelivery")
+	s.Require().NotNil(crawled)
+	s.Equal(published.SpanContext().TraceID, crawled.SpanContext.TraceID)
+	s.Equal(handled.SpanContext.SpanID, crawled.ParentSpanID)
+
+	s.assertExpectations()
+}
+
+func TestPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(PoolTestSuite))
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ipfs-search/ipfs-search/components/breaker"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/protocol/car"
+	"github.com/ipfs-search/ipfs-search/components/protocol/filesystem"
+	"github.com/ipfs-search/ipfs-search/components/protocol/gateway"
+	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// getIPFSProtocol returns the IPFS protocol, probed through its API.
+func (w *Pool) getIPFSProtocol() (protocol.Protocol, breaker.Probe, error) {
+	// Many stat/ls connections
+	ipfsClient, err := utils.GetSecureHTTPClient(w.dialer.DialContext, 1000,
+		w.config.IPFS.TLSConfig(), w.config.IPFS.CredentialsConfig())
+	if err != nil {
+		return nil, nil, fmt.Errorf("configuring IPFS client: %w", err)
+	}
+
+	ipfsProtocol := ipfs.New(w.config.IPFSConfig(), ipfsClient, w.Instrumentation)
+
+	return ipfsProtocol, ipfsProtocol.Ping, nil
+}
+
+// getGatewayProtocol returns the trustless gateway protocol, probed by requesting an inline block.
+func (w *Pool) getGatewayProtocol() (protocol.Protocol, breaker.Probe) {
+	// Many stat/ls connections
+	gatewayClient := utils.GetHTTPClient(w.dialer.DialContext, 1000)
+	gatewayProtocol := gateway.New(w.config.GatewayConfig(), gatewayClient, w.Instrumentation)
+
+	return gatewayProtocol, gatewayProtocol.Ping
+}
+
+// getFilesystemProtocol returns the filesystem protocol, serving file contents to the extractor until in-flight
+// crawls have finished on shutdown.
+func (w *Pool) getFilesystemProtocol() (protocol.Protocol, breaker.Probe, error) {
+	fs := filesystem.New(w.config.FilesystemConfig(), w.Instrumentation)
+
+	log.Printf("Crawling files in %s.", w.config.Filesystem.Root)
+	if err := fs.Serve(w.workCtx); err != nil {
+		return nil, nil, err
+	}
+
+	return fs, fs.Ping, nil
+}
+
+// getCARProtocol returns the CAR protocol, serving file contents to the extractor until in-flight crawls have
+// finished on shutdown. The CAR files are closed on shutdown.
+func (w *Pool) getCARProtocol() (protocol.Protocol, breaker.Probe, error) {
+	c, err := car.New(w.config.CARConfig(), w.Instrumentation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.addCloser(c.Close)
+
+	log.Printf("Crawling CAR files matching %s.", w.config.CAR.Files)
+	if err := c.Serve(w.workCtx); err != nil {
+		return nil, nil, err
+	}
+
+	return c, c.Ping, nil
+}
+
+// getProtocol returns the configured protocol backend along with a probe checking its availability.
+func (w *Pool) getProtocol() (protocol.Protocol, breaker.Probe, error) {
+	switch w.config.Protocol.Backend {
+	case config.IPFSBackend:
+		return w.getIPFSProtocol()
+	case config.GatewayBackend:
+		p, probe := w.getGatewayProtocol()
+		return p, probe, nil
+	case config.FilesystemBackend:
+		return w.getFilesystemProtocol()
+	case config.CARBackend:
+		return w.getCARProtocol()
+	default:
+		return nil, nil, fmt.Errorf("unknown protocol backend '%s'", w.config.Protocol.Backend)
+	}
+}
@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/metrics"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue/disk"
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
+
+	"github.com/ipfs-search/ipfs-search/config"
+)
+
+// consumeQueue is a queue published to by the crawler and consumed by workers, allowing failed deliveries
+// to be retried.
+type consumeQueue interface {
+	queue.Queue
+	queue.Retrier
+	fmt.Stringer
+}
+
+// queues holds the queues for the various types of resources.
+type queues struct {
+	Files       consumeQueue
+	Directories consumeQueue
+	Hashes      consumeQueue
+}
+
+func (w *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
+	queues, err := w.getBackendQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crawler.Queues{
+		Files:       metrics.NewQueue(queues.Files, w.config.Queues.Files.Name, w.Instrumentation),
+		Directories: metrics.NewQueue(queues.Directories, w.config.Queues.Directories.Name, w.Instrumentation),
+		Hashes:      metrics.NewQueue(queues.Hashes, w.config.Queues.Hashes.Name, w.Instrumentation),
+	}, nil
+}
+
+func (w *Pool) getBackendQueues(ctx context.Context) (*queues, error) {
+	switch w.config.Queues.Backend {
+	case config.AMQPBackend:
+		return w.getAMQPQueues(ctx)
+	case config.MemoryBackend:
+		return w.getMemoryQueues(), nil
+	case config.DiskBackend:
+		return w.getDiskQueues()
+	case config.RedisBackend:
+		return w.getRedisQueues(ctx)
+	default:
+		return nil, fmt.Errorf("unknown queue backend '%s'", w.config.Queues.Backend)
+	}
+}
+
+func (w *Pool) getAMQPQueues(ctx context.Context) (*queues, error) {
+	amqpConfig, err := amqp.DialConfig(w.dialer.Dial, w.config.AMQP.TLSConfig(), w.config.AMQP.CredentialsConfig())
+	if err != nil {
+		return nil, fmt.Errorf("configuring AMQP: %w", err)
+	}
+
+	log.Println("Connecting to AMQP.")
+	amqpConnection, err := amqp.NewConnection(ctx, w.config.AMQPConfig(), amqpConfig, w.Instrumentation)
+	if err != nil {
+		return nil, err
+	}
+	w.addCloser(amqpConnection.Close)
+	w.addCheck("amqp", amqpConnection.Ping)
+
+	log.Println("Creating AMQP channels.")
+	fq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Files.Name, prefetchCount(w.config.Workers.FileWorkers))
+	if err != nil {
+		return nil, err
+	}
+
+	dq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Directories.Name, prefetchCount(w.config.Workers.DirectoryWorkers))
+	if err != nil {
+		return nil, err
+	}
+
+	hq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Hashes.Name, prefetchCount(w.config.Workers.HashWorkers))
+	if err != nil {
+		return nil, err
+	}
+
+	return &queues{
+		Files:       fq,
+		Directories: dq,
+		Hashes:      hq,
+	}, nil
+}
+
+// getMemoryQueues returns in-memory queues, shared between publishing and consuming.
+func (w *Pool) getMemoryQueues() *queues {
+	if w.memoryBroker == nil {
+		log.Println("Using in-memory queues.")
+		w.memoryBroker = memory.NewBroker(w.config.MemoryQueueConfig(), w.Instrumentation)
+	}
+
+	return &queues{
+		Files:       w.memoryBroker.Queue(w.config.Queues.Files.Name),
+		Directories: w.memoryBroker.Queue(w.config.Queues.Directories.Name),
+		Hashes:      w.memoryBroker.Queue(w.config.Queues.Hashes.Name),
+	}
+}
+
+// getDiskQueues returns queues persisted on local disk, shared between publishing and consuming.
+func (w *Pool) getDiskQueues() (*queues, error) {
+	if w.diskBroker == nil {
+		log.Printf("Opening queues in %s.", w.config.Queues.Path)
+		b, err := disk.Open(w.config.DiskQueueConfig(), w.Instrumentation)
+		if err != nil {
+			return nil, err
+		}
+
+		w.addCloser(b.Close)
+		w.diskBroker = b
+	}
+
+	var (
+		qs  queues
+		err error
+	)
+
+	if qs.Files, err = w.diskBroker.Queue(w.config.Queues.Files.Name); err != nil {
+		return nil, err
+	}
+
+	if qs.Directories, err = w.diskBroker.Queue(w.config.Queues.Directories.Name); err != nil {
+		return nil, err
+	}
+
+	if qs.Hashes, err = w.diskBroker.Queue(w.config.Queues.Hashes.Name); err != nil {
+		return nil, err
+	}
+
+	return &qs, nil
+}
+
+// getRedisQueues returns queues on Redis Streams, sharing a single client.
+func (w *Pool) getRedisQueues(ctx context.Context) (*queues, error) {
+	cfg := w.config.RedisConfig()
+
+	if w.redisClient == nil {
+		log.Println("Connecting to Redis.")
+		c, err := redis.NewClient(ctx, cfg, w.Instrumentation)
+		if err != nil {
+			return nil, err
+		}
+
+		w.addCloser(c.Close)
+		w.redisClient = c
+		w.addCheck("redis", func(ctx context.Context) error {
+			return c.WithContext(ctx).Ping().Err()
+		})
+	}
+
+	var (
+		qs  queues
+		err error
+	)
+
+	if qs.Files, err = redis.NewQueue(ctx, w.redisClient, cfg, w.config.Queues.Files.Name, w.Instrumentation); err != nil {
+		return nil, err
+	}
+
+	if qs.Directories, err = redis.NewQueue(ctx, w.redisClient, cfg, w.config.Queues.Directories.Name, w.Instrumentation); err != nil {
+		return nil, err
+	}
+
+	if qs.Hashes, err = redis.NewQueue(ctx, w.redisClient, cfg, w.config.Queues.Hashes.Name, w.Instrumentation); err != nil {
+		return nil, err
+	}
+
+	return &qs, nil
+}
@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/config"
+)
+
+// ErrInvalidWorkers is returned when resizing a pool to a negative number of workers.
+var ErrInvalidWorkers = errors.New("invalid number of workers")
+
+// scale starts or stops workers consuming from c until n are running. Stopped workers finish their current crawl.
+func (w *Pool) scale(ctx context.Context, c *consumer, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.stops) < n {
+		workerCtx, stop := context.WithCancel(ctx)
+		name := fmt.Sprintf("%s-%d", c, c.seq)
+
+		c.stops = append(c.stops, stop)
+		c.seq++
+
+		w.workers.Add(1)
+		go w.startWorker(workerCtx, c, name)
+	}
+
+	for len(c.stops) > n {
+		last := len(c.stops) - 1
+
+		c.stops[last]()
+		c.stops = c.stops[:last]
+	}
+
+	c.workers = n
+}
+
+// prefetchCount returns the prefetch count for a number of workers. It is at least 1, as 0 would let a broker push
+// the entire queue to a pool without workers.
+func prefetchCount(workers int) int {
+	if workers < 1 {
+		return 1
+	}
+
+	return workers
+}
+
+// Resize changes the number of workers consuming the named queue, adjusting the prefetch count of the queue to match.
+// Stopped workers finish their current crawl. Before Start, only the number of workers to start is changed.
+func (w *Pool) Resize(ctx context.Context, name string, workers int) error {
+	if workers < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidWorkers, workers)
+	}
+
+	c, err := w.getConsumer(name)
+	if err != nil {
+		return err
+	}
+
+	w.resizeMu.Lock()
+	defer w.resizeMu.Unlock()
+
+	if w.consumeCtx != nil && w.consumeCtx.Err() != nil {
+		return fmt.Errorf("resizing %s: %w", name, w.consumeCtx.Err())
+	}
+
+	if p, ok := c.queue.(queue.Prefetcher); ok {
+		if err := p.SetPrefetch(ctx, prefetchCount(workers)); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Resizing %s to %d workers", c, workers)
+
+	if w.consumeCtx == nil {
+		// Not started yet.
+		c.mu.Lock()
+		c.workers = workers
+		c.mu.Unlock()
+
+		return nil
+	}
+
+	w.scale(w.consumeCtx, c, workers)
+
+	return nil
+}
+
+// Reconfigure resizes the pools to the number of workers in cfg, e.g. after reloading the configuration.
+// All pools are resized, returning the first error.
+func (w *Pool) Reconfigure(ctx context.Context, cfg *config.Workers) error {
+	var firstErr error
+
+	for _, p := range []struct {
+		name    string
+		workers int
+	}{
+		{w.config.Queues.Files.Name, cfg.FileWorkers},
+		{w.config.Queues.Hashes.Name, cfg.HashWorkers},
+		{w.config.Queues.Directories.Name, cfg.DirectoryWorkers},
+	} {
+		if err := w.Resize(ctx, p.name, p.workers); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// prefetchQueue is a consumeQueue mock with adjustable prefetch.
+type prefetchQueue struct {
+	mockQueue
+}
+
+func (q *prefetchQueue) SetPrefetch(ctx context.Context, count int) error {
+	args := q.Called(ctx, count)
+	return args.Error(0)
+}
+
+type ResizeTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	w   *Pool
+	q   *prefetchQueue
+	c   *consumer
+}
+
+func (s *ResizeTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	i := instr.New()
+
+	s.q = &prefetchQueue{}
+	s.c = newConsumer(s.q, make(chan queue.Delivery), 2)
+
+	s.w = &Pool{
+		config:          config.Default(),
+		consumers:       []*consumer{s.c},
+		Instrumentation: i,
+		consumed:        newConsumedCounter(i),
+	}
+}
+
+func (s *ResizeTestSuite) TearDownTest() {
+	s.q.AssertExpectations(s.T())
+}
+
+// waitWorkers asserts all workers exit within a second.
+func (s *ResizeTestSuite) waitWorkers() {
+	done := make(chan struct{})
+	go func() {
+		s.w.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("workers did not exit")
+	}
+}
+
+func (s *ResizeTestSuite) TestResizeBeforeStart() {
+	s.q.On("SetPrefetch", s.ctx, 5).Return(nil).Once()
+
+	s.NoError(s.w.Resize(s.ctx, "mock", 5))
+
+	s.Equal(5, s.c.status().Workers)
+	s.Empty(s.c.stops)
+}
+
+func (s *ResizeTestSuite) TestResizeRunning() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	s.w.consumeCtx = ctx
+	s.w.startPool(ctx, s.c)
+	s.Len(s.c.stops, 2)
+
+	s.q.On("SetPrefetch", s.ctx, 4).Return(nil).Once()
+	s.NoError(s.w.Resize(s.ctx, "mock", 4))
+	s.Len(s.c.stops, 4)
+	s.Equal(4, s.c.status().Workers)
+
+	// Without workers, the broker should not deliver the whole queue.
+	s.q.On("SetPrefetch", s.ctx, 1).Return(nil).Once()
+	s.NoError(s.w.Resize(s.ctx, "mock", 0))
+	s.Empty(s.c.stops)
+
+	// Stopped workers exit without consumption being cancelled.
+	s.waitWorkers()
+
+	// Workers get unique names.
+	s.Equal(4, s.c.seq)
+}
+
+func (s *ResizeTestSuite) TestResizeAfterShutdown() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+
+	s.w.consumeCtx = ctx
+
+	s.Error(s.w.Resize(s.ctx, "mock", 4))
+}
+
+func (s *ResizeTestSuite) TestResizeInvalid() {
+	s.True(errors.Is(s.w.Resize(s.ctx, "mock", -1), ErrInvalidWorkers))
+	s.True(errors.Is(s.w.Resize(s.ctx, "nope", 1), ErrUnknownQueue))
+}
+
+func (s *ResizeTestSuite) TestPrefetchError() {
+	s.q.On("SetPrefetch", s.ctx, 3).Return(errors.New("channel closed")).Once()
+
+	s.Error(s.w.Resize(s.ctx, "mock", 3))
+	s.Equal(2, s.c.status().Workers)
+}
+
+func (s *ResizeTestSuite) TestReconfigure() {
+	s.w.config.Queues.Files.Name = "mock"
+
+	cfg := config.WorkersDefaults()
+	cfg.FileWorkers = 7
+
+	s.q.On("SetPrefetch", s.ctx, 7).Return(nil).Once()
+
+	// Other queues are not consumed by this pool.
+	err := s.w.Reconfigure(s.ctx, &cfg)
+	s.True(errors.Is(err, ErrUnknownQueue))
+	s.Equal(7, s.c.status().Workers)
+}
+
+func TestResizeTestSuite(t *testing.T) {
+	suite.Run(t, new(ResizeTestSuite))
+}
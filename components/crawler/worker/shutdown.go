@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"log"
+)
+
+// addCloser registers a function closing a resource on shutdown.
+func (w *Pool) addCloser(f func() error) {
+	w.closers = append(w.closers, f)
+}
+
+// close closes resources in reverse order of creation, returning the first error.
+func (w *Pool) close() error {
+	var firstErr error
+
+	for i := len(w.closers) - 1; i >= 0; i-- {
+		if err := w.closers[i](); err != nil {
+			log.Printf("Error closing resource: %v", err)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	w.closers = nil
+
+	return firstErr
+}
+
+// Shutdown stops consumption and waits for in-flight crawls to finish, acknowledging or rejecting their
+// deliveries. When ctx is done before crawls have finished, they are cancelled and their deliveries requeued.
+// Finally, queues and indexes are closed.
+func (w *Pool) Shutdown(ctx context.Context) error {
+	ctx, span := w.Tracer.Start(ctx, "crawler.worker.Shutdown")
+	defer span.End()
+
+	if w.stopConsuming != nil {
+		w.stopConsuming()
+	}
+
+	log.Println("Cancelling consumers.")
+	for _, c := range w.consumers {
+		if err := c.queue.Cancel(ctx); err != nil {
+			span.RecordError(ctx, err)
+			log.Printf("Error cancelling consumer: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.workers.Wait()
+		close(done)
+	}()
+
+	log.Println("Waiting for in-flight crawls to finish.")
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("Grace period expired; cancelling in-flight crawls.")
+		w.cancelWork()
+		<-done
+	}
+
+	w.cancelWork()
+
+	log.Println("Closing queues and indexes.")
+	return w.close()
+}
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShutdownTestSuite struct {
+	suite.Suite
+}
+
+func (s *ShutdownTestSuite) TestCloseOrder() {
+	w := &Pool{}
+
+	var closed []int
+
+	w.addCloser(func() error {
+		closed = append(closed, 1)
+		return nil
+	})
+	w.addCloser(func() error {
+		closed = append(closed, 2)
+		return errors.New("middle")
+	})
+	w.addCloser(func() error {
+		closed = append(closed, 3)
+		return errors.New("last")
+	})
+
+	err := w.close()
+
+	// Closed in reverse order, all closers called despite errors, first error returned.
+	s.Equal([]int{3, 2, 1}, closed)
+	s.EqualError(err, "last")
+
+	// Closers are only called once.
+	s.NoError(w.close())
+	s.Len(closed, 3)
+}
+
+func TestShutdownTestSuite(t *testing.T) {
+	suite.Run(t, new(ShutdownTestSuite))
+}
@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/breaker"
+)
+
+// ErrUnknownQueue is returned when pausing or resuming a queue which is not consumed by the pool.
+var ErrUnknownQueue = errors.New("unknown queue")
+
+// CrawlError describes a failed crawl.
+type CrawlError struct {
+	Time     time.Time `json:"time"`
+	Resource string    `json:"resource,omitempty"`
+	Error    string    `json:"error"`
+}
+
+// QueueStatus describes the workers consuming a queue.
+type QueueStatus struct {
+	Queue    string       `json:"queue"`
+	Workers  int          `json:"workers"`
+	Paused   bool         `json:"paused"`
+	Limit    int          `json:"limit,omitempty"` // Adaptive concurrency limit, when enabled.
+	InFlight []string     `json:"in_flight"`       // Resources currently being crawled.
+	Errors   []CrawlError `json:"errors"`          // Most recent failures, oldest first.
+}
+
+// Status describes the state of the pool.
+type Status struct {
+	Queues   []QueueStatus     `json:"queues"`
+	Breakers map[string]string `json:"breakers"` // Circuit breaker state by backend.
+}
+
+// Status returns the current status of the pool.
+func (w *Pool) Status() *Status {
+	s := &Status{
+		Queues:   make([]QueueStatus, 0, len(w.consumers)),
+		Breakers: make(map[string]string, len(w.breakers)),
+	}
+
+	for _, c := range w.consumers {
+		s.Queues = append(s.Queues, c.status())
+	}
+
+	for _, b := range w.breakers {
+		s.Breakers[b.String()] = b.State().String()
+	}
+
+	return s
+}
+
+// getConsumer returns the consumer for the named queue.
+func (w *Pool) getConsumer(name string) (*consumer, error) {
+	for _, c := range w.consumers {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownQueue, name)
+}
+
+// Pause stops workers from consuming the named queue, without interrupting in-flight crawls.
+func (w *Pool) Pause(name string) error {
+	c, err := w.getConsumer(name)
+	if err != nil {
+		return err
+	}
+
+	c.pause()
+
+	return nil
+}
+
+// Resume restarts consumption of the named queue after Pause.
+func (w *Pool) Resume(name string) error {
+	c, err := w.getConsumer(name)
+	if err != nil {
+		return err
+	}
+
+	c.resume()
+
+	return nil
+}
+
+// addCheck registers a readiness check for a backend.
+func (w *Pool) addCheck(name string, check breaker.Probe) {
+	if w.checks == nil {
+		w.checks = make(map[string]breaker.Probe)
+	}
+
+	w.checks[name] = check
+}
+
+// Ready checks whether the backends used by the pool are reachable, returning the result by backend.
+// Backends are checked concurrently, until ctx is done.
+func (w *Pool) Ready(ctx context.Context) map[string]error {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]error, len(w.checks))
+	)
+
+	for name, check := range w.checks {
+		wg.Add(1)
+
+		go func(name string, check breaker.Probe) {
+			defer wg.Done()
+
+			err := check(ctx)
+
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return results
+}
@@ -10,6 +10,11 @@ type Config struct {
 	TikaServerURL  string            // TikaServer is the URL of the ipfs-tika server.
 	RequestTimeout time.Duration     // Timeout for metadata requests for the server.
 	MaxFileSize    datasize.ByteSize // Don't attempt to get metadata for files over this size.
+
+	// GatewayServers maps gateway URLs, e.g. `http://node1:8080`, to the ipfs-tika server fetching from that
+	// gateway. As ipfs-tika requests content by path from its own gateway, this lets resources be extracted
+	// through the IPFS node which fetched them. Other gateways use TikaServerURL.
+	GatewayServers map[string]string
 }
 
 // DefaultConfig returns the default configuration for a Sniffer.
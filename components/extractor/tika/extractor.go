@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
@@ -37,6 +38,17 @@ func (e *Extractor) get(ctx context.Context, url string) (resp *http.Response, e
 	return e.client.Do(req)
 }
 
+// serverURL returns the URL of the ipfs-tika server fetching from the gateway in u.
+func (e *Extractor) serverURL(u *url.URL) string {
+	gateway := url.URL{Scheme: u.Scheme, Host: u.Host}
+
+	if server, ok := e.config.GatewayServers[gateway.String()]; ok {
+		return server
+	}
+
+	return e.config.TikaServerURL
+}
+
 func (e *Extractor) getExtractURL(r *t.AnnotatedResource) string {
 	// TODO: This should be TIKAURL?url=GATEWAYURL (or something similar)
 	gwURL := e.protocol.GatewayURL(r)
@@ -44,7 +56,21 @@ func (e *Extractor) getExtractURL(r *t.AnnotatedResource) string {
 	if err != nil {
 		panic(fmt.Sprintf("unexpected parsing error generating URL: %v", err))
 	}
-	return e.config.TikaServerURL + u.EscapedPath()
+	return strings.TrimSuffix(e.serverURL(u), "/") + u.EscapedPath()
+}
+
+// classifyStatus classifies errors from unexpected HTTP status codes; gateway errors and unavailability
+// imply Tika is unavailable and other server errors are transient, while client errors are permanent.
+func classifyStatus(status int, err error) error {
+	switch {
+	case status == http.StatusBadGateway, status == http.StatusServiceUnavailable,
+		status == http.StatusGatewayTimeout, status == http.StatusTooManyRequests:
+		return t.BackendUnavailable(err)
+	case status >= 500:
+		return t.Transient(err)
+	default:
+		return t.Permanent(err)
+	}
 }
 
 // Extract metadata from a (potentially) referenced resource, updating
@@ -69,21 +95,24 @@ func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m inter
 
 	resp, err := e.get(ctx, e.getExtractURL(r))
 	if err != nil {
-		err := fmt.Errorf("%w: %v", extractor.ErrRequest, err)
+		err := t.ClassifiedError{
+			Class: t.ErrorClass(err),
+			Err:   fmt.Errorf("%w: %v", extractor.ErrRequest, err),
+		}
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		err := fmt.Errorf("%w: unexpected status %s", extractor.ErrUnexpectedResponse, resp.Status)
+		err := classifyStatus(resp.StatusCode, fmt.Errorf("%w: unexpected status %s", extractor.ErrUnexpectedResponse, resp.Status))
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
 
 	// Parse resulting JSON
 	if err := json.NewDecoder(resp.Body).Decode(m); err != nil {
-		err := fmt.Errorf("%w: %v", extractor.ErrUnexpectedResponse, err)
+		err := t.Permanent(fmt.Errorf("%w: %v", extractor.ErrUnexpectedResponse, err))
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
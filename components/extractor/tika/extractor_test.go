@@ -2,6 +2,7 @@ package tika
 
 import (
     "context"
+    "errors"
     "fmt"
     "net/http"
     "net/url"
@@ -240,6 +241,39 @@ func (s TikaTestSuite) TestTika500() {
     err := s.e.Extract(s.ctx, r, &f)
 
     s.Error(err, extractor.ErrUnexpectedResponse)
+    s.Equal(t.ErrTransient, t.ErrorClass(err))
+    s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s TikaTestSuite) TestTika503() {
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    tikaURL := fmt.Sprintf("/ipfs/%s", testCID)
+    gwURL := "http://localhost:8080" + tikaURL
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", tikaURL, mock.Anything).
+        Return(httpmock.Response{
+            Status: 503,
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, &f)
+
+    s.True(errors.Is(err, extractor.ErrUnexpectedResponse))
+    s.Equal(t.ErrBackendUnavailable, t.ErrorClass(err))
     s.mockAPIHandler.AssertExpectations(s.T())
 }
 
@@ -280,6 +314,42 @@ func (s TikaTestSuite) TestExtractInvalidJSON() {
     err := s.e.Extract(s.ctx, r, &f)
 
     s.Error(err, extractor.ErrUnexpectedResponse)
+    s.Equal(t.ErrPermanent, t.ErrorClass(err))
+    s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s TikaTestSuite) TestExtractGatewayServer() {
+    // Content fetched by node2 is extracted by the ipfs-tika server using its gateway.
+    s.cfg.TikaServerURL = "http://default-tika.invalid"
+    s.cfg.GatewayServers = map[string]string{
+        "http://node2:8080": s.mockAPIServer.URL() + "/",
+    }
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    tikaURL := fmt.Sprintf("/ipfs/%s", testCID)
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return("http://node2:8080" + tikaURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", tikaURL, mock.Anything).
+        Return(httpmock.Response{
+            Body: []byte(`{}`),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    s.NoError(s.e.Extract(s.ctx, r, &f))
     s.mockAPIHandler.AssertExpectations(s.T())
 }
 
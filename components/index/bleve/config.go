@@ -0,0 +1,7 @@
+package bleve
+
+// Config represents the configuration for a Bleve index.
+type Config struct {
+	Name string // Name of the index.
+	Path string // Directory containing the index' files; created when it doesn't exist.
+}
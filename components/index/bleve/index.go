@@ -0,0 +1,193 @@
+// Package bleve implements an embedded, on-disk index using Bleve, allowing the crawler to run without Elasticsearch.
+package bleve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// ErrNotFound is returned when updating a document which does not exist.
+var ErrNotFound = errors.New("document not found")
+
+// sourcePrefix prefixes the keys under which document sources are stored in the internal storage of the index.
+const sourcePrefix = "_source/"
+
+// Index wraps a Bleve index to store documents
+type Index struct {
+	idx blevesearch.Index
+	cfg *Config
+
+	// Serialises writes, guaranteeing consistency of read-modify-write updates.
+	mu sync.Mutex
+
+	*instr.Instrumentation
+}
+
+// New opens or, when it doesn't exist, creates an index in the directory specified by the configuration.
+func New(cfg *Config, i *instr.Instrumentation) (*Index, error) {
+	path := filepath.Join(cfg.Path, cfg.Name)
+
+	idx, err := blevesearch.Open(path)
+	if errors.Is(err, blevesearch.ErrorIndexPathDoesNotExist) {
+		if err := os.MkdirAll(cfg.Path, 0755); err != nil {
+			return nil, err
+		}
+
+		idx, err = blevesearch.New(path, blevesearch.NewIndexMapping())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("opening index %s: %w", path, err)
+	}
+
+	return &Index{
+		idx:             idx,
+		cfg:             cfg,
+		Instrumentation: i,
+	}, nil
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	return i.cfg.Name
+}
+
+func sourceKey(id string) []byte {
+	return []byte(sourcePrefix + id)
+}
+
+func (i *Index) getSource(id string) (source, error) {
+	b, err := i.idx.GetInternal(sourceKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if b == nil {
+		return nil, nil
+	}
+
+	s := make(source)
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// write atomically stores the source and indexes its fields.
+func (i *Index) write(id string, s source) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	batch := i.idx.NewBatch()
+
+	if err := batch.Index(id, map[string]interface{}(s)); err != nil {
+		return err
+	}
+	batch.SetInternal(sourceKey(id), b)
+
+	return i.idx.Batch(batch)
+}
+
+// Index a document's properties, identified by id
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.Tracer.Start(ctx, "index.bleve.Index")
+	defer span.End()
+
+	s, err := toSource(properties)
+	if err == nil {
+		i.mu.Lock()
+		err = i.write(id, s)
+		i.mu.Unlock()
+	}
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Update a document's properties, given id
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.Tracer.Start(ctx, "index.bleve.Update")
+	defer span.End()
+
+	err := func() error {
+		update, err := toSource(properties)
+		if err != nil {
+			return err
+		}
+
+		i.mu.Lock()
+		defer i.mu.Unlock()
+
+		s, err := i.getSource(id)
+		if err != nil {
+			return err
+		}
+
+		if s == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+
+		s.merge(update)
+
+		return i.write(id, s)
+	}()
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Get retreives `fields` from document with `id` from the index, returning:
+// - (true, decoding_error) if found (decoding error set when errors in json)
+// - (false, nil) when not found
+// - (false, error) otherwise
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	ctx, span := i.Tracer.Start(ctx, "index.bleve.Get")
+	defer span.End()
+
+	s, err := i.getSource(id)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return false, err
+	}
+
+	if s == nil {
+		return false, nil
+	}
+
+	err = s.filter(fields...).decode(dst)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return true, err
+}
+
+// Close closes the index.
+func (i *Index) Close() error {
+	return i.idx.Close()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Index = &Index{}
@@ -0,0 +1,161 @@
+package bleve
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testCID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+
+type IndexTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	cfg *Config
+	idx *Index
+}
+
+func (s *IndexTestSuite) SetupTest() {
+	var err error
+
+	s.ctx = context.Background()
+
+	dir, err := ioutil.TempDir("", "ipfs-search-bleve")
+	s.Require().NoError(err)
+
+	s.cfg = &Config{
+		Name: "ipfs_files",
+		Path: dir,
+	}
+
+	s.idx, err = New(s.cfg, instr.New())
+	s.Require().NoError(err)
+}
+
+func (s *IndexTestSuite) TearDownTest() {
+	s.idx.Close()
+	os.RemoveAll(s.cfg.Path)
+}
+
+func (s *IndexTestSuite) testFile() *indexTypes.File {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return &indexTypes.File{
+		Document: indexTypes.Document{
+			FirstSeen: now,
+			LastSeen:  now,
+			References: indexTypes.References{
+				{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "fileName.pdf",
+				},
+			},
+			Size: 400,
+		},
+		Content: "The Filecoin Space Race is now live!",
+		Metadata: indexTypes.Metadata{
+			"title": []interface{}{"How Filecoin Supports Video Storage"},
+		},
+	}
+}
+
+func (s *IndexTestSuite) TestGetNotFound() {
+	dst := new(indexTypes.Update)
+
+	found, err := s.idx.Get(s.ctx, testCID, dst, "references", "last-seen")
+
+	s.False(found)
+	s.NoError(err)
+}
+
+func (s *IndexTestSuite) TestIndexGet() {
+	f := s.testFile()
+
+	s.NoError(s.idx.Index(s.ctx, testCID, f))
+
+	dst := new(indexTypes.File)
+	found, err := s.idx.Get(s.ctx, testCID, dst)
+
+	s.True(found)
+	s.NoError(err)
+	s.Equal(f, dst)
+}
+
+func (s *IndexTestSuite) TestGetFields() {
+	f := s.testFile()
+
+	s.NoError(s.idx.Index(s.ctx, testCID, f))
+
+	dst := new(indexTypes.File)
+	found, err := s.idx.Get(s.ctx, testCID, dst, "references", "last-seen", "metadata.title")
+
+	s.True(found)
+	s.NoError(err)
+	s.Equal(f.References, dst.References)
+	s.Equal(f.LastSeen, dst.LastSeen)
+	s.Equal(f.Metadata, dst.Metadata)
+	s.Empty(dst.Content)
+	s.Zero(dst.Size)
+}
+
+func (s *IndexTestSuite) TestUpdate() {
+	f := s.testFile()
+
+	s.NoError(s.idx.Index(s.ctx, testCID, f))
+
+	u := &indexTypes.Update{
+		LastSeen: f.LastSeen.Add(time.Hour),
+		References: append(f.References, indexTypes.Reference{
+			ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+			Name:       "otherName.pdf",
+		}),
+	}
+
+	s.NoError(s.idx.Update(s.ctx, testCID, u))
+
+	dst := new(indexTypes.File)
+	found, err := s.idx.Get(s.ctx, testCID, dst)
+
+	s.True(found)
+	s.NoError(err)
+	s.Equal(u.LastSeen, dst.LastSeen)
+	s.Equal(u.References, dst.References)
+	s.Equal(f.FirstSeen, dst.FirstSeen)
+	s.Equal(f.Content, dst.Content)
+}
+
+func (s *IndexTestSuite) TestUpdateNotFound() {
+	err := s.idx.Update(s.ctx, testCID, &indexTypes.Update{})
+
+	s.True(errors.Is(err, ErrNotFound))
+}
+
+func (s *IndexTestSuite) TestReopen() {
+	f := s.testFile()
+
+	s.NoError(s.idx.Index(s.ctx, testCID, f))
+	s.NoError(s.idx.Close())
+
+	var err error
+	s.idx, err = New(s.cfg, instr.New())
+	s.Require().NoError(err)
+
+	dst := new(indexTypes.Invalid)
+	found, err := s.idx.Get(s.ctx, testCID, dst)
+
+	s.True(found)
+	s.NoError(err)
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
@@ -0,0 +1,93 @@
+package bleve
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// source represents the JSON document as it has been indexed, similar to Elasticsearch' _source.
+type source map[string]interface{}
+
+// toSource converts properties into a source through its JSON representation, so that field names
+// match those of other index implementations.
+func toSource(properties interface{}) (source, error) {
+	b, err := json.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	s := make(source)
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// merge recursively merges fields from src into s, overwriting all but object values. This mimics
+// the partial document updates of Elasticsearch.
+func (s source) merge(src source) {
+	for k, v := range src {
+		srcObj, srcIsObj := v.(map[string]interface{})
+		dstObj, dstIsObj := s[k].(map[string]interface{})
+
+		if srcIsObj && dstIsObj {
+			source(dstObj).merge(srcObj)
+			continue
+		}
+
+		s[k] = v
+	}
+}
+
+// filter returns a new source with only the specified (dot-separated) fields, or all fields when
+// none are specified.
+func (s source) filter(fields ...string) source {
+	if len(fields) == 0 {
+		return s
+	}
+
+	out := make(source)
+
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+
+		src, dst := s, out
+		for i, key := range path {
+			v, ok := src[key]
+			if !ok {
+				break
+			}
+
+			if i == len(path)-1 {
+				dst[key] = v
+				break
+			}
+
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				break
+			}
+
+			next, ok := dst[key].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				dst[key] = next
+			}
+
+			src, dst = obj, next
+		}
+	}
+
+	return out
+}
+
+// decode writes the source into dst.
+func (s source) decode(dst interface{}) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, dst)
+}
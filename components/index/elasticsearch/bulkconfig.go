@@ -0,0 +1,25 @@
+package elasticsearch
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// BulkConfig represents the configuration for bulk indexing to Elasticsearch.
+type BulkConfig struct {
+	Workers       int               // Number of concurrent workers committing bulk requests.
+	FlushActions  int               // Commit when this many requests are pending.
+	FlushBytes    datasize.ByteSize // Commit when pending requests exceed this size.
+	FlushInterval time.Duration     // Commit pending requests at least this often.
+}
+
+// DefaultBulkConfig returns the default configuration for bulk indexing.
+func DefaultBulkConfig() *BulkConfig {
+	return &BulkConfig{
+		Workers:       2,
+		FlushActions:  1000,
+		FlushBytes:    5 * 1024 * 1024, // 5MB
+		FlushInterval: 5 * time.Second,
+	}
+}
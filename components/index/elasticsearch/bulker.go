@@ -0,0 +1,217 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+var (
+	// ErrMissingBulkItem is returned when Elasticsearch's response lacks the result for a bulk request.
+	ErrMissingBulkItem = errors.New("missing item in bulk response")
+
+	// ErrBulkerClosed is returned for requests made after the Bulker has been closed.
+	ErrBulkerClosed = errors.New("bulker closed")
+)
+
+// bulkItem is a request waiting to be committed, along with the channel to report its result on.
+type bulkItem struct {
+	req    elastic.BulkableRequest
+	result chan error
+}
+
+// Bulker batches requests to Elasticsearch, reporting the result of each individual request back to the caller.
+// A single Bulker is safe for concurrent use and can be shared by multiple indexes.
+//
+// Unlike elastic's BulkProcessor, which keeps requests of failed commits around to resend them with the next
+// commit, every batch is committed exactly once; callers decide on retries.
+type Bulker struct {
+	es  *elastic.Client
+	cfg *BulkConfig
+	ctx context.Context
+
+	items   chan bulkItem
+	flushes []chan chan struct{} // Per worker, so that each worker commits once.
+	closed  chan struct{}
+	close   sync.Once
+	wg      sync.WaitGroup
+
+	*instr.Instrumentation
+}
+
+// NewBulker creates and starts a new Bulker, committing requests with ctx.
+func NewBulker(ctx context.Context, es *elastic.Client, cfg *BulkConfig, i *instr.Instrumentation) (*Bulker, error) {
+	b := &Bulker{
+		es:              es,
+		cfg:             cfg,
+		ctx:             ctx,
+		items:           make(chan bulkItem),
+		flushes:         make([]chan chan struct{}, cfg.Workers),
+		closed:          make(chan struct{}),
+		Instrumentation: i,
+	}
+
+	b.wg.Add(cfg.Workers)
+	for n := range b.flushes {
+		b.flushes[n] = make(chan chan struct{})
+		go b.work(b.flushes[n])
+	}
+
+	return b, nil
+}
+
+// batch is the state of a single worker: the requests for the next commit.
+type batch struct {
+	service *elastic.BulkService
+	items   []bulkItem
+}
+
+// full returns whether the batch should be committed, based on the number of actions and its estimated size.
+func (b *Bulker) full(bt *batch) bool {
+	return (b.cfg.FlushActions > 0 && bt.service.NumberOfActions() >= b.cfg.FlushActions) ||
+		(b.cfg.FlushBytes > 0 && bt.service.EstimatedSizeInBytes() >= int64(b.cfg.FlushBytes))
+}
+
+// work adds requests to a batch, committing it when full, on the flush interval and on Flush() and Close().
+func (b *Bulker) work(flushes <-chan chan struct{}) {
+	defer b.wg.Done()
+
+	bt := &batch{service: b.es.Bulk()}
+
+	var tick <-chan time.Time
+	if b.cfg.FlushInterval > 0 {
+		ticker := time.NewTicker(b.cfg.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case item := <-b.items:
+			bt.service.Add(item.req)
+			bt.items = append(bt.items, item)
+
+			if b.full(bt) {
+				b.commit(bt)
+			}
+		case <-tick:
+			b.commit(bt)
+		case done := <-flushes:
+			b.commit(bt)
+			close(done)
+		case <-b.closed:
+			b.commit(bt)
+			return
+		}
+	}
+}
+
+// itemError returns an error for a failed bulk response item, or nil when the item succeeded.
+func itemError(item map[string]*elastic.BulkResponseItem) error {
+	for _, result := range item {
+		if result.Error != nil || result.Status < 200 || result.Status > 299 {
+			return &elastic.Error{
+				Status:  result.Status,
+				Details: result.Error,
+			}
+		}
+	}
+
+	return nil
+}
+
+// commit sends a batch to Elasticsearch and dispatches results to waiting callers. The batch is emptied
+// regardless of the outcome.
+func (b *Bulker) commit(bt *batch) {
+	if len(bt.items) == 0 {
+		return
+	}
+
+	ctx, span := b.Tracer.Start(b.ctx, "index.elasticsearch.Bulker.commit",
+		trace.WithAttributes(label.Int("actions", len(bt.items))),
+	)
+	defer span.End()
+
+	response, err := bt.service.Do(ctx)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	for n, item := range bt.items {
+		// Results are buffered, so that callers which gave up waiting do not block us.
+		switch {
+		case err != nil:
+			item.result <- err
+		case response == nil || n >= len(response.Items):
+			item.result <- ErrMissingBulkItem
+		default:
+			item.result <- itemError(response.Items[n])
+		}
+	}
+
+	// BulkService only resets itself after successful commits.
+	bt.service.Reset()
+	bt.items = nil
+}
+
+// Do adds a request to a batch and waits until it has been committed, returning its result.
+func (b *Bulker) Do(ctx context.Context, r elastic.BulkableRequest) error {
+	ctx, span := b.Tracer.Start(ctx, "index.elasticsearch.Bulker.Do")
+	defer span.End()
+
+	item := bulkItem{r, make(chan error, 1)}
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-b.closed:
+		err = ErrBulkerClosed
+	case b.items <- item:
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case err = <-item.result:
+		}
+	}
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Flush commits all pending requests.
+func (b *Bulker) Flush() error {
+	for _, flushes := range b.flushes {
+		done := make(chan struct{})
+
+		select {
+		case <-b.closed:
+			return ErrBulkerClosed
+		case flushes <- done:
+			<-done
+		}
+	}
+
+	return nil
+}
+
+// Close commits pending requests and stops the Bulker.
+func (b *Bulker) Close() error {
+	b.close.Do(func() { close(b.closed) })
+	b.wg.Wait()
+
+	return nil
+}
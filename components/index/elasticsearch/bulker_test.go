@@ -0,0 +1,228 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// bulkAPI is a stand-in for Elasticsearch's bulk API, recording the documents in each commit. Items for documents
+// with ID "bad" fail with a 404; others succeed. While status is set, commits fail as a whole with that status.
+type bulkAPI struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	status  int
+	commits [][]string
+}
+
+func newBulkAPI() *bulkAPI {
+	a := new(bulkAPI)
+	a.Server = httptest.NewServer(http.HandlerFunc(a.handle))
+
+	return a
+}
+
+func (a *bulkAPI) handle(w http.ResponseWriter, r *http.Request) {
+	type item map[string]map[string]interface{}
+
+	var (
+		ids   []string
+		items []item
+	)
+
+	// Actions alternate with their sources.
+	scanner := bufio.NewScanner(r.Body)
+	for action := true; scanner.Scan(); action = !action {
+		if !action {
+			continue
+		}
+
+		var meta item
+		if err := json.Unmarshal(scanner.Bytes(), &meta); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for op, m := range meta {
+			id := m["_id"].(string)
+			ids = append(ids, id)
+
+			result := map[string]interface{}{"_id": id, "status": 200}
+			if id == "bad" {
+				result["status"] = 404
+				result["error"] = map[string]string{"type": "document_missing_exception"}
+			}
+
+			items = append(items, item{op: result})
+		}
+	}
+
+	a.mu.Lock()
+	a.commits = append(a.commits, ids)
+	status := a.status
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if status != 0 {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error": {"type": "illegal_argument_exception", "reason": "bad request"}, "status": 400}`))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": true, "items": items})
+}
+
+func (a *bulkAPI) setStatus(status int) {
+	a.mu.Lock()
+	a.status = status
+	a.mu.Unlock()
+}
+
+func (a *bulkAPI) getCommits() [][]string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.commits
+}
+
+type BulkerTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+
+	cfg    *BulkConfig
+	es     *elastic.Client
+	bulker *Bulker
+	idx    *BulkIndex
+	api    *bulkAPI
+}
+
+func (s *BulkerTestSuite) SetupTest() {
+	var err error
+
+	s.ctx = context.Background()
+	s.api = newBulkAPI()
+
+	s.es, err = elastic.NewClient(
+		elastic.SetURL(s.api.URL),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(false),
+	)
+	s.Require().NoError(err)
+
+	s.cfg = DefaultBulkConfig()
+	s.cfg.Workers = 1
+	s.cfg.FlushActions = 2
+	s.cfg.FlushInterval = time.Minute
+
+	s.bulker, err = NewBulker(s.ctx, s.es, s.cfg, instr.New())
+	s.Require().NoError(err)
+
+	s.idx = NewBulk(s.es, &Config{Name: "ipfs_files"}, s.bulker, instr.New()).(*BulkIndex)
+}
+
+func (s *BulkerTestSuite) TearDownTest() {
+	s.bulker.Close()
+	s.api.Close()
+}
+
+// index indexes a document in the background, returning a channel with the result.
+func (s *BulkerTestSuite) index(id string) <-chan error {
+	err := make(chan error, 1)
+	go func() {
+		err <- s.idx.Index(s.ctx, id, map[string]string{"content": "test"})
+	}()
+
+	return err
+}
+
+func (s *BulkerTestSuite) TestPerItemErrors() {
+	indexErr := s.index("good")
+	updateErr := make(chan error, 1)
+	go func() {
+		updateErr <- s.idx.Update(s.ctx, "bad", map[string]string{"content": "test"})
+	}()
+
+	s.NoError(<-indexErr)
+
+	err := <-updateErr
+	s.Error(err)
+	s.True(elastic.IsNotFound(err))
+
+	s.Len(s.api.getCommits(), 1)
+}
+
+func (s *BulkerTestSuite) TestRequestError() {
+	s.api.setStatus(400)
+
+	errs := []<-chan error{s.index("a"), s.index("b")}
+
+	// Both items should receive the error for the request as a whole.
+	for _, err := range errs {
+		s.Error(<-err)
+	}
+}
+
+func (s *BulkerTestSuite) TestFailedCommitNotResent() {
+	s.api.setStatus(500)
+
+	errs := []<-chan error{s.index("a"), s.index("b")}
+	for _, err := range errs {
+		s.Error(<-err)
+	}
+
+	s.api.setStatus(0)
+
+	errs = []<-chan error{s.index("c"), s.index("d")}
+	for _, err := range errs {
+		s.NoError(<-err)
+	}
+
+	commits := s.api.getCommits()
+	s.Require().Len(commits, 2)
+	s.ElementsMatch([]string{"c", "d"}, commits[1])
+}
+
+func (s *BulkerTestSuite) TestFlush() {
+	// A single request will not be committed by itself; FlushActions is 2 and FlushInterval a minute.
+	err := s.index("a")
+
+	s.Eventually(func() bool {
+		s.NoError(s.bulker.Flush())
+		return len(s.api.getCommits()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	s.NoError(<-err)
+}
+
+func (s *BulkerTestSuite) TestContextCanceled() {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	err := s.idx.Index(ctx, "a", map[string]string{"content": "test"})
+
+	s.Equal(context.DeadlineExceeded, err)
+}
+
+func (s *BulkerTestSuite) TestClosed() {
+	s.NoError(s.bulker.Close())
+
+	err := s.idx.Index(s.ctx, "a", map[string]string{"content": "test"})
+	s.Equal(ErrBulkerClosed, err)
+}
+
+func TestBulkerTestSuite(t *testing.T) {
+	suite.Run(t, new(BulkerTestSuite))
+}
@@ -0,0 +1,72 @@
+package elasticsearch
+
+import (
+	"context"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// BulkIndex wraps an Elasticsearch index, batching writes through a Bulker.
+// Index and Update block until the batch containing the request has been committed.
+type BulkIndex struct {
+	index  *Index
+	bulker *Bulker
+
+	*instr.Instrumentation
+}
+
+// NewBulk returns a new index, writing through bulker.
+func NewBulk(es *elastic.Client, cfg *Config, bulker *Bulker, i *instr.Instrumentation) index.Index {
+	return &BulkIndex{
+		index: &Index{
+			es:              es,
+			cfg:             cfg,
+			Instrumentation: i,
+		},
+		bulker:          bulker,
+		Instrumentation: i,
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *BulkIndex) String() string {
+	return i.index.String()
+}
+
+// Index a document's properties, identified by id
+func (i *BulkIndex) Index(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.BulkIndex.Index")
+	defer span.End()
+
+	req := elastic.NewBulkIndexRequest().
+		Index(i.index.cfg.Name).
+		Id(id).
+		Doc(properties)
+
+	return classify(i.bulker.Do(ctx, req))
+}
+
+// Update a document's properties, given id
+func (i *BulkIndex) Update(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.BulkIndex.Update")
+	defer span.End()
+
+	req := elastic.NewBulkUpdateRequest().
+		Index(i.index.cfg.Name).
+		Id(id).
+		Doc(properties)
+
+	return classify(i.bulker.Do(ctx, req))
+}
+
+// Get retreives `fields` from document with `id` from the index. As reads are not batched, this
+// is equivalent to Index.Get.
+func (i *BulkIndex) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	return i.index.Get(ctx, id, dst, fields...)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Index = &BulkIndex{}
@@ -0,0 +1,37 @@
+package elasticsearch
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/olivere/elastic/v7"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// classify annotates errors from Elasticsearch with their error class; overload and server
+// errors imply the backend is unavailable, client errors other than 404 are permanent.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if elastic.IsConnErr(err) {
+		return t.BackendUnavailable(err)
+	}
+
+	var e *elastic.Error
+	if errors.As(err, &e) {
+		switch {
+		case e.Status == http.StatusTooManyRequests, e.Status >= 500:
+			return t.BackendUnavailable(err)
+		case e.Status == http.StatusNotFound:
+			// Left unclassified, allowing detection with elastic.IsNotFound.
+			return err
+		case e.Status >= 400:
+			return t.Permanent(err)
+		}
+	}
+
+	return err
+}
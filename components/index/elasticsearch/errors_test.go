@@ -0,0 +1,39 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/suite"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type ErrorsTestSuite struct {
+	suite.Suite
+}
+
+func (s *ErrorsTestSuite) TestClassify() {
+	s.Nil(classify(nil))
+	s.Equal(t.ErrBackendUnavailable, t.ErrorClass(classify(&elastic.Error{Status: 429})))
+	s.Equal(t.ErrBackendUnavailable, t.ErrorClass(classify(&elastic.Error{Status: 503})))
+	s.Equal(t.ErrPermanent, t.ErrorClass(classify(&elastic.Error{Status: 400})))
+	s.Equal(t.ErrBackendUnavailable, t.ErrorClass(classify(elastic.ErrNoClient)))
+}
+
+func (s *ErrorsTestSuite) TestClassifyNotFound() {
+	err := classify(&elastic.Error{Status: 404})
+
+	s.True(elastic.IsNotFound(err))
+}
+
+func (s *ErrorsTestSuite) TestClassifyUnwrap() {
+	err := &elastic.Error{Status: 400}
+
+	s.True(errors.Is(classify(err), err))
+}
+
+func TestErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsTestSuite))
+}
@@ -46,12 +46,10 @@ func (i *Index) Index(ctx context.Context, id string, properties interface{}) er
 		Do(ctx)
 
 	if err != nil {
-		// Handle error
-		return err
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 	}
 
-	return nil
-
+	return classify(err)
 }
 
 // Update a document's properties, given id
@@ -69,7 +67,7 @@ func (i *Index) Update(ctx context.Context, id string, properties interface{}) e
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 	}
 
-	return err
+	return classify(err)
 }
 
 // Get retreives `fields` from document with `id` from the index, returning:
@@ -111,7 +109,7 @@ func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...s
 	default:
 		// Unknown error, propagate
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return false, err
+		return false, classify(err)
 	}
 }
 
@@ -0,0 +1,325 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+var (
+	// ErrNotVersioned is returned when an alias name is in use by a concrete index, or points to an unversioned index.
+	// Such indexes have to be adopted manually, as described in docs/indices.
+	ErrNotVersioned = errors.New("index is not versioned")
+
+	// ErrNotInitialized is returned when attempting to migrate an index which does not exist.
+	ErrNotInitialized = errors.New("index not initialized")
+
+	// ErrReindex is returned when reindexing documents failed.
+	ErrReindex = errors.New("reindexing failed")
+)
+
+// Index represents a versioned index, accessed through Alias.
+type Index struct {
+	Alias   string
+	Mapping Mapping
+}
+
+// Status represents the current state of an Index.
+type Status struct {
+	Alias   string
+	Index   string // Concrete index the alias points to, empty when not initialized.
+	Version int
+	Docs    int64
+}
+
+// Progress represents the progress of a reindex operation.
+type Progress struct {
+	Total            int64 `json:"total"`
+	Created          int64 `json:"created"`
+	Updated          int64 `json:"updated"`
+	Deleted          int64 `json:"deleted"`
+	VersionConflicts int64 `json:"version_conflicts"` // Documents skipped as they were written during migration.
+}
+
+// Done returns the amount of processed documents.
+func (p Progress) Done() int64 {
+	return p.Created + p.Updated + p.Deleted + p.VersionConflicts
+}
+
+// reindexTask represents the result of a reindex task, as returned by the tasks API.
+type reindexTask struct {
+	Completed bool `json:"completed"`
+	Task      struct {
+		Status Progress `json:"status"`
+	} `json:"task"`
+	Error    *elastic.ErrorDetails `json:"error"`
+	Response *struct {
+		Progress
+		Failures []json.RawMessage `json:"failures"`
+	} `json:"response"`
+}
+
+// Manager creates, migrates and reports on versioned indexes.
+type Manager struct {
+	es *elastic.Client
+
+	// PollInterval is the interval at which reindexing progress is polled.
+	PollInterval time.Duration
+
+	*instr.Instrumentation
+}
+
+// New returns a new Manager.
+func New(es *elastic.Client, i *instr.Instrumentation) *Manager {
+	return &Manager{
+		es:              es,
+		PollInterval:    10 * time.Second,
+		Instrumentation: i,
+	}
+}
+
+// current returns the concrete index and version an alias points to, or an empty name when it doesn't exist.
+func (m *Manager) current(ctx context.Context, alias string) (string, int, error) {
+	result, err := m.es.Aliases().Alias(alias).Do(ctx)
+	if elastic.IsNotFound(err) {
+		// Alias does not exist, make sure the name is not taken by an index
+		exists, err := m.es.IndexExists(alias).Do(ctx)
+		if err != nil {
+			return "", 0, err
+		}
+
+		if exists {
+			return "", 0, fmt.Errorf("%w: %s is an index rather than an alias", ErrNotVersioned, alias)
+		}
+
+		return "", 0, nil
+	}
+
+	if err != nil {
+		return "", 0, err
+	}
+
+	indices := result.IndicesByAlias(alias)
+	if len(indices) != 1 {
+		return "", 0, fmt.Errorf("alias %s points to %d indexes, expected 1", alias, len(indices))
+	}
+
+	version, ok := parseVersion(alias, indices[0])
+	if !ok {
+		return "", 0, fmt.Errorf("%w: alias %s points to %s", ErrNotVersioned, alias, indices[0])
+	}
+
+	return indices[0], version, nil
+}
+
+func (m *Manager) create(ctx context.Context, name string, mapping Mapping) error {
+	body, err := mapping.Body()
+	if err != nil {
+		return err
+	}
+
+	_, err = m.es.CreateIndex(name).BodyString(body).Do(ctx)
+
+	return err
+}
+
+// Init creates the first version of an index and points its alias to it, unless the alias already exists.
+// It returns true when the index has been created.
+func (m *Manager) Init(ctx context.Context, i Index) (bool, error) {
+	ctx, span := m.Tracer.Start(ctx, "index.elasticsearch.schema.Init", trace.WithAttributes(label.String("alias", i.Alias)))
+	defer span.End()
+
+	created, err := func() (bool, error) {
+		current, _, err := m.current(ctx, i.Alias)
+		if err != nil {
+			return false, err
+		}
+
+		if current != "" {
+			return false, nil
+		}
+
+		name := versionedName(i.Alias, 1)
+
+		log.Printf("Creating index %s", name)
+		if err := m.create(ctx, name, i.Mapping); err != nil {
+			return false, err
+		}
+
+		log.Printf("Adding alias %s to %s", i.Alias, name)
+		_, err = m.es.Alias().Add(name, i.Alias).Do(ctx)
+
+		return err == nil, err
+	}()
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return created, err
+}
+
+// Status returns the current Status of an index.
+func (m *Manager) Status(ctx context.Context, i Index) (*Status, error) {
+	ctx, span := m.Tracer.Start(ctx, "index.elasticsearch.schema.Status", trace.WithAttributes(label.String("alias", i.Alias)))
+	defer span.End()
+
+	current, version, err := m.current(ctx, i.Alias)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	s := &Status{
+		Alias:   i.Alias,
+		Index:   current,
+		Version: version,
+	}
+
+	if current == "" {
+		return s, nil
+	}
+
+	if s.Docs, err = m.es.Count(current).Do(ctx); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (m *Manager) getTask(ctx context.Context, id string) (*reindexTask, error) {
+	resp, err := m.es.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "GET",
+		Path:   fmt.Sprintf("/_tasks/%s", id),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	task := new(reindexTask)
+	if err := json.Unmarshal(resp.Body, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// waitForReindex polls a reindex task until it completes, reporting progress.
+func (m *Manager) waitForReindex(ctx context.Context, id string, progress func(Progress)) error {
+	ticker := time.NewTicker(m.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		task, err := m.getTask(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if task.Completed {
+			switch {
+			case task.Error != nil:
+				return fmt.Errorf("%w: %s: %s", ErrReindex, task.Error.Type, task.Error.Reason)
+			case task.Response == nil:
+				return fmt.Errorf("%w: no response for task %s", ErrReindex, id)
+			case len(task.Response.Failures) > 0:
+				return fmt.Errorf("%w: %d failures, first: %s", ErrReindex, len(task.Response.Failures), task.Response.Failures[0])
+			}
+
+			progress(task.Response.Progress)
+
+			return nil
+		}
+
+		progress(task.Task.Status)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// reindex copies documents from index src to dst, skipping documents which already exist in dst.
+func (m *Manager) reindex(ctx context.Context, src, dst string, progress func(Progress)) error {
+	task, err := m.es.Reindex().
+		SourceIndex(src).
+		Destination(elastic.NewReindexDestination().Index(dst).OpType("create")).
+		Conflicts("proceed").
+		WaitForCompletion(false).
+		DoAsync(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrReindex, err)
+	}
+
+	if err := m.waitForReindex(ctx, task.TaskId, progress); err != nil {
+		return err
+	}
+
+	// Make copied documents searchable right away.
+	_, err = m.es.Refresh(dst).Do(ctx)
+
+	return err
+}
+
+// Migrate creates the next version of an index with the current mapping, atomically points the alias to it and
+// then copies all documents from the previous version. Progress is called periodically during reindexing.
+// The previous version is left in place and returned, so that it can be removed after verification.
+//
+// As the alias points to the new version throughout, writes need not be stopped. Documents written during
+// migration are not overwritten by their previous version, and searches return documents as soon as they have
+// been copied. When reindexing fails, the alias has already been moved; the previous version is returned along
+// with an ErrReindex error, so that reindexing can be retried from it.
+func (m *Manager) Migrate(ctx context.Context, i Index, progress func(Progress)) (string, error) {
+	ctx, span := m.Tracer.Start(ctx, "index.elasticsearch.schema.Migrate", trace.WithAttributes(label.String("alias", i.Alias)))
+	defer span.End()
+
+	previous, err := func() (string, error) {
+		current, version, err := m.current(ctx, i.Alias)
+		if err != nil {
+			return "", err
+		}
+
+		if current == "" {
+			return "", fmt.Errorf("%w: %s", ErrNotInitialized, i.Alias)
+		}
+
+		next := versionedName(i.Alias, version+1)
+
+		log.Printf("Creating index %s", next)
+		if err := m.create(ctx, next, i.Mapping); err != nil {
+			return "", err
+		}
+
+		// Write to the new version from now on.
+		log.Printf("Moving alias %s from %s to %s", i.Alias, current, next)
+		if _, err := m.es.Alias().Remove(current, i.Alias).Add(next, i.Alias).Do(ctx); err != nil {
+			return "", err
+		}
+
+		log.Printf("Reindexing %s to %s", current, next)
+		if err := m.reindex(ctx, current, next, progress); err != nil {
+			return current, fmt.Errorf("%w (alias %s already points to %s)", err, i.Alias, next)
+		}
+
+		return current, nil
+	}()
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return previous, err
+}
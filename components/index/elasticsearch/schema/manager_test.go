@@ -0,0 +1,262 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dankinder/httpmock"
+	"github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type ManagerTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	m   *Manager
+	i   Index
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+	responseHeader http.Header
+
+	swapped bool // Whether the alias has been swapped.
+}
+
+func (s *ManagerTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.swapped = false
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+	s.responseHeader = http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+
+	es, err := elastic.NewClient(
+		elastic.SetURL(s.mockAPIServer.URL()),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(false),
+	)
+	s.Require().NoError(err)
+
+	s.m = New(es, instr.New())
+	s.m.PollInterval = time.Millisecond
+
+	s.i = Index{
+		Alias:   "ipfs_files",
+		Mapping: Files,
+	}
+}
+
+func (s *ManagerTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *ManagerTestSuite) respond(method, path, body string) {
+	s.mockAPIHandler.
+		On("Handle", method, path, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(body),
+		}).
+		Once()
+}
+
+func (s *ManagerTestSuite) aliasMissing() {
+	s.mockAPIHandler.
+		On("Handle", "GET", "/_alias/ipfs_files", mock.Anything).
+		Return(httpmock.Response{
+			Status: 404,
+			Header: s.responseHeader,
+			Body:   []byte(`{"error": "alias [ipfs_files] missing", "status": 404}`),
+		}).
+		Once()
+}
+
+func (s *ManagerTestSuite) TestInit() {
+	s.aliasMissing()
+
+	s.mockAPIHandler.
+		On("Handle", "HEAD", "/ipfs_files", mock.Anything).
+		Return(httpmock.Response{Status: 404}).
+		Once()
+
+	body, err := Files.Body()
+	s.Require().NoError(err)
+
+	s.mockAPIHandler.
+		On("Handle", "PUT", "/ipfs_files_v1", []byte(body)).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"acknowledged": true, "shards_acknowledged": true, "index": "ipfs_files_v1"}`),
+		}).
+		Once()
+
+	s.respond("POST", "/_aliases", `{"acknowledged": true}`)
+
+	created, err := s.m.Init(s.ctx, s.i)
+
+	s.NoError(err)
+	s.True(created)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *ManagerTestSuite) TestInitExisting() {
+	s.respond("GET", "/_alias/ipfs_files", `{"ipfs_files_v3": {"aliases": {"ipfs_files": {}}}}`)
+
+	created, err := s.m.Init(s.ctx, s.i)
+
+	s.NoError(err)
+	s.False(created)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *ManagerTestSuite) TestInitUnversioned() {
+	s.aliasMissing()
+
+	s.mockAPIHandler.
+		On("Handle", "HEAD", "/ipfs_files", mock.Anything).
+		Return(httpmock.Response{Status: 200}).
+		Once()
+
+	created, err := s.m.Init(s.ctx, s.i)
+
+	s.True(errors.Is(err, ErrNotVersioned))
+	s.False(created)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *ManagerTestSuite) TestStatus() {
+	s.respond("GET", "/_alias/ipfs_files", `{"ipfs_files_v3": {"aliases": {"ipfs_files": {}}}}`)
+	s.respond("POST", "/ipfs_files_v3/_count", `{"count": 42}`)
+
+	status, err := s.m.Status(s.ctx, s.i)
+
+	s.NoError(err)
+	s.Equal(&Status{
+		Alias:   "ipfs_files",
+		Index:   "ipfs_files_v3",
+		Version: 3,
+		Docs:    42,
+	}, status)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+// swapAlias expects the alias to be swapped from v3 to v4 in a single, atomic request.
+func (s *ManagerTestSuite) swapAlias() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_aliases", mock.MatchedBy(func(body []byte) bool {
+			var actions struct {
+				Actions []map[string]map[string]string `json:"actions"`
+			}
+
+			if err := json.Unmarshal(body, &actions); err != nil || len(actions.Actions) != 2 {
+				return false
+			}
+
+			return actions.Actions[0]["remove"]["index"] == "ipfs_files_v3" &&
+				actions.Actions[1]["add"]["index"] == "ipfs_files_v4"
+		})).
+		Run(func(mock.Arguments) { s.swapped = true }).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"acknowledged": true}`),
+		}).
+		Once()
+}
+
+// reindex expects documents to be copied from v3 to v4 without overwriting documents written to v4, after writes
+// have been directed to v4 by swapping the alias.
+func (s *ManagerTestSuite) reindex() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_reindex?wait_for_completion=false", mock.MatchedBy(func(body []byte) bool {
+			var request struct {
+				Conflicts string `json:"conflicts"`
+				Source    struct {
+					Index string `json:"index"`
+				} `json:"source"`
+				Dest struct {
+					Index  string `json:"index"`
+					OpType string `json:"op_type"`
+				} `json:"dest"`
+			}
+
+			if err := json.Unmarshal(body, &request); err != nil {
+				return false
+			}
+
+			return s.swapped && request.Source.Index == "ipfs_files_v3" && request.Dest.Index == "ipfs_files_v4" &&
+				request.Dest.OpType == "create" && request.Conflicts == "proceed"
+		})).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"task": "node:1"}`),
+		}).
+		Once()
+}
+
+func (s *ManagerTestSuite) TestMigrate() {
+	s.respond("GET", "/_alias/ipfs_files", `{"ipfs_files_v3": {"aliases": {"ipfs_files": {}}}}`)
+	s.respond("PUT", "/ipfs_files_v4", `{"acknowledged": true, "index": "ipfs_files_v4"}`)
+	s.swapAlias()
+	s.reindex()
+	s.respond("GET", "/_tasks/node:1", `{"completed": false, "task": {"status": {"total": 10, "created": 5}}}`)
+	s.respond("GET", "/_tasks/node:1", `{"completed": true, "task": {"status": {"total": 10, "created": 9}}, "response": {"total": 10, "created": 9, "version_conflicts": 1, "failures": []}}`)
+	s.respond("POST", "/ipfs_files_v4/_refresh", `{"_shards": {"total": 1, "successful": 1, "failed": 0}}`)
+
+	var progress []Progress
+
+	previous, err := s.m.Migrate(s.ctx, s.i, func(p Progress) {
+		progress = append(progress, p)
+	})
+
+	s.NoError(err)
+	s.Equal("ipfs_files_v3", previous)
+	s.Equal([]Progress{
+		{Total: 10, Created: 5},
+		{Total: 10, Created: 9, VersionConflicts: 1},
+	}, progress)
+	s.Equal(int64(10), progress[1].Done())
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *ManagerTestSuite) TestMigrateFailures() {
+	s.respond("GET", "/_alias/ipfs_files", `{"ipfs_files_v3": {"aliases": {"ipfs_files": {}}}}`)
+	s.respond("PUT", "/ipfs_files_v4", `{"acknowledged": true, "index": "ipfs_files_v4"}`)
+	s.swapAlias()
+	s.reindex()
+	s.respond("GET", "/_tasks/node:1", `{"completed": true, "response": {"total": 10, "created": 9, "failures": [{"id": "x"}]}}`)
+
+	previous, err := s.m.Migrate(s.ctx, s.i, func(Progress) {})
+
+	// Previous version returned, to retry reindexing from.
+	s.True(errors.Is(err, ErrReindex))
+	s.Equal("ipfs_files_v3", previous)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *ManagerTestSuite) TestMigrateNotInitialized() {
+	s.aliasMissing()
+
+	s.mockAPIHandler.
+		On("Handle", "HEAD", "/ipfs_files", mock.Anything).
+		Return(httpmock.Response{Status: 404}).
+		Once()
+
+	_, err := s.m.Migrate(s.ctx, s.i, func(Progress) {})
+
+	s.True(errors.Is(err, ErrNotInitialized))
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func TestManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(ManagerTestSuite))
+}
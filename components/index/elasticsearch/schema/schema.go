@@ -0,0 +1,59 @@
+/*
+Package schema manages the Elasticsearch indexes used by ipfs-search: their mappings, creation and migration.
+
+Indexes are versioned; each index is addressed through an alias (e.g. `ipfs_files`) pointing to a concrete,
+versioned index (e.g. `ipfs_files_v3`). Migrating creates the next version with the current mappings,
+reindexes existing documents into it and atomically moves the alias.
+*/
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:embed mappings/*.json
+var mappings embed.FS
+
+// Mapping identifies the embedded settings and mappings for a kind of index.
+type Mapping string
+
+// Available mappings.
+const (
+	Files       Mapping = "files"
+	Directories Mapping = "directories"
+	Invalids    Mapping = "invalids"
+)
+
+// Body returns the index settings and mappings, suitable as body for index creation.
+func (m Mapping) Body() (string, error) {
+	b, err := mappings.ReadFile(fmt.Sprintf("mappings/%s.json", m))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// versionedName returns the name of the concrete index for an alias and version.
+func versionedName(alias string, version int) string {
+	return fmt.Sprintf("%s_v%d", alias, version)
+}
+
+// parseVersion returns the version of a concrete index behind an alias, or false when it is not versioned.
+func parseVersion(alias string, name string) (int, bool) {
+	prefix := alias + "_v"
+
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+	if err != nil {
+		return 0, false
+	}
+
+	return version, true
+}
@@ -0,0 +1,27 @@
+package limiter
+
+import (
+	"time"
+)
+
+// Config specifies the configuration for concurrency limiters.
+type Config struct {
+	Enabled          bool          // Whether concurrency is limited adaptively.
+	MinLimit         int           // Lower bound of the concurrency limit.
+	MaxLimit         int           // Upper bound of the concurrency limit, which is also the initial limit.
+	LatencyThreshold time.Duration // Requests slower than this indicate overload.
+	Backoff          float64       // Factor by which the limit is multiplied on overload.
+	DecreaseInterval time.Duration // Minimum time between decreases, so that a burst of slow requests decreases the limit once.
+}
+
+// DefaultConfig returns the default configuration for concurrency limiters.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:          false,
+		MinLimit:         1,
+		MaxLimit:         120,
+		LatencyThreshold: 10 * time.Second,
+		Backoff:          0.75,
+		DecreaseInterval: time.Second,
+	}
+}
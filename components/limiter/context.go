@@ -0,0 +1,31 @@
+package limiter
+
+import (
+	"context"
+	"time"
+)
+
+type contextKey struct{}
+
+// NewContext returns a context carrying l, to which wrapped backends report their requests.
+func NewContext(ctx context.Context, l *Limiter) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Limiter carried by ctx, or nil.
+func FromContext(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(contextKey{}).(*Limiter)
+	return l
+}
+
+// observe reports a request started at start to the Limiter carried by ctx, if any.
+func observe(ctx context.Context, start time.Time, err error) {
+	observeLatency(ctx, time.Since(start), err)
+}
+
+// observeLatency reports a request with the given latency to the Limiter carried by ctx, if any.
+func observeLatency(ctx context.Context, latency time.Duration, err error) {
+	if l := FromContext(ctx); l != nil {
+		l.Observe(latency, err)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Package limiter implements adaptive concurrency limits, based on the latency and timeouts of backend requests.
+
+Limits follow additive increase, multiplicative decrease (AIMD): every request completing in time raises the limit by
+1/limit, such that it increases by about one for each limit's worth of requests. Requests which are slower than a
+threshold, time out or find their backend unavailable multiply the limit by a backoff factor.
+*/
+package limiter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Limiter limits the number of concurrent operations, adapting the limit to observed requests. It is concurrency-safe.
+type Limiter struct {
+	name string
+	cfg  *Config
+
+	mu           sync.Mutex
+	limit        float64
+	inFlight     int
+	lastDecrease time.Time
+	released     chan struct{} // Closed when capacity may have become available, then replaced.
+
+	limitCounter metric.BoundInt64UpDownCounter // Current limit.
+}
+
+// New returns a new Limiter for the operations identified by name, starting at the maximum limit.
+func New(name string, cfg *Config, i *instr.Instrumentation) *Limiter {
+	l := &Limiter{
+		name:     name,
+		cfg:      cfg,
+		limit:    float64(cfg.MaxLimit),
+		released: make(chan struct{}),
+	}
+
+	l.limitCounter = metric.Must(i.Meter).NewInt64UpDownCounter(
+		"ipfs_search.limiter.limit",
+		metric.WithDescription("Current adaptive concurrency limit per queue."),
+	).Bind(label.String("queue", name))
+	l.limitCounter.Add(context.Background(), int64(cfg.MaxLimit))
+
+	return l
+}
+
+// String returns the name of the limiter.
+func (l *Limiter) String() string {
+	return l.name
+}
+
+// Limit returns the current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// notify wakes up callers waiting in Acquire; must be called with mu held.
+func (l *Limiter) notify() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Acquire blocks until the number of operations in flight is below the limit or ctx is done, in which case the
+// context's error is returned. Every successful Acquire should be followed by Release.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// Release ends an operation started by Acquire.
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.notify()
+}
+
+// overloaded returns whether a request which took latency and resulted in err indicates overload of the backend.
+func (l *Limiter) overloaded(latency time.Duration, err error) bool {
+	return latency > l.cfg.LatencyThreshold ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		t.ErrorClass(err) == t.ErrBackendUnavailable
+}
+
+// Observe adapts the limit to a backend request which took latency and resulted in err.
+func (l *Limiter) Observe(latency time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		// Cancelled requests, e.g. on shutdown, say nothing about the backend.
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old := int(l.limit)
+
+	if l.overloaded(latency, err) {
+		if time.Since(l.lastDecrease) < l.cfg.DecreaseInterval {
+			return
+		}
+
+		l.limit = math.Max(l.limit*l.cfg.Backoff, float64(l.cfg.MinLimit))
+		l.lastDecrease = time.Now()
+	} else {
+		l.limit = math.Min(l.limit+1/l.limit, float64(l.cfg.MaxLimit))
+	}
+
+	if limit := int(l.limit); limit != old {
+		l.limitCounter.Add(context.Background(), int64(limit-old))
+
+		if limit > old {
+			l.notify()
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type LimiterTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	cfg *Config
+	l   *Limiter
+}
+
+func (s *LimiterTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.cfg = &Config{
+		Enabled:          true,
+		MinLimit:         2,
+		MaxLimit:         4,
+		LatencyThreshold: time.Second,
+		Backoff:          0.5,
+		DecreaseInterval: 0,
+	}
+
+	s.l = New("test", s.cfg, instr.New())
+}
+
+func (s *LimiterTestSuite) TestStartsAtMax() {
+	s.Equal(4, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestSlowDecreases() {
+	s.l.Observe(2*time.Second, nil)
+	s.Equal(2, s.l.Limit())
+
+	// Bounded by the minimum.
+	s.l.Observe(2*time.Second, nil)
+	s.Equal(2, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestTimeoutDecreases() {
+	s.l.Observe(time.Millisecond, context.DeadlineExceeded)
+	s.Equal(2, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestUnavailableDecreases() {
+	s.l.Observe(time.Millisecond, t.BackendUnavailable(errors.New("connection refused")))
+	s.Equal(2, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestIgnoresCancelled() {
+	s.l.Observe(2*time.Second, context.Canceled)
+	s.Equal(4, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestInvalidIsNoOverload() {
+	s.l.Observe(time.Millisecond, t.Invalid(errors.New("unsupported")))
+	s.Equal(4, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestDecreaseInterval() {
+	s.cfg.DecreaseInterval = time.Hour
+	s.cfg.MinLimit = 1
+
+	s.l.Observe(2*time.Second, nil)
+	s.l.Observe(2*time.Second, nil)
+
+	// Decreased once.
+	s.Equal(2, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestAdditiveIncrease() {
+	s.l.Observe(2*time.Second, nil)
+	s.Equal(2, s.l.Limit())
+
+	// Increases by one after about limit successful requests.
+	s.l.Observe(time.Millisecond, nil)
+	s.l.Observe(time.Millisecond, nil)
+	s.Equal(2, s.l.Limit())
+	s.l.Observe(time.Millisecond, nil)
+	s.Equal(3, s.l.Limit())
+
+	// Bounded by the maximum.
+	for i := 0; i < 100; i++ {
+		s.l.Observe(time.Millisecond, nil)
+	}
+	s.Equal(4, s.l.Limit())
+}
+
+func (s *LimiterTestSuite) TestAcquireBlocksAtLimit() {
+	for i := 0; i < 4; i++ {
+		s.NoError(s.l.Acquire(s.ctx))
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	s.Equal(context.DeadlineExceeded, s.l.Acquire(ctx))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- s.l.Acquire(s.ctx)
+	}()
+
+	s.l.Release()
+	s.NoError(<-acquired)
+}
+
+func (s *LimiterTestSuite) TestDecreaseLimitsAcquire() {
+	s.l.Observe(2*time.Second, nil)
+
+	s.NoError(s.l.Acquire(s.ctx))
+	s.NoError(s.l.Acquire(s.ctx))
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	s.Equal(context.DeadlineExceeded, s.l.Acquire(ctx))
+}
+
+func (s *LimiterTestSuite) TestContext() {
+	s.Nil(FromContext(s.ctx))
+	s.Equal(s.l, FromContext(NewContext(s.ctx, s.l)))
+}
+
+func TestLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(LimiterTestSuite))
+}
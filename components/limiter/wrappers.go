@@ -0,0 +1,100 @@
+package limiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Protocol wraps a Protocol, reporting requests to the Limiter in their context.
+type Protocol struct {
+	p protocol.Protocol
+}
+
+// NewProtocol returns a Protocol reporting requests to p to the Limiter in their context.
+func NewProtocol(p protocol.Protocol) protocol.Protocol {
+	return &Protocol{p}
+}
+
+// GatewayURL returns the URL to request a resource from the gateway.
+func (p *Protocol) GatewayURL(r *t.AnnotatedResource) string {
+	return p.p.GatewayURL(r)
+}
+
+// Stat returns a AnnotatedResource with Type and Size populated.
+func (p *Protocol) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	start := time.Now()
+	err := p.p.Stat(ctx, r)
+	observe(ctx, start, err)
+
+	return err
+}
+
+// Ls returns a channel with AnnotatedResource's with Type and Size populated.
+// The latency reported is that of the first entry, as streaming entries waits for the consumer of out.
+func (p *Protocol) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	var (
+		start   = time.Now()
+		latency time.Duration // Written by the forwarder, until done is closed.
+		entries = make(chan *t.AnnotatedResource)
+		done    = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		for e := range entries {
+			if latency == 0 {
+				latency = time.Since(start)
+			}
+
+			if ctx.Err() == nil {
+				select {
+				case out <- e:
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+
+	err := p.p.Ls(ctx, r, entries)
+	close(entries)
+	<-done
+
+	if latency == 0 {
+		// Empty directory or failure before the first entry.
+		latency = time.Since(start)
+	}
+
+	observeLatency(ctx, latency, err)
+
+	return err
+}
+
+// Extractor wraps an Extractor, reporting requests to the Limiter in their context.
+type Extractor struct {
+	e extractor.Extractor
+}
+
+// NewExtractor returns an Extractor reporting extraction by e to the Limiter in its context.
+func NewExtractor(e extractor.Extractor) extractor.Extractor {
+	return &Extractor{e}
+}
+
+// Extract metadata from a (potentially) referenced resource, updating Metadata or returning an error.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	start := time.Now()
+	err := e.e.Extract(ctx, r, m)
+	observe(ctx, start, err)
+
+	return err
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ protocol.Protocol   = &Protocol{}
+	_ extractor.Extractor = &Extractor{}
+)
@@ -0,0 +1,120 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// lsProtocol lists entries after a delay.
+type lsProtocol struct {
+	protocol.Protocol
+	delay   time.Duration
+	entries int
+}
+
+func (p *lsProtocol) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	time.Sleep(p.delay)
+
+	for n := 0; n < p.entries; n++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- &t.AnnotatedResource{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmEntry"}}:
+		}
+	}
+
+	return nil
+}
+
+type WrappersTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	l   *Limiter
+	dir *t.AnnotatedResource
+}
+
+func (s *WrappersTestSuite) SetupTest() {
+	cfg := &Config{
+		Enabled:          true,
+		MinLimit:         2,
+		MaxLimit:         4,
+		LatencyThreshold: 50 * time.Millisecond,
+		Backoff:          0.5,
+	}
+
+	s.l = New("test", cfg, instr.New())
+	s.ctx = NewContext(context.Background(), s.l)
+	s.dir = &t.AnnotatedResource{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmDir"}}
+}
+
+// consume receives entries from out, waiting delay before each.
+func consume(out <-chan *t.AnnotatedResource, delay time.Duration) <-chan int {
+	count := make(chan int, 1)
+
+	go func() {
+		n := 0
+		for range out {
+			time.Sleep(delay)
+			n++
+		}
+		count <- n
+	}()
+
+	return count
+}
+
+func (s *WrappersTestSuite) TestLsSlowConsumer() {
+	p := NewProtocol(&lsProtocol{entries: 3})
+
+	out := make(chan *t.AnnotatedResource)
+	count := consume(out, 40*time.Millisecond)
+
+	s.NoError(p.Ls(s.ctx, s.dir, out))
+	close(out)
+
+	s.Equal(3, <-count)
+
+	// Streaming took longer than the threshold, but the backend responded right away.
+	s.Equal(4, s.l.Limit())
+}
+
+func (s *WrappersTestSuite) TestLsSlowBackend() {
+	p := NewProtocol(&lsProtocol{delay: 100 * time.Millisecond, entries: 1})
+
+	out := make(chan *t.AnnotatedResource)
+	count := consume(out, 0)
+
+	s.NoError(p.Ls(s.ctx, s.dir, out))
+	close(out)
+
+	s.Equal(1, <-count)
+	s.Equal(2, s.l.Limit())
+}
+
+func (s *WrappersTestSuite) TestLsEmpty() {
+	p := NewProtocol(&lsProtocol{delay: 100 * time.Millisecond})
+
+	s.NoError(p.Ls(s.ctx, s.dir, make(chan *t.AnnotatedResource)))
+	s.Equal(2, s.l.Limit())
+}
+
+func (s *WrappersTestSuite) TestLsCanceled() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	p := NewProtocol(&lsProtocol{entries: 3})
+
+	// Returns without blocking on out, which nobody reads.
+	cancel()
+	p.Ls(ctx, s.dir, make(chan *t.AnnotatedResource))
+}
+
+func TestWrappersTestSuite(t *testing.T) {
+	suite.Run(t, new(WrappersTestSuite))
+}
@@ -0,0 +1,27 @@
+// Package metrics wraps components to record the duration and outcome of their operations as metrics.
+package metrics
+
+import (
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// status returns a label with the outcome of an operation.
+func status(err error) label.KeyValue {
+	if err != nil {
+		return label.String("status", "error")
+	}
+
+	return label.String("status", "ok")
+}
+
+// newDuration returns a histogram recording durations in seconds.
+func newDuration(i *instr.Instrumentation, name, description string) metric.Float64ValueRecorder {
+	return metric.Must(i.Meter).NewFloat64ValueRecorder(
+		name,
+		metric.WithDescription(description),
+		metric.WithUnit("s"),
+	)
+}
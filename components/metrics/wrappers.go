@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Protocol wraps a Protocol, recording the duration of requests.
+type Protocol struct {
+	p        protocol.Protocol
+	duration metric.Float64ValueRecorder
+}
+
+// NewProtocol returns a Protocol recording the duration of requests to p.
+func NewProtocol(p protocol.Protocol, i *instr.Instrumentation) protocol.Protocol {
+	return &Protocol{
+		p:        p,
+		duration: newDuration(i, "ipfs_search.protocol.duration", "Duration of protocol requests by operation."),
+	}
+}
+
+// GatewayURL returns the URL to request a resource from the gateway.
+func (p *Protocol) GatewayURL(r *t.AnnotatedResource) string {
+	return p.p.GatewayURL(r)
+}
+
+// Stat returns a AnnotatedResource with Type and Size populated.
+func (p *Protocol) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	start := time.Now()
+	err := p.p.Stat(ctx, r)
+	p.duration.Record(ctx, instr.Since(start), label.String("operation", "stat"), status(err))
+
+	return err
+}
+
+// Ls returns a channel with AnnotatedResource's with Type and Size populated.
+func (p *Protocol) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	start := time.Now()
+	err := p.p.Ls(ctx, r, out)
+	p.duration.Record(ctx, instr.Since(start), label.String("operation", "ls"), status(err))
+
+	return err
+}
+
+// Extractor wraps an Extractor, recording the duration of extraction.
+type Extractor struct {
+	e        extractor.Extractor
+	duration metric.Float64ValueRecorder
+}
+
+// NewExtractor returns an Extractor recording the duration of extraction by e.
+func NewExtractor(e extractor.Extractor, i *instr.Instrumentation) extractor.Extractor {
+	return &Extractor{
+		e:        e,
+		duration: newDuration(i, "ipfs_search.extractor.duration", "Duration of metadata extraction."),
+	}
+}
+
+// Extract metadata from a (potentially) referenced resource, updating Metadata or returning an error.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	start := time.Now()
+	err := e.e.Extract(ctx, r, m)
+	e.duration.Record(ctx, instr.Since(start), status(err))
+
+	return err
+}
+
+// Index wraps an Index, recording the duration of operations.
+type Index struct {
+	i        index.Index
+	name     label.KeyValue
+	duration metric.Float64ValueRecorder
+}
+
+// NewIndex returns an Index recording the duration of operations on i, labeled with name.
+func NewIndex(i index.Index, name string, inst *instr.Instrumentation) index.Index {
+	return &Index{
+		i:        i,
+		name:     label.String("index", name),
+		duration: newDuration(inst, "ipfs_search.index.duration", "Duration of index operations by index and operation."),
+	}
+}
+
+// String returns the name of the wrapped index, for convenient logging.
+func (i *Index) String() string {
+	return fmt.Sprint(i.i)
+}
+
+func (i *Index) record(ctx context.Context, start time.Time, operation string, err error) {
+	i.duration.Record(ctx, instr.Since(start), i.name, label.String("operation", operation), status(err))
+}
+
+// Index a document's properties, identified by id.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	start := time.Now()
+	err := i.i.Index(ctx, id, properties)
+	i.record(ctx, start, "index", err)
+
+	return err
+}
+
+// Update a document's properties, given id.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	start := time.Now()
+	err := i.i.Update(ctx, id, properties)
+	i.record(ctx, start, "update", err)
+
+	return err
+}
+
+// Get retreives `fields` from document with `id` from the index.
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	start := time.Now()
+	found, err := i.i.Get(ctx, id, dst, fields...)
+	i.record(ctx, start, "get", err)
+
+	return found, err
+}
+
+// Queue wraps a Queue, counting published messages.
+type Queue struct {
+	queue.Queue
+	name      label.KeyValue
+	published metric.Int64Counter
+}
+
+// NewQueue returns a Queue counting messages published to q, labeled with name.
+func NewQueue(q queue.Queue, name string, i *instr.Instrumentation) *Queue {
+	return &Queue{
+		Queue:     q,
+		name:      label.String("queue", name),
+		published: newPublished(i),
+	}
+}
+
+// Publish adds a task with specified params to the Queue, counting it when published.
+func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	err := q.Queue.Publish(ctx, params, priority)
+	q.published.Add(ctx, 1, q.name, status(err))
+
+	return err
+}
+
+// Publisher wraps a Publisher, counting published messages.
+type Publisher struct {
+	p         queue.Publisher
+	name      label.KeyValue
+	published metric.Int64Counter
+}
+
+// Publish adds a task with specified params to the queue, counting it when published.
+func (p *Publisher) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	err := p.p.Publish(ctx, params, priority)
+	p.published.Add(ctx, 1, p.name, status(err))
+
+	return err
+}
+
+// PublisherFactory wraps a PublisherFactory, returning publishers counting published messages.
+type PublisherFactory struct {
+	f    queue.PublisherFactory
+	name string
+	*instr.Instrumentation
+}
+
+// NewPublisherFactory returns a PublisherFactory for publishers counting messages published to the queue name.
+func NewPublisherFactory(f queue.PublisherFactory, name string, i *instr.Instrumentation) queue.PublisherFactory {
+	return &PublisherFactory{f, name, i}
+}
+
+// NewPublisher generates a new publisher or returns an error.
+func (f *PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, error) {
+	p, err := f.f.NewPublisher(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Publisher{
+		p:         p,
+		name:      label.String("queue", f.name),
+		published: newPublished(f.Instrumentation),
+	}, nil
+}
+
+// newPublished returns the counter for published messages.
+func newPublished(i *instr.Instrumentation) metric.Int64Counter {
+	return metric.Must(i.Meter).NewInt64Counter(
+		"ipfs_search.queue.published",
+		metric.WithDescription("Messages published by queue and status."),
+	)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ protocol.Protocol      = &Protocol{}
+	_ extractor.Extractor    = &Extractor{}
+	_ index.Index            = &Index{}
+	_ queue.Queue            = &Queue{}
+	_ queue.Publisher        = &Publisher{}
+	_ queue.PublisherFactory = &PublisherFactory{}
+)
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/api/metric/metrictest"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type WrappersTestSuite struct {
+	suite.Suite
+
+	ctx   context.Context
+	meter *metrictest.MeterImpl
+	i     *instr.Instrumentation
+}
+
+func (s *WrappersTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.i = instr.New()
+	s.meter, s.i.Meter = metrictest.NewMeter()
+}
+
+// recorded returns the single measurement recorded by the test, with its labels.
+func (s *WrappersTestSuite) recorded() (string, map[label.Key]label.Value) {
+	s.Require().Len(s.meter.MeasurementBatches, 1)
+
+	b := s.meter.MeasurementBatches[0]
+	s.Require().Len(b.Measurements, 1)
+
+	labels := make(map[label.Key]label.Value, len(b.Labels))
+	for _, l := range b.Labels {
+		labels[l.Key] = l.Value
+	}
+
+	return b.Measurements[0].Instrument.Descriptor().Name(), labels
+}
+
+func (s *WrappersTestSuite) TestIndex() {
+	m := &index.Mock{}
+	testErr := errors.New("test")
+	m.On("Update", mock.Anything, "id", "props").Return(testErr).Once()
+
+	i := NewIndex(m, "files", s.i)
+	s.Equal(testErr, i.Update(s.ctx, "id", "props"))
+
+	name, labels := s.recorded()
+	s.Equal("ipfs_search.index.duration", name)
+	s.Equal("files", labels["index"].AsString())
+	s.Equal("update", labels["operation"].AsString())
+	s.Equal("error", labels["status"].AsString())
+
+	m.AssertExpectations(s.T())
+}
+
+func (s *WrappersTestSuite) TestPublisherFactory() {
+	q := &queue.Mock{}
+	q.On("Publish", mock.Anything, "params", uint8(9)).Return(nil).Once()
+
+	f := &queue.MockFactory{}
+	f.On("NewPublisher", mock.Anything).Return(q, nil).Once()
+
+	p, err := NewPublisherFactory(f, "hashes", s.i).NewPublisher(s.ctx)
+	s.Require().NoError(err)
+	s.NoError(p.Publish(s.ctx, "params", 9))
+
+	name, labels := s.recorded()
+	s.Equal("ipfs_search.queue.published", name)
+	s.Equal("hashes", labels["queue"].AsString())
+	s.Equal("ok", labels["status"].AsString())
+
+	q.AssertExpectations(s.T())
+	f.AssertExpectations(s.T())
+}
+
+func TestWrappersTestSuite(t *testing.T) {
+	suite.Run(t, new(WrappersTestSuite))
+}
@@ -0,0 +1,176 @@
+// Package car implements the Protocol interface for content in CAR (Content Addressable aRchive) files, allowing
+// IPFS content to be crawled without a running IPFS node.
+package car
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	uio "github.com/ipfs/go-unixfs/io"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const ipfsPrefix = "/ipfs/"
+
+// CAR implements the Protocol interface for UnixFS content in CAR files. It is concurrency-safe.
+//
+// Resources are IPFS resources; their blocks are read from the CAR files instead of the network.
+type CAR struct {
+	config *Config
+
+	store      *store
+	gatewayURL *url.URL
+
+	*instr.Instrumentation
+}
+
+// New opens and indexes the configured CAR files and returns a new CAR protocol.
+func New(config *Config, instr *instr.Instrumentation) (*CAR, error) {
+	gatewayURL, err := url.Parse(config.GatewayURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse gateway URL, error: %w", err)
+	}
+
+	if !gatewayURL.IsAbs() {
+		return nil, fmt.Errorf("gateway URL is not absolute: %s", gatewayURL)
+	}
+
+	paths, err := filepath.Glob(config.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no CAR files matching %s", config.Files)
+	}
+
+	s, err := openStore(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Opened %d CAR files with %d blocks, roots: %v", len(paths), len(s.blocks), s.roots)
+
+	return &CAR{
+		config,
+		s,
+		gatewayURL,
+		instr,
+	}, nil
+}
+
+// Close closes the CAR files.
+func (c *CAR) Close() error {
+	return c.store.Close()
+}
+
+// Roots returns the roots declared in the headers of the CAR files.
+func (c *CAR) Roots() []cid.Cid {
+	return c.store.roots
+}
+
+// Ping checks whether the CAR files can still be read, returning an error otherwise.
+func (c *CAR) Ping(ctx context.Context) error {
+	for _, f := range c.store.files {
+		if _, err := f.Stat(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// wrapErr wraps errors for content which is not in the CAR files or cannot be decoded with ErrInvalidResource;
+// unlike on the network, missing blocks will not become available.
+func wrapErr(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+}
+
+// get returns the node for a resource.
+func (c *CAR) get(ctx context.Context, id string) (ipld.Node, error) {
+	cid, err := cid.Decode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.store.Get(ctx, cid)
+}
+
+// Resolve returns the resource for a CID or an IPFS path, e.g. `/ipfs/<cid>/sub/path`, walking directories in the
+// CAR files. Resources within a directory reference their parent directory by the last path segment.
+func (c *CAR) Resolve(ctx context.Context, path string) (*t.AnnotatedResource, error) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, ipfsPrefix), "/"), "/")
+	if segments[0] == "" {
+		return nil, fmt.Errorf("%w: empty path", t.ErrInvalidResource)
+	}
+
+	nd, err := c.get(ctx, segments[0])
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       segments[0],
+		},
+	}
+
+	for _, name := range segments[1:] {
+		dir, err := uio.NewDirectoryFromNode(c.store, nd)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+
+		if nd, err = dir.Find(ctx, name); err != nil {
+			return nil, wrapErr(fmt.Errorf("resolving %s: %w", name, err))
+		}
+
+		r = &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       nd.Cid().String(),
+			},
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   name,
+			},
+		}
+	}
+
+	return r, nil
+}
+
+// GatewayURL returns the URL to request a resource from the built-in server. If a reference is available, it is used
+// to generate the filename to facilitate content type detection, like for IPFS gateways.
+func (c *CAR) GatewayURL(r *t.AnnotatedResource) string {
+	u := *c.gatewayURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + ipfsPrefix
+	u.RawPath = ""
+
+	if ref := r.Reference; ref.Name != "" {
+		u.Path += ref.Parent.ID + "/" + ref.Name
+	} else {
+		u.Path += r.ID
+	}
+
+	return u.String()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ protocol.Protocol = &CAR{}
@@ -0,0 +1,406 @@
+package car
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipfs/go-ipld-format"
+	dag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+	"github.com/ipfs/go-unixfs/hamt"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const shardedEntries = 50
+
+// recordingDAG records the nodes added to it, in order, for writing them to a CAR file.
+type recordingDAG struct {
+	ipld.DAGService
+	nodes []ipld.Node
+}
+
+func (d *recordingDAG) Add(ctx context.Context, nd ipld.Node) error {
+	d.nodes = append(d.nodes, nd)
+	return nil
+}
+
+func (d *recordingDAG) AddMany(ctx context.Context, nds []ipld.Node) error {
+	d.nodes = append(d.nodes, nds...)
+	return nil
+}
+
+// writeCAR writes a CARv1 file with the given roots and nodes.
+func writeCAR(path string, roots []cid.Cid, nodes []ipld.Node) error {
+	h, err := cbor.DumpObject(&header{Roots: roots, Version: 1})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeSection := func(parts ...[]byte) {
+		var l uint64
+		for _, p := range parts {
+			l += uint64(len(p))
+		}
+
+		varint := make([]byte, binary.MaxVarintLen64)
+		buf.Write(varint[:binary.PutUvarint(varint, l)])
+
+		for _, p := range parts {
+			buf.Write(p)
+		}
+	}
+
+	writeSection(h)
+	for _, nd := range nodes {
+		writeSection(nd.Cid().Bytes(), nd.RawData())
+	}
+
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// wrapV2 wraps the CARv1 file at src in a CARv2 file at dst, without index.
+func wrapV2(src, dst string) error {
+	v1, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, v2HeaderSize)
+	dataOffset := uint64(len(v2Pragma) + v2HeaderSize)
+	binary.LittleEndian.PutUint64(header[16:24], dataOffset)
+	binary.LittleEndian.PutUint64(header[24:32], uint64(len(v1)))
+
+	return ioutil.WriteFile(dst, bytes.Join([][]byte{v2Pragma, header, v1}, nil), 0644)
+}
+
+type CARTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	dir string
+
+	root, file, raw, sharded, missing ipld.Node
+
+	car *CAR
+}
+
+// chunkedFile returns a UnixFS file consisting of raw leaves.
+func chunkedFile(chunks ...string) (*dag.ProtoNode, []ipld.Node) {
+	fsNode := unixfs.NewFSNode(unixfs.TFile)
+	nd := new(dag.ProtoNode)
+	leaves := []ipld.Node{}
+
+	for _, chunk := range chunks {
+		leaf := dag.NewRawNode([]byte(chunk))
+		fsNode.AddBlockSize(uint64(len(chunk)))
+
+		if err := nd.AddNodeLink("", leaf); err != nil {
+			panic(err)
+		}
+
+		leaves = append(leaves, leaf)
+	}
+
+	data, err := fsNode.GetBytes()
+	if err != nil {
+		panic(err)
+	}
+	nd.SetData(data)
+
+	return nd, leaves
+}
+
+func (s *CARTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.dir = s.T().TempDir()
+
+	d := &recordingDAG{}
+
+	file, leaves := chunkedFile("hello ", "world")
+	s.file = file
+	s.Require().NoError(d.AddMany(s.ctx, append(leaves, file)))
+
+	s.raw = dag.NewRawNode([]byte("raw"))
+	s.Require().NoError(d.Add(s.ctx, s.raw))
+
+	// Referenced but not included in the CAR.
+	s.missing = dag.NewRawNode([]byte("missing"))
+
+	shard, err := hamt.NewShard(d, 256)
+	s.Require().NoError(err)
+
+	for i := 0; i < shardedEntries; i++ {
+		nd := dag.NewRawNode([]byte(fmt.Sprintf("entry %d", i)))
+		s.Require().NoError(d.Add(s.ctx, nd))
+		s.Require().NoError(shard.Set(s.ctx, fmt.Sprintf("file-%d.txt", i), nd))
+	}
+
+	s.sharded, err = shard.Node()
+	s.Require().NoError(err)
+
+	root := unixfs.EmptyDirNode()
+	s.Require().NoError(root.AddNodeLink("hello.txt", s.file))
+	s.Require().NoError(root.AddNodeLink("raw", s.raw))
+	s.Require().NoError(root.AddNodeLink("sharded", s.sharded))
+	s.Require().NoError(root.AddNodeLink("missing", s.missing))
+	s.root = root
+	s.Require().NoError(d.Add(s.ctx, root))
+
+	s.Require().NoError(writeCAR(filepath.Join(s.dir, "fixture.car"), []cid.Cid{root.Cid()}, d.nodes))
+
+	cfg := DefaultConfig()
+	cfg.Files = filepath.Join(s.dir, "*.car")
+	cfg.GatewayURL = "http://car.local/prefix/"
+
+	s.car, err = New(cfg, instr.New())
+	s.Require().NoError(err)
+}
+
+func (s *CARTestSuite) TearDownTest() {
+	s.car.Close()
+}
+
+func resource(nd ipld.Node) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       nd.Cid().String(),
+		},
+	}
+}
+
+func (s *CARTestSuite) TestRoots() {
+	s.Equal([]cid.Cid{s.root.Cid()}, s.car.Roots())
+}
+
+func (s *CARTestSuite) TestNewNoFiles() {
+	cfg := DefaultConfig()
+	cfg.Files = filepath.Join(s.dir, "*.nothing")
+
+	_, err := New(cfg, instr.New())
+	s.Error(err)
+}
+
+func (s *CARTestSuite) TestNewInvalidFile() {
+	path := filepath.Join(s.dir, "invalid.car")
+	s.Require().NoError(ioutil.WriteFile(path, []byte("not a CAR"), 0644))
+
+	cfg := DefaultConfig()
+	cfg.Files = path
+
+	_, err := New(cfg, instr.New())
+	s.Error(err)
+}
+
+func (s *CARTestSuite) TestReaderHeaderTooLarge() {
+	for _, l := range []uint64{maxHeaderSize + 1, 1 << 62, 1<<64 - 1} {
+		varint := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(varint, l)
+
+		_, err := NewReader(bytes.NewReader(varint[:n]))
+		s.True(errors.Is(err, errHeaderTooLarge), "length %d: %v", l, err)
+	}
+}
+
+func (s *CARTestSuite) TestStatChunkedFile() {
+	r := resource(s.file)
+
+	s.NoError(s.car.Stat(s.ctx, r))
+	s.Equal(t.Stat{Type: t.FileType, Size: 11}, r.Stat)
+}
+
+func (s *CARTestSuite) TestStatRaw() {
+	r := resource(s.raw)
+
+	s.NoError(s.car.Stat(s.ctx, r))
+	s.Equal(t.Stat{Type: t.FileType, Size: 3}, r.Stat)
+}
+
+func (s *CARTestSuite) TestStatDirectory() {
+	for _, nd := range []ipld.Node{s.root, s.sharded} {
+		r := resource(nd)
+
+		s.NoError(s.car.Stat(s.ctx, r))
+		s.Equal(t.DirectoryType, r.Type)
+	}
+}
+
+func (s *CARTestSuite) TestStatCIDv1() {
+	// Blocks are found by multihash, regardless of CID version.
+	c := cid.NewCidV1(cid.DagProtobuf, s.file.Cid().Hash())
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: c.String()},
+	}
+
+	s.NoError(s.car.Stat(s.ctx, r))
+	s.Equal(t.FileType, r.Type)
+}
+
+func (s *CARTestSuite) TestStatNodeInvalid() {
+	_, err := StatNode(dag.NodeWithData([]byte("not unixfs")))
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *CARTestSuite) TestStatMissing() {
+	err := s.car.Stat(s.ctx, resource(s.missing))
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *CARTestSuite) ls(nd ipld.Node) (map[string]*t.AnnotatedResource, error) {
+	out := make(chan *t.AnnotatedResource, 100)
+
+	err := s.car.Ls(s.ctx, resource(nd), out)
+	close(out)
+
+	entries := make(map[string]*t.AnnotatedResource)
+	for e := range out {
+		entries[e.Reference.Name] = e
+	}
+
+	return entries, err
+}
+
+func (s *CARTestSuite) TestLs() {
+	entries, err := s.ls(s.root)
+
+	s.NoError(err)
+	s.Len(entries, 4)
+
+	f := entries["hello.txt"]
+	s.Require().NotNil(f)
+	s.Equal(s.file.Cid().String(), f.ID)
+	s.Equal(t.IPFSProtocol, f.Protocol)
+	s.Equal(s.root.Cid().String(), f.Reference.Parent.ID)
+	s.Equal(t.Stat{Type: t.FileType, Size: 11}, f.Stat)
+
+	s.Equal(t.DirectoryType, entries["sharded"].Type)
+	s.Equal(t.UndefinedType, entries["missing"].Type)
+}
+
+func (s *CARTestSuite) TestLsSharded() {
+	entries, err := s.ls(s.sharded)
+
+	s.NoError(err)
+	s.Len(entries, shardedEntries)
+
+	e := entries["file-7.txt"]
+	s.Require().NotNil(e)
+	s.Equal(t.Stat{Type: t.FileType, Size: 7}, e.Stat)
+}
+
+func (s *CARTestSuite) TestLsFile() {
+	_, err := s.ls(s.file)
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *CARTestSuite) TestResolve() {
+	r, err := s.car.Resolve(s.ctx, fmt.Sprintf("/ipfs/%s/sharded/file-7.txt", s.root.Cid()))
+
+	s.NoError(err)
+	s.Equal(dag.NewRawNode([]byte("entry 7")).Cid().String(), r.ID)
+	s.Equal("file-7.txt", r.Reference.Name)
+	s.Equal(s.sharded.Cid().String(), r.Reference.Parent.ID)
+}
+
+func (s *CARTestSuite) TestResolveCID() {
+	r, err := s.car.Resolve(s.ctx, s.root.Cid().String())
+
+	s.NoError(err)
+	s.Equal(s.root.Cid().String(), r.ID)
+	s.Nil(r.Reference.Parent)
+}
+
+func (s *CARTestSuite) TestResolveNotExist() {
+	_, err := s.car.Resolve(s.ctx, fmt.Sprintf("/ipfs/%s/nothing", s.root.Cid()))
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *CARTestSuite) TestGatewayURL() {
+	r := resource(s.file)
+	s.Equal("http://car.local/prefix/ipfs/"+s.file.Cid().String(), s.car.GatewayURL(r))
+
+	r.Reference = t.Reference{
+		Parent: resource(s.root).Resource,
+		Name:   "my file.txt",
+	}
+	s.Equal("http://car.local/prefix/ipfs/"+s.root.Cid().String()+"/my%20file.txt", s.car.GatewayURL(r))
+}
+
+func (s *CARTestSuite) serve(path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.car.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return w
+}
+
+func (s *CARTestSuite) TestServeFile() {
+	w := s.serve(fmt.Sprintf("/prefix/ipfs/%s/hello.txt", s.root.Cid()))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("hello world", w.Body.String())
+	s.Contains(w.Header().Get("Content-Type"), "text/plain")
+}
+
+func (s *CARTestSuite) TestServeSharded() {
+	w := s.serve(fmt.Sprintf("/prefix/ipfs/%s/file-3.txt", s.sharded.Cid()))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("entry 3", w.Body.String())
+}
+
+func (s *CARTestSuite) TestServeNotFound() {
+	for _, path := range []string{
+		fmt.Sprintf("/prefix/ipfs/%s", s.root.Cid()),         // Directory
+		fmt.Sprintf("/prefix/ipfs/%s/missing", s.root.Cid()), // Not in CAR
+		fmt.Sprintf("/prefix/ipfs/%s/nothing", s.root.Cid()),
+		fmt.Sprintf("/ipfs/%s/hello.txt", s.root.Cid()),
+		"/prefix/ipfs/invalid",
+	} {
+		s.Equal(http.StatusNotFound, s.serve(path).Code, path)
+	}
+}
+
+func (s *CARTestSuite) TestCARv2() {
+	v1 := filepath.Join(s.dir, "fixture.car")
+	v2 := filepath.Join(s.dir, "v2", "fixture.car")
+
+	s.Require().NoError(os.Mkdir(filepath.Dir(v2), 0755))
+	s.Require().NoError(wrapV2(v1, v2))
+
+	cfg := DefaultConfig()
+	cfg.Files = v2
+
+	c, err := New(cfg, instr.New())
+	s.Require().NoError(err)
+	defer c.Close()
+
+	r := resource(s.file)
+	s.NoError(c.Stat(s.ctx, r))
+	s.Equal(uint64(11), r.Size)
+}
+
+func (s *CARTestSuite) TestPing() {
+	s.NoError(s.car.Ping(s.ctx))
+
+	s.Require().NoError(s.car.Close())
+	s.Error(s.car.Ping(s.ctx))
+}
+
+func TestCARTestSuite(t *testing.T) {
+	suite.Run(t, new(CARTestSuite))
+}
@@ -0,0 +1,17 @@
+package car
+
+// Config specifies the configuration for the CAR protocol.
+type Config struct {
+	Files         string // Glob pattern matching the CAR files to crawl, e.g. `/data/*.car`.
+	ServerAddress string // Address to serve file contents on, for metadata extraction.
+	GatewayURL    string // URL under which the served file contents are reachable for the extractor.
+}
+
+// DefaultConfig returns the default configuration for the CAR protocol.
+func DefaultConfig() *Config {
+	return &Config{
+		Files:         "*.car",
+		ServerAddress: "localhost:8091",
+		GatewayURL:    "http://localhost:8091",
+	}
+}
@@ -0,0 +1,83 @@
+package car
+
+import (
+	"context"
+
+	ipld "github.com/ipfs/go-ipld-format"
+	uio "github.com/ipfs/go-unixfs/io"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// linkStat returns the type and size of a directory entry. As blocks are local, types are resolved, except for
+// entries whose blocks are not in the CAR files.
+func (c *CAR) linkStat(ctx context.Context, link *ipld.Link) t.Stat {
+	nd, err := c.store.Get(ctx, link.Cid)
+	if err != nil {
+		return t.Stat{
+			Type: t.UndefinedType,
+			Size: link.Size,
+		}
+	}
+
+	stat, err := StatNode(nd)
+	if err != nil {
+		return t.Stat{Type: t.UnsupportedType}
+	}
+
+	return stat
+}
+
+// Ls streams the entries of a (HAMT sharded) UnixFS directory with Type, Size and Reference populated.
+func (c *CAR) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	ctx, span := c.Tracer.Start(ctx, "protocol.car.Ls", trace.WithAttributes(label.String("cid", r.ID)))
+	defer span.End()
+
+	nd, err := c.get(ctx, r.ID)
+	if err != nil {
+		err = wrapErr(err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	dir, err := uio.NewDirectoryFromNode(c.store, nd)
+	if err != nil {
+		err = wrapErr(err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for result := range dir.EnumLinksAsync(ctx) {
+		if result.Err != nil {
+			err := wrapErr(result.Err)
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
+
+		refR := &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       result.Link.Cid.String(),
+			},
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   result.Link.Name,
+			},
+			Stat: c.linkStat(ctx, result.Link),
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- refR:
+		}
+	}
+
+	return ctx.Err()
+}
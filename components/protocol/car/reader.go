@@ -0,0 +1,126 @@
+package car
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+)
+
+// maxHeaderSize limits the size of headers read, as their length comes from untrusted data. Headers consist of a
+// version and the roots, of which there are few; this leaves room for tens of thousands.
+const maxHeaderSize = 1 << 20
+
+var (
+	errUnsupportedVersion = errors.New("unsupported CAR version")
+	errHeaderTooLarge     = errors.New("CAR header too large")
+	errSectionTooLarge    = errors.New("CAR section too large")
+)
+
+// header is the header of CARv1 data.
+type header struct {
+	Roots   []cid.Cid
+	Version uint64
+}
+
+func init() {
+	cbor.RegisterCborType(header{})
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	*bufio.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReader) ReadByte() (byte, error) {
+	b, err := r.Reader.ReadByte()
+	if err == nil {
+		r.n++
+	}
+	return b, err
+}
+
+// Reader reads the blocks in a stream of CARv1 data, e.g. a gateway response. Blocks are not verified against
+// their CIDs.
+type Reader struct {
+	r *countingReader
+
+	// Roots are the roots declared in the header.
+	Roots []cid.Cid
+
+	// MaxSectionSize, when non-zero, limits the size of sections (CID and block) read, protecting against
+	// allocating arbitrary amounts of memory for untrusted data.
+	MaxSectionSize uint64
+}
+
+// NewReader reads the header from r, returning a Reader for the blocks following it. Headers larger than
+// maxHeaderSize are refused.
+func NewReader(r io.Reader) (*Reader, error) {
+	cr := &countingReader{Reader: bufio.NewReader(r)}
+
+	l, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return nil, fmt.Errorf("reading header length: %w", err)
+	}
+
+	if l > maxHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes", errHeaderTooLarge, l)
+	}
+
+	buf := make([]byte, l)
+	if _, err := io.ReadFull(cr, buf); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	h := new(header)
+	if err := cbor.DecodeInto(buf, h); err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+
+	if h.Version != 1 {
+		return nil, fmt.Errorf("%w: %d", errUnsupportedVersion, h.Version)
+	}
+
+	return &Reader{r: cr, Roots: h.Roots}, nil
+}
+
+// Next returns the CID and data of the next block along with the offset of its data relative to the start of the
+// stream, or io.EOF after the last block.
+func (r *Reader) Next() (cid.Cid, []byte, int64, error) {
+	l, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return cid.Undef, nil, 0, io.EOF
+	}
+	if err != nil {
+		return cid.Undef, nil, 0, fmt.Errorf("reading section length: %w", err)
+	}
+
+	if r.MaxSectionSize != 0 && l > r.MaxSectionSize {
+		return cid.Undef, nil, 0, fmt.Errorf("%w: %d bytes", errSectionTooLarge, l)
+	}
+
+	start := r.r.n
+
+	buf := make([]byte, l)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return cid.Undef, nil, 0, fmt.Errorf("reading section: %w", err)
+	}
+
+	n, c, err := cid.CidFromBytes(buf)
+	if err != nil {
+		return cid.Undef, nil, 0, fmt.Errorf("reading CID: %w", err)
+	}
+
+	return c, buf[n:], start + int64(n), nil
+}
@@ -0,0 +1,72 @@
+package car
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	uio "github.com/ipfs/go-unixfs/io"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// ServeHTTP serves the contents of UnixFS files in the CAR files on `/ipfs/<cid>[/path]` below the path of the gateway
+// URL, such that the extractor can fetch them from GatewayURL. Directories and content not in the CAR files are not
+// found.
+func (c *CAR) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prefix := strings.TrimSuffix(c.gatewayURL.Path, "/") + ipfsPrefix
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+
+	resource, err := c.Resolve(ctx, strings.TrimPrefix(r.URL.Path, prefix))
+	if err != nil {
+		if errors.Is(err, t.ErrInvalidResource) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	nd, err := c.get(ctx, resource.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if stat, err := StatNode(nd); err != nil || stat.Type != t.FileType {
+		http.NotFound(w, r)
+		return
+	}
+
+	reader, err := uio.NewDagReader(ctx, nd, c.store)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer reader.Close()
+
+	// Content is immutable; the name is used to detect the content type.
+	w.Header().Set("Cache-Control", "public, max-age=29030400, immutable")
+	w.Header().Set("Etag", `"`+resource.ID+`"`)
+
+	http.ServeContent(w, r, resource.Reference.Name, time.Time{}, reader)
+}
+
+// Serve serves file contents on the configured address until ctx is done.
+func (c *CAR) Serve(ctx context.Context) error {
+	return utils.Serve(ctx, c.config.ServerAddress, c, "CAR contents")
+}
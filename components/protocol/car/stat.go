@@ -0,0 +1,34 @@
+package car
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Stat populates Type and Size of an AnnotatedResource from its block in the CAR files.
+func (c *CAR) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := c.Tracer.Start(ctx, "protocol.car.Stat", trace.WithAttributes(label.String("cid", r.ID)))
+	defer span.End()
+
+	nd, err := c.get(ctx, r.ID)
+	if err != nil {
+		err = wrapErr(err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	stat, err := StatNode(nd)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	r.Stat = stat
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package car
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+
+	// Register decoders for dag-pb and raw blocks.
+	_ "github.com/ipfs/go-merkledag"
+)
+
+// ErrReadOnly is returned when attempting to modify the blocks in CAR files.
+var ErrReadOnly = errors.New("CAR files are read-only")
+
+// v2Pragma starts CARv2 files; it is a CARv1 header declaring version 2.
+var v2Pragma = []byte{0x0a, 0xa1, 0x67, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x02}
+
+// v2HeaderSize is the size of the CARv2 header following the pragma.
+const v2HeaderSize = 40
+
+// location is the location of a block within a CAR file.
+type location struct {
+	cid    cid.Cid // CID as found in the CAR, determining how the block is decoded.
+	file   *os.File
+	offset int64
+	length int
+}
+
+// store provides read-only access to the blocks in a set of CAR files, indexed when opened. It implements
+// ipld.DAGService, so that UnixFS files and directories can be read from it. It is concurrency-safe.
+type store struct {
+	files  []*os.File
+	roots  []cid.Cid
+	blocks map[string]location // Blocks by multihash, so that both CIDv0 and CIDv1 can be used.
+}
+
+// dataSection returns the offset and size of the CARv1 data in f, unwrapping CARv2 files.
+func dataSection(f *os.File) (int64, int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pragma := make([]byte, len(v2Pragma))
+	if _, err := f.ReadAt(pragma, 0); err != nil && err != io.EOF {
+		return 0, 0, err
+	}
+
+	if !bytes.Equal(pragma, v2Pragma) {
+		return 0, info.Size(), nil
+	}
+
+	// CARv2: 16 bytes of characteristics, followed by the offset and size of the CARv1 data.
+	v2Header := make([]byte, v2HeaderSize)
+	if _, err := f.ReadAt(v2Header, int64(len(v2Pragma))); err != nil {
+		return 0, 0, err
+	}
+
+	offset := binary.LittleEndian.Uint64(v2Header[16:24])
+	size := binary.LittleEndian.Uint64(v2Header[24:32])
+
+	return int64(offset), int64(size), nil
+}
+
+// index reads the roots and the locations of the blocks in a CAR file.
+func (s *store) index(f *os.File) error {
+	offset, size, err := dataSection(f)
+	if err != nil {
+		return err
+	}
+
+	r, err := NewReader(io.NewSectionReader(f, offset, size))
+	if err != nil {
+		return err
+	}
+
+	s.roots = append(s.roots, r.Roots...)
+
+	for {
+		c, data, dataOffset, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		s.blocks[string(c.Hash())] = location{
+			cid:    c,
+			file:   f,
+			offset: offset + dataOffset,
+			length: len(data),
+		}
+	}
+}
+
+// openStore opens and indexes CAR files.
+func openStore(paths []string) (*store, error) {
+	s := &store{
+		blocks: make(map[string]location),
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+
+		s.files = append(s.files, f)
+
+		if err := s.index(f); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("indexing %s: %w", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Close closes the CAR files.
+func (s *store) Close() error {
+	var firstErr error
+
+	for _, f := range s.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Get returns the node for a CID, or ipld.ErrNotFound when its block is not in any of the CAR files.
+func (s *store) Get(ctx context.Context, c cid.Cid) (ipld.Node, error) {
+	loc, ok := s.blocks[string(c.Hash())]
+	if !ok {
+		return nil, ipld.ErrNotFound
+	}
+
+	data := make([]byte, loc.length)
+	if _, err := loc.file.ReadAt(data, loc.offset); err != nil {
+		return nil, err
+	}
+
+	b, err := blocks.NewBlockWithCid(data, loc.cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return ipld.Decode(b)
+}
+
+// GetMany returns the nodes for CIDs, in order.
+func (s *store) GetMany(ctx context.Context, cids []cid.Cid) <-chan *ipld.NodeOption {
+	out := make(chan *ipld.NodeOption, len(cids))
+
+	go func() {
+		defer close(out)
+
+		for _, c := range cids {
+			nd, err := s.Get(ctx, c)
+
+			select {
+			case out <- &ipld.NodeOption{Node: nd, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Add is not supported.
+func (s *store) Add(context.Context, ipld.Node) error {
+	return ErrReadOnly
+}
+
+// AddMany is not supported.
+func (s *store) AddMany(context.Context, []ipld.Node) error {
+	return ErrReadOnly
+}
+
+// Remove is not supported.
+func (s *store) Remove(context.Context, cid.Cid) error {
+	return ErrReadOnly
+}
+
+// RemoveMany is not supported.
+func (s *store) RemoveMany(context.Context, []cid.Cid) error {
+	return ErrReadOnly
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ ipld.DAGService = &store{}
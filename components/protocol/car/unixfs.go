@@ -0,0 +1,54 @@
+package car
+
+import (
+	"fmt"
+
+	ipld "github.com/ipfs/go-ipld-format"
+	dag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// StatNode returns the type and size of a UnixFS node, like the `files/stat` API of IPFS: the file size for files
+// and the cumulative size for directories. Nodes which are not valid UnixFS result in an invalid resource error.
+func StatNode(nd ipld.Node) (t.Stat, error) {
+	switch nd := nd.(type) {
+	case *dag.RawNode:
+		return t.Stat{
+			Type: t.FileType,
+			Size: uint64(len(nd.RawData())),
+		}, nil
+
+	case *dag.ProtoNode:
+		fsNode, err := unixfs.FSNodeFromBytes(nd.Data())
+		if err != nil {
+			return t.Stat{}, t.Invalid(err)
+		}
+
+		switch fsNode.Type() {
+		case unixfs.TFile, unixfs.TRaw:
+			return t.Stat{
+				Type: t.FileType,
+				Size: fsNode.FileSize(),
+			}, nil
+
+		case unixfs.TDirectory, unixfs.THAMTShard:
+			size, err := nd.Size()
+			if err != nil {
+				return t.Stat{}, err
+			}
+
+			return t.Stat{
+				Type: t.DirectoryType,
+				Size: size,
+			}, nil
+
+		default:
+			return t.Stat{Type: t.UnsupportedType}, nil
+		}
+
+	default:
+		return t.Stat{}, t.Invalid(fmt.Errorf("not unixfs node (proto or raw): %s", nd.Cid()))
+	}
+}
@@ -0,0 +1,17 @@
+package filesystem
+
+// Config specifies the configuration for the filesystem protocol.
+type Config struct {
+	Root          string // Directory containing the resources; resource IDs are absolute paths within it.
+	ServerAddress string // Address to serve file contents on, for metadata extraction.
+	GatewayURL    string // URL under which the served file contents are reachable for the extractor.
+}
+
+// DefaultConfig returns the default configuration for the filesystem protocol.
+func DefaultConfig() *Config {
+	return &Config{
+		Root:          ".",
+		ServerAddress: "localhost:8090",
+		GatewayURL:    "http://localhost:8090",
+	}
+}
@@ -0,0 +1,173 @@
+// Package filesystem implements the Protocol interface for a directory on a local or mounted filesystem, allowing
+// datasets to be indexed through the same pipeline as IPFS content.
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Filesystem implements the Protocol interface for a directory on the local filesystem. It is concurrency-safe.
+//
+// Resource IDs are slash-separated absolute paths within the root directory, the root itself being `/`.
+type Filesystem struct {
+	config *Config
+
+	root       string
+	gatewayURL *url.URL
+
+	*instr.Instrumentation
+}
+
+// New returns a new filesystem protocol.
+func New(config *Config, instr *instr.Instrumentation) *Filesystem {
+	root, err := filepath.Abs(config.Root)
+	if err != nil {
+		panic(fmt.Sprintf("could not resolve root directory, error: %v", err))
+	}
+
+	// Resolve symbolic links, so that paths served can be verified to be within the root.
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	gatewayURL, err := url.Parse(config.GatewayURL)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse gateway URL, error: %v", err))
+	}
+
+	if !gatewayURL.IsAbs() {
+		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
+	}
+
+	return &Filesystem{
+		config,
+		root,
+		gatewayURL,
+		instr,
+	}
+}
+
+// cleanID returns the canonical ID for a path within the root.
+func cleanID(p string) string {
+	return path.Clean("/" + p)
+}
+
+// localPath returns the path on the local filesystem for a resource ID.
+func (f *Filesystem) localPath(id string) string {
+	return filepath.Join(f.root, filepath.FromSlash(cleanID(id)))
+}
+
+// typeFromMode returns the resource type for a file mode; anything but regular files and directories,
+// including symbolic links, is unsupported.
+func typeFromMode(mode os.FileMode) t.ResourceType {
+	switch {
+	case mode.IsRegular():
+		return t.FileType
+	case mode.IsDir():
+		return t.DirectoryType
+	default:
+		return t.UnsupportedType
+	}
+}
+
+// statFromInfo returns the stat for file info.
+func statFromInfo(info os.FileInfo) t.Stat {
+	rType := typeFromMode(info.Mode())
+
+	var size uint64
+	if rType == t.FileType {
+		size = uint64(info.Size())
+	}
+
+	return t.Stat{
+		Type: rType,
+		Size: size,
+	}
+}
+
+// wrapErr wraps errors for paths which do not exist, cannot be read or are not of the expected type with
+// ErrInvalidResource.
+func wrapErr(err error) error {
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.ENOTDIR) {
+		return fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	return err
+}
+
+// child returns the resource for an entry within a directory.
+func child(parent *t.Resource, name string) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.FilesystemProtocol,
+			ID:       path.Join(cleanID(parent.ID), name),
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   name,
+		},
+	}
+}
+
+// Resolve returns the resource for a path within the root, referencing its parent directory by name.
+func (f *Filesystem) Resolve(ctx context.Context, p string) (*t.AnnotatedResource, error) {
+	id := cleanID(p)
+
+	if _, err := os.Lstat(f.localPath(id)); err != nil {
+		return nil, wrapErr(err)
+	}
+
+	if id == "/" {
+		return &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.FilesystemProtocol,
+				ID:       id,
+			},
+		}, nil
+	}
+
+	parent := &t.Resource{
+		Protocol: t.FilesystemProtocol,
+		ID:       path.Dir(id),
+	}
+
+	return child(parent, path.Base(id)), nil
+}
+
+// Ping checks whether the root directory is available, returning an error otherwise.
+func (f *Filesystem) Ping(ctx context.Context) error {
+	info, err := os.Stat(f.root)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("root %s is not a directory", f.root)
+	}
+
+	return nil
+}
+
+// GatewayURL returns the URL to request a resource from the built-in file server.
+func (f *Filesystem) GatewayURL(r *t.AnnotatedResource) string {
+	u := *f.gatewayURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + cleanID(r.ID)
+
+	return u.String()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ protocol.Protocol = &Filesystem{}
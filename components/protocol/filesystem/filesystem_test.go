@@ -0,0 +1,184 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type FilesystemTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	root string
+	fs   *Filesystem
+}
+
+func (s *FilesystemTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.root = s.T().TempDir()
+
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.root, "docs", "sub"), 0755))
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.root, "docs", "my file.txt"), []byte("hello"), 0644))
+	s.Require().NoError(os.Symlink("/etc/passwd", filepath.Join(s.root, "docs", "link")))
+
+	cfg := DefaultConfig()
+	cfg.Root = s.root
+	cfg.GatewayURL = "http://files.local/prefix/"
+
+	s.fs = New(cfg, instr.New())
+}
+
+func resource(id string) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.FilesystemProtocol,
+			ID:       id,
+		},
+	}
+}
+
+func (s *FilesystemTestSuite) TestStatFile() {
+	r := resource("/docs/my file.txt")
+
+	s.NoError(s.fs.Stat(s.ctx, r))
+	s.Equal(t.Stat{Type: t.FileType, Size: 5}, r.Stat)
+}
+
+func (s *FilesystemTestSuite) TestStatDirectory() {
+	r := resource("/docs")
+
+	s.NoError(s.fs.Stat(s.ctx, r))
+	s.Equal(t.DirectoryType, r.Type)
+}
+
+func (s *FilesystemTestSuite) TestStatSymlink() {
+	r := resource("/docs/link")
+
+	s.NoError(s.fs.Stat(s.ctx, r))
+	s.Equal(t.UnsupportedType, r.Type)
+}
+
+func (s *FilesystemTestSuite) TestStatNotExist() {
+	err := s.fs.Stat(s.ctx, resource("/missing"))
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *FilesystemTestSuite) TestStatOutsideRoot() {
+	r := resource("/../../../etc")
+
+	// Paths are confined to the root.
+	err := s.fs.Stat(s.ctx, r)
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *FilesystemTestSuite) TestLs() {
+	parent := resource("/docs")
+	out := make(chan *t.AnnotatedResource, 10)
+
+	s.NoError(s.fs.Ls(s.ctx, parent, out))
+	close(out)
+
+	entries := make(map[string]*t.AnnotatedResource)
+	for e := range out {
+		entries[e.ID] = e
+	}
+
+	s.Len(entries, 3)
+
+	f := entries["/docs/my file.txt"]
+	s.Require().NotNil(f)
+	s.Equal(t.FilesystemProtocol, f.Protocol)
+	s.Equal(t.Stat{Type: t.FileType, Size: 5}, f.Stat)
+	s.Equal(t.Reference{Parent: parent.Resource, Name: "my file.txt"}, f.Reference)
+
+	s.Equal(t.DirectoryType, entries["/docs/sub"].Type)
+	s.Equal(t.UnsupportedType, entries["/docs/link"].Type)
+}
+
+func (s *FilesystemTestSuite) TestLsFile() {
+	out := make(chan *t.AnnotatedResource, 10)
+
+	err := s.fs.Ls(s.ctx, resource("/docs/my file.txt"), out)
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *FilesystemTestSuite) TestResolve() {
+	r, err := s.fs.Resolve(s.ctx, "docs/sub/")
+
+	s.NoError(err)
+	s.Equal("/docs/sub", r.ID)
+	s.Equal("/docs", r.Reference.Parent.ID)
+	s.Equal("sub", r.Reference.Name)
+}
+
+func (s *FilesystemTestSuite) TestResolveRoot() {
+	r, err := s.fs.Resolve(s.ctx, "/")
+
+	s.NoError(err)
+	s.Equal("/", r.ID)
+	s.Nil(r.Reference.Parent)
+}
+
+func (s *FilesystemTestSuite) TestResolveNotExist() {
+	_, err := s.fs.Resolve(s.ctx, "/missing")
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *FilesystemTestSuite) TestGatewayURL() {
+	s.Equal("http://files.local/prefix/docs/my%20file.txt", s.fs.GatewayURL(resource("/docs/my file.txt")))
+}
+
+func (s *FilesystemTestSuite) serve(path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.fs.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return w
+}
+
+func (s *FilesystemTestSuite) TestServeFile() {
+	w := s.serve("/prefix/docs/my%20file.txt")
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("hello", w.Body.String())
+}
+
+func (s *FilesystemTestSuite) TestServeNotFound() {
+	for _, path := range []string{
+		"/prefix/missing",
+		"/prefix/docs",      // Directory
+		"/prefix/docs/link", // Symbolic link
+		"/docs/my%20file.txt",
+	} {
+		s.Equal(http.StatusNotFound, s.serve(path).Code, path)
+	}
+}
+
+func (s *FilesystemTestSuite) TestServeSymlinkedDirectory() {
+	outside := s.T().TempDir()
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0644))
+	s.Require().NoError(os.Symlink(outside, filepath.Join(s.root, "outside")))
+
+	s.Equal(http.StatusNotFound, s.serve("/prefix/outside/secret").Code)
+}
+
+func (s *FilesystemTestSuite) TestPing() {
+	s.NoError(s.fs.Ping(s.ctx))
+
+	s.Require().NoError(os.RemoveAll(s.root))
+	s.Error(s.fs.Ping(s.ctx))
+}
+
+func TestFilesystemTestSuite(t *testing.T) {
+	suite.Run(t, new(FilesystemTestSuite))
+}
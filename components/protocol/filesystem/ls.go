@@ -0,0 +1,67 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// lsBatchSize is the number of directory entries read at a time, so that large directories are streamed.
+const lsBatchSize = 100
+
+// Ls streams the entries of a directory with Type, Size and Reference populated.
+func (f *Filesystem) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	ctx, span := f.Tracer.Start(ctx, "protocol.filesystem.Ls", trace.WithAttributes(label.String("path", r.ID)))
+	defer span.End()
+
+	dir, err := os.Open(f.localPath(r.ID))
+	if err != nil {
+		err = wrapErr(err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+	defer dir.Close()
+
+	for {
+		entries, err := dir.ReadDir(lsBatchSize)
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					// Removed since listing.
+					continue
+				}
+
+				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+				return err
+			}
+
+			c := child(r.Resource, entry.Name())
+			c.Stat = statFromInfo(info)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- c:
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			err = wrapErr(err)
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package filesystem
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// ServeHTTP serves the contents of regular files within the root, such that the extractor can fetch them from
+// GatewayURL. Directories, symbolic links and paths resolving outside of the root are not found.
+func (f *Filesystem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gatewayPath := strings.TrimSuffix(f.gatewayURL.Path, "/")
+	if !strings.HasPrefix(r.URL.Path, gatewayPath+"/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	p := f.localPath(strings.TrimPrefix(r.URL.Path, gatewayPath))
+
+	// Refuse paths escaping the root through symbolic links in parent directories.
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil || resolved != p && !strings.HasPrefix(resolved, f.root+string(filepath.Separator)) {
+		http.NotFound(w, r)
+		return
+	}
+
+	info, err := os.Lstat(p)
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := os.Open(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// Serve serves file contents on the configured address until ctx is done.
+func (f *Filesystem) Serve(ctx context.Context) error {
+	return utils.Serve(ctx, f.config.ServerAddress, f, "files in "+f.root)
+}
@@ -0,0 +1,29 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Stat populates Type and Size of an AnnotatedResource. Symbolic links are not followed and are of unsupported type.
+func (f *Filesystem) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := f.Tracer.Start(ctx, "protocol.filesystem.Stat", trace.WithAttributes(label.String("path", r.ID)))
+	defer span.End()
+
+	info, err := os.Lstat(f.localPath(r.ID))
+	if err != nil {
+		err = wrapErr(err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	r.Stat = statFromInfo(info)
+
+	return nil
+}
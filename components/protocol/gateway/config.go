@@ -0,0 +1,23 @@
+package gateway
+
+import (
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the trustless gateway protocol.
+type Config struct {
+	TrustlessGatewayURL string            // URL of a trustless gateway, to fetch verifiable blocks and CARs from (for Ls and Stat calls).
+	GatewayURL          string            // URL of an IPFS Gateway (to request content).
+	PartialSize         datasize.ByteSize // Filesize of items which are being considered partials (chunks).
+	MaxBlockSize        datasize.ByteSize // Maximum size of blocks accepted from the gateway.
+}
+
+// DefaultConfig returns the default configuration for the trustless gateway protocol.
+func DefaultConfig() *Config {
+	return &Config{
+		TrustlessGatewayURL: "https://trustless-gateway.link",
+		GatewayURL:          "http://localhost:8080",
+		PartialSize:         262144, // Default chunker block size, see ipfs.DefaultConfig.
+		MaxBlockSize:        2 * datasize.MB,
+	}
+}
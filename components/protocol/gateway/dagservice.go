@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+
+	// Register decoders for dag-pb and raw blocks.
+	_ "github.com/ipfs/go-merkledag"
+)
+
+var errReadOnly = errors.New("gateway blocks are read-only")
+
+// dagService provides read-only access to verified blocks from the gateway, such that UnixFS files and directories
+// can be decoded locally. Blocks are first looked up in preloaded blocks, e.g. from a CAR response.
+type dagService struct {
+	g         *Gateway
+	preloaded map[string]blocks.Block // Blocks by multihash; read-only, hence concurrency-safe.
+}
+
+// Get returns the node for a CID.
+func (d *dagService) Get(ctx context.Context, c cid.Cid) (ipld.Node, error) {
+	var (
+		b   blocks.Block
+		err error
+	)
+
+	if preloaded, ok := d.preloaded[string(c.Hash())]; ok {
+		// Decode with the codec of the requested CID.
+		b, err = blocks.NewBlockWithCid(preloaded.RawData(), c)
+	} else {
+		b, err = d.g.getBlock(ctx, c)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ipld.Decode(b)
+}
+
+// GetMany returns the nodes for CIDs, in order.
+func (d *dagService) GetMany(ctx context.Context, cids []cid.Cid) <-chan *ipld.NodeOption {
+	out := make(chan *ipld.NodeOption, len(cids))
+
+	go func() {
+		defer close(out)
+
+		for _, c := range cids {
+			nd, err := d.Get(ctx, c)
+
+			select {
+			case out <- &ipld.NodeOption{Node: nd, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Add is not supported.
+func (d *dagService) Add(context.Context, ipld.Node) error {
+	return errReadOnly
+}
+
+// AddMany is not supported.
+func (d *dagService) AddMany(context.Context, []ipld.Node) error {
+	return errReadOnly
+}
+
+// Remove is not supported.
+func (d *dagService) Remove(context.Context, cid.Cid) error {
+	return errReadOnly
+}
+
+// RemoveMany is not supported.
+func (d *dagService) RemoveMany(context.Context, []cid.Cid) error {
+	return errReadOnly
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ ipld.DAGService = &dagService{}
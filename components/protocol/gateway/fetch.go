@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol/car"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Response formats of trustless gateways.
+const (
+	rawFormat = "raw"
+	carFormat = "car"
+
+	rawContentType = "application/vnd.ipld.raw"
+	carContentType = "application/vnd.ipld.car"
+)
+
+var (
+	errUnexpectedStatus      = errors.New("unexpected status")
+	errUnexpectedContentType = errors.New("unexpected content type")
+	errHashMismatch          = errors.New("block does not match CID")
+	errBlockTooLarge         = errors.New("block too large")
+)
+
+// classifyStatus classifies errors from unexpected HTTP status codes. Overload and bad gateways imply the gateway
+// is unavailable, while content not (yet) being found or retrieved is transient, like timeouts on IPFS.
+func classifyStatus(status int, err error) error {
+	switch {
+	case status == http.StatusBadRequest:
+		return t.Invalid(err)
+	case status == http.StatusBadGateway, status == http.StatusServiceUnavailable,
+		status == http.StatusTooManyRequests:
+		return t.BackendUnavailable(err)
+	case status == http.StatusNotFound, status == http.StatusGone, status >= 500:
+		return t.Transient(err)
+	default:
+		return t.Permanent(err)
+	}
+}
+
+// get requests a CID in a trustless format from the gateway, returning the response when successful.
+func (g *Gateway) get(ctx context.Context, c cid.Cid, format string, contentType string) (*http.Response, error) {
+	u := *g.trustlessURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + ipfsPrefix + c.String()
+	u.RawPath = ""
+
+	q := u.Query()
+	q.Set("format", format)
+	if format == carFormat {
+		// Only the blocks of the requested entity, e.g. all shards of a directory but not its entries.
+		q.Set("dag-scope", "entity")
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", contentType)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, classifyStatus(resp.StatusCode, fmt.Errorf("%w %s for %s", errUnexpectedStatus, resp.Status, c))
+	}
+
+	// A gateway not supporting the trustless format might return deserialized content instead.
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != contentType {
+		resp.Body.Close()
+		err := fmt.Errorf("%w '%s' for %s", errUnexpectedContentType, mediaType, c)
+		return nil, t.BackendUnavailable(err)
+	}
+
+	return resp, nil
+}
+
+// verify returns a block for data, when it matches the CID.
+func verify(c cid.Cid, data []byte) (blocks.Block, error) {
+	sum, err := c.Prefix().Sum(data)
+	if err != nil {
+		// Unsupported hash function.
+		return nil, t.Invalid(err)
+	}
+
+	if !bytes.Equal(sum.Hash(), c.Hash()) {
+		return nil, fmt.Errorf("%w: %s", errHashMismatch, c)
+	}
+
+	return blocks.NewBlockWithCid(data, c)
+}
+
+// getBlock fetches a single raw block from the gateway, verifying it against its CID.
+func (g *Gateway) getBlock(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	resp, err := g.get(ctx, c, rawFormat, rawContentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(g.config.MaxBlockSize)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > int(g.config.MaxBlockSize) {
+		return nil, t.Invalid(fmt.Errorf("%w: %s", errBlockTooLarge, c))
+	}
+
+	return verify(c, data)
+}
+
+// getCAR fetches the blocks of an entity as a CAR from the gateway, verifying each against its CID. Blocks are
+// returned by multihash.
+func (g *Gateway) getCAR(ctx context.Context, c cid.Cid) (map[string]blocks.Block, error) {
+	resp, err := g.get(ctx, c, carFormat, carContentType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	r, err := car.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sections consist of a CID, which is small, and a block.
+	r.MaxSectionSize = uint64(g.config.MaxBlockSize) + 1024
+
+	result := make(map[string]blocks.Block)
+
+	for {
+		blockCid, data, _, err := r.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := verify(blockCid, data)
+		if err != nil {
+			return nil, err
+		}
+
+		result[string(blockCid.Hash())] = b
+	}
+}
@@ -0,0 +1,59 @@
+// Package gateway implements the Protocol interface for a trustless IPFS gateway, fetching verifiable blocks and
+// CARs rather than relying on the (privileged) RPC API of an IPFS node. Blocks are verified against their CIDs
+// and UnixFS is decoded locally.
+//
+// Ref: https://specs.ipfs.tech/http-gateways/trustless-gateway/
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// Gateway implements the Protocol interface for a trustless gateway. It is concurrency-safe.
+type Gateway struct {
+	config *Config
+
+	trustlessURL *url.URL
+	gatewayURL   *url.URL
+	client       *http.Client
+
+	*instr.Instrumentation
+}
+
+// New returns a new trustless gateway protocol.
+func New(config *Config, client *http.Client, instr *instr.Instrumentation) *Gateway {
+	trustlessURL, err := url.Parse(config.TrustlessGatewayURL)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse trustless gateway URL, error: %v", err))
+	}
+
+	if !trustlessURL.IsAbs() {
+		panic(fmt.Sprintf("trustless gateway URL is not absolute: %s", trustlessURL))
+	}
+
+	gatewayURL, err := url.Parse(config.GatewayURL)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse IPFS Gateway URL, error: %v", err))
+	}
+
+	if !gatewayURL.IsAbs() {
+		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
+	}
+
+	return &Gateway{
+		config,
+		trustlessURL,
+		gatewayURL,
+		client,
+		instr,
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ protocol.Protocol = &Gateway{}
@@ -0,0 +1,428 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipfs/go-ipld-format"
+	dag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+	"github.com/ipfs/go-unixfs/hamt"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const shardedEntries = 50
+
+// carHeader is the header of CARv1 data.
+type carHeader struct {
+	Roots   []cid.Cid
+	Version uint64
+}
+
+func init() {
+	cbor.RegisterCborType(carHeader{})
+}
+
+// fixtureGateway is a stand-in trustless gateway, serving fixture blocks. Blocks are added to it as a DAGService.
+type fixtureGateway struct {
+	ipld.DAGService
+
+	blocks   map[string][]byte // Block data by CID.
+	requests int32
+
+	omitShards  bool   // Serve directories without their HAMT shards in CAR responses.
+	carBody     []byte // Served instead of CAR responses when set.
+	contentType string
+}
+
+func (g *fixtureGateway) Add(ctx context.Context, nd ipld.Node) error {
+	g.blocks[nd.Cid().String()] = nd.RawData()
+	return nil
+}
+
+func (g *fixtureGateway) AddMany(ctx context.Context, nds []ipld.Node) error {
+	for _, nd := range nds {
+		g.Add(ctx, nd)
+	}
+	return nil
+}
+
+// writeSection writes a CAR section with the given parts.
+func writeSection(w *bytes.Buffer, parts ...[]byte) {
+	var l uint64
+	for _, p := range parts {
+		l += uint64(len(p))
+	}
+
+	varint := make([]byte, binary.MaxVarintLen64)
+	w.Write(varint[:binary.PutUvarint(varint, l)])
+
+	for _, p := range parts {
+		w.Write(p)
+	}
+}
+
+// entity returns the CIDs of the blocks for an entity: the block itself and, for HAMT sharded directories, all of
+// its shards.
+func (g *fixtureGateway) entity(c cid.Cid) []cid.Cid {
+	cids := []cid.Cid{c}
+
+	if c.Type() != cid.DagProtobuf || g.omitShards {
+		return cids
+	}
+
+	nd, err := dag.DecodeProtobuf(g.blocks[c.String()])
+	if err != nil {
+		panic(err)
+	}
+
+	if fsNode, err := unixfs.FSNodeFromBytes(nd.Data()); err != nil || fsNode.Type() != unixfs.THAMTShard {
+		return cids
+	}
+
+	for _, l := range nd.Links() {
+		// Links to shards have only the prefix as their name.
+		if len(l.Name) == 2 {
+			cids = append(cids, g.entity(l.Cid)...)
+		}
+	}
+
+	return cids
+}
+
+func (g *fixtureGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&g.requests, 1)
+
+	c, err := cid.Decode(strings.TrimPrefix(r.URL.Path, "/ipfs/"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := g.blocks[c.String()]; !ok && c.Prefix().MhType != 0x00 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "raw":
+		if r.Header.Get("Accept") != rawContentType {
+			http.Error(w, "unexpected accept header", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", rawContentType)
+		if g.contentType != "" {
+			w.Header().Set("Content-Type", g.contentType)
+		}
+
+		w.Write(g.blocks[c.String()])
+
+	case "car":
+		if r.URL.Query().Get("dag-scope") != "entity" {
+			http.Error(w, "unexpected dag-scope", http.StatusBadRequest)
+			return
+		}
+
+		h, err := cbor.DumpObject(&carHeader{Roots: []cid.Cid{c}, Version: 1})
+		if err != nil {
+			panic(err)
+		}
+
+		var buf bytes.Buffer
+		writeSection(&buf, h)
+		for _, c := range g.entity(c) {
+			writeSection(&buf, c.Bytes(), g.blocks[c.String()])
+		}
+
+		w.Header().Set("Content-Type", carContentType+"; version=1")
+
+		if g.carBody != nil {
+			w.Write(g.carBody)
+			return
+		}
+
+		w.Write(buf.Bytes())
+
+	default:
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+	}
+}
+
+type GatewayTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+
+	fixture *fixtureGateway
+	server  *httptest.Server
+	g       *Gateway
+
+	root, file, raw, sharded ipld.Node
+}
+
+func (s *GatewayTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.fixture = &fixtureGateway{blocks: make(map[string][]byte)}
+
+	leaf1, leaf2 := dag.NewRawNode([]byte("hello ")), dag.NewRawNode([]byte("world"))
+	fsNode := unixfs.NewFSNode(unixfs.TFile)
+	fsNode.AddBlockSize(6)
+	fsNode.AddBlockSize(5)
+	data, err := fsNode.GetBytes()
+	s.Require().NoError(err)
+
+	file := dag.NodeWithData(data)
+	s.Require().NoError(file.AddNodeLink("", leaf1))
+	s.Require().NoError(file.AddNodeLink("", leaf2))
+	s.file = file
+
+	s.raw = dag.NewRawNode([]byte("raw"))
+
+	shard, err := hamt.NewShard(s.fixture, 256)
+	s.Require().NoError(err)
+
+	for i := 0; i < shardedEntries; i++ {
+		nd := dag.NewRawNode([]byte(fmt.Sprintf("entry %d", i)))
+		s.Require().NoError(s.fixture.Add(s.ctx, nd))
+		s.Require().NoError(shard.Set(s.ctx, fmt.Sprintf("file-%d.txt", i), nd))
+	}
+
+	s.sharded, err = shard.Node()
+	s.Require().NoError(err)
+
+	root := unixfs.EmptyDirNode()
+	s.Require().NoError(root.AddNodeLink("hello.txt", s.file))
+	s.Require().NoError(root.AddNodeLink("raw", s.raw))
+	s.Require().NoError(root.AddNodeLink("sharded", s.sharded))
+	s.root = root
+
+	s.Require().NoError(s.fixture.AddMany(s.ctx, []ipld.Node{leaf1, leaf2, s.file, s.raw, s.root}))
+
+	s.server = httptest.NewServer(s.fixture)
+
+	cfg := DefaultConfig()
+	cfg.TrustlessGatewayURL = s.server.URL
+	cfg.GatewayURL = "http://gateway.local/"
+
+	s.g = New(cfg, s.server.Client(), instr.New())
+}
+
+func (s *GatewayTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func resource(nd ipld.Node) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       nd.Cid().String(),
+		},
+	}
+}
+
+func (s *GatewayTestSuite) TestStatFile() {
+	r := resource(s.file)
+
+	s.NoError(s.g.Stat(s.ctx, r))
+	s.Equal(t.Stat{Type: t.FileType, Size: 11}, r.Stat)
+	s.Equal(int32(1), s.fixture.requests)
+}
+
+func (s *GatewayTestSuite) TestStatRaw() {
+	r := resource(s.raw)
+
+	s.NoError(s.g.Stat(s.ctx, r))
+	s.Equal(t.Stat{Type: t.FileType, Size: 3}, r.Stat)
+}
+
+func (s *GatewayTestSuite) TestStatDirectory() {
+	for _, nd := range []ipld.Node{s.root, s.sharded} {
+		r := resource(nd)
+
+		s.NoError(s.g.Stat(s.ctx, r))
+		s.Equal(t.DirectoryType, r.Type)
+	}
+}
+
+func (s *GatewayTestSuite) TestStatPartial() {
+	s.g.config.PartialSize = 3
+	r := resource(s.raw)
+
+	s.NoError(s.g.Stat(s.ctx, r))
+	s.Equal(t.PartialType, r.Type)
+}
+
+func (s *GatewayTestSuite) TestStatNotFound() {
+	err := s.g.Stat(s.ctx, resource(dag.NewRawNode([]byte("missing"))))
+
+	s.True(errors.Is(err, errUnexpectedStatus))
+	s.Equal(t.ErrTransient, t.ErrorClass(err))
+}
+
+func (s *GatewayTestSuite) TestStatInvalidCID() {
+	r := resource(s.raw)
+	r.ID = "invalid"
+
+	err := s.g.Stat(s.ctx, r)
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *GatewayTestSuite) TestStatHashMismatch() {
+	// Gateway serving different data for a CID.
+	s.fixture.blocks[s.raw.Cid().String()] = []byte("tampered")
+
+	err := s.g.Stat(s.ctx, resource(s.raw))
+	s.True(errors.Is(err, errHashMismatch))
+}
+
+func (s *GatewayTestSuite) TestStatBlockTooLarge() {
+	s.g.config.MaxBlockSize = 2
+
+	err := s.g.Stat(s.ctx, resource(s.raw))
+	s.True(errors.Is(err, errBlockTooLarge))
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *GatewayTestSuite) TestStatUnexpectedContentType() {
+	// Gateway not supporting trustless responses.
+	s.fixture.contentType = "text/plain"
+
+	err := s.g.Stat(s.ctx, resource(s.raw))
+	s.True(errors.Is(err, errUnexpectedContentType))
+	s.Equal(t.ErrBackendUnavailable, t.ErrorClass(err))
+}
+
+func (s *GatewayTestSuite) ls(nd ipld.Node) (map[string]*t.AnnotatedResource, error) {
+	out := make(chan *t.AnnotatedResource, 100)
+
+	err := s.g.Ls(s.ctx, resource(nd), out)
+	close(out)
+
+	entries := make(map[string]*t.AnnotatedResource)
+	for e := range out {
+		entries[e.Reference.Name] = e
+	}
+
+	return entries, err
+}
+
+func (s *GatewayTestSuite) TestLs() {
+	entries, err := s.ls(s.root)
+
+	s.NoError(err)
+	s.Len(entries, 3)
+
+	f := entries["hello.txt"]
+	s.Require().NotNil(f)
+	s.Equal(s.file.Cid().String(), f.ID)
+	s.Equal(t.IPFSProtocol, f.Protocol)
+	s.Equal(s.root.Cid().String(), f.Reference.Parent.ID)
+	s.Equal(t.UndefinedType, f.Type)
+
+	// Raw blocks are known to be files.
+	s.Equal(t.Stat{Type: t.FileType, Size: 3}, entries["raw"].Stat)
+
+	// Entries are not fetched.
+	s.Equal(int32(1), s.fixture.requests)
+}
+
+func (s *GatewayTestSuite) TestLsSharded() {
+	entries, err := s.ls(s.sharded)
+
+	s.NoError(err)
+	s.Len(entries, shardedEntries)
+	s.Equal(t.FileType, entries["file-7.txt"].Type)
+
+	// Shards are included in the CAR.
+	s.Equal(int32(1), s.fixture.requests)
+}
+
+func (s *GatewayTestSuite) TestLsShardedFallback() {
+	s.fixture.omitShards = true
+
+	entries, err := s.ls(s.sharded)
+
+	s.NoError(err)
+	s.Len(entries, shardedEntries)
+}
+
+func (s *GatewayTestSuite) TestLsFile() {
+	_, err := s.ls(s.file)
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *GatewayTestSuite) TestLsHashMismatch() {
+	s.fixture.blocks[s.root.Cid().String()] = s.sharded.RawData()
+
+	_, err := s.ls(s.root)
+	s.True(errors.Is(err, errHashMismatch))
+}
+
+func (s *GatewayTestSuite) TestLsHeaderTooLarge() {
+	// Hostile or broken gateway, announcing a header larger than can be allocated.
+	varint := make([]byte, binary.MaxVarintLen64)
+	s.fixture.carBody = varint[:binary.PutUvarint(varint, 1<<62)]
+
+	_, err := s.ls(s.root)
+	s.Error(err)
+	s.Contains(err.Error(), "CAR header too large")
+}
+
+func (s *GatewayTestSuite) TestResolve() {
+	r, err := s.g.Resolve(s.ctx, fmt.Sprintf("/ipfs/%s/sharded/file-7.txt", s.root.Cid()))
+
+	s.NoError(err)
+	s.Equal(dag.NewRawNode([]byte("entry 7")).Cid().String(), r.ID)
+	s.Equal("file-7.txt", r.Reference.Name)
+	s.Equal(s.sharded.Cid().String(), r.Reference.Parent.ID)
+}
+
+func (s *GatewayTestSuite) TestResolveCID() {
+	r, err := s.g.Resolve(s.ctx, s.root.Cid().String())
+
+	s.NoError(err)
+	s.Equal(s.root.Cid().String(), r.ID)
+	s.Nil(r.Reference.Parent)
+	s.Equal(int32(0), s.fixture.requests)
+}
+
+func (s *GatewayTestSuite) TestResolveNotExist() {
+	_, err := s.g.Resolve(s.ctx, fmt.Sprintf("/ipfs/%s/nothing", s.root.Cid()))
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *GatewayTestSuite) TestGatewayURL() {
+	r := resource(s.file)
+	s.Equal("http://gateway.local/ipfs/"+s.file.Cid().String(), s.g.GatewayURL(r))
+
+	r.Reference = t.Reference{
+		Parent: resource(s.root).Resource,
+		Name:   "my file.txt",
+	}
+	s.Equal("http://gateway.local/ipfs/"+s.root.Cid().String()+"/my%20file.txt", s.g.GatewayURL(r))
+}
+
+func (s *GatewayTestSuite) TestPing() {
+	s.NoError(s.g.Ping(s.ctx))
+
+	s.server.Close()
+	s.Error(s.g.Ping(s.ctx))
+}
+
+func TestGatewayTestSuite(t *testing.T) {
+	suite.Run(t, new(GatewayTestSuite))
+}
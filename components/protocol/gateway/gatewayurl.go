@@ -0,0 +1,31 @@
+package gateway
+
+import (
+	"fmt"
+	"net/url"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const ipfsPrefix = "/ipfs/"
+
+// namedPath returns the (escaped/raw) path for a resource, using the reference for the filename when available.
+func namedPath(r *t.AnnotatedResource) string {
+	if ref := r.Reference; ref.Name != "" {
+		return fmt.Sprintf("%s%s/%s", ipfsPrefix, ref.Parent.ID, url.PathEscape(ref.Name))
+	}
+
+	return ipfsPrefix + r.ID
+}
+
+// GatewayURL returns the URL to request a resource from the (non-trustless) gateway. If a reference is available,
+// it is used to generate the filename to facilitate content type detection, like for the IPFS protocol.
+func (g *Gateway) GatewayURL(r *t.AnnotatedResource) string {
+	url, err := g.gatewayURL.Parse(namedPath(r))
+
+	if err != nil {
+		panic(fmt.Sprintf("error generating GatewayURL: %v", err))
+	}
+
+	return url.String()
+}
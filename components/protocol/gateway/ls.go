@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	uio "github.com/ipfs/go-unixfs/io"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// linkStat returns the type and size of a directory entry, without fetching it. Only raw blocks are known to be
+// files; the type of other entries is resolved when they are crawled.
+func linkStat(link *ipld.Link) t.Stat {
+	if link.Cid.Type() == cid.Raw {
+		return t.Stat{
+			Type: t.FileType,
+			Size: link.Size,
+		}
+	}
+
+	return t.Stat{
+		Type: t.UndefinedType,
+		Size: link.Size,
+	}
+}
+
+// Ls streams the entries of a (HAMT sharded) UnixFS directory with Type, Size and Reference populated. The
+// directory is fetched as a single CAR, including all of its shards.
+func (g *Gateway) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	ctx, span := g.Tracer.Start(ctx, "protocol.gateway.Ls", trace.WithAttributes(label.String("cid", r.ID)))
+	defer span.End()
+
+	c, err := decodeCID(r)
+	if err != nil {
+		return err
+	}
+
+	preloaded, err := g.getCAR(ctx, c)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	// Blocks missing from the CAR are fetched individually.
+	ds := &dagService{g, preloaded}
+
+	nd, err := ds.Get(ctx, c)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	dir, err := uio.NewDirectoryFromNode(ds, nd)
+	if err != nil {
+		// Not a directory.
+		return t.Invalid(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for result := range dir.EnumLinksAsync(ctx) {
+		if result.Err != nil {
+			span.RecordError(ctx, result.Err, trace.WithErrorStatus(codes.Error))
+			return result.Err
+		}
+
+		refR := &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       result.Link.Cid.String(),
+			},
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   result.Link.Name,
+			},
+			Stat: linkStat(result.Link),
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- refR:
+		}
+	}
+
+	return ctx.Err()
+}
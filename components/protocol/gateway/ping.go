@@ -0,0 +1,19 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+)
+
+// emptyCID is the identity CID of an empty raw block, which gateways can return without fetching anything.
+var emptyCID, _ = cid.Decode("bafkqaaa")
+
+// Ping checks whether the trustless gateway is available, returning an error otherwise.
+func (g *Gateway) Ping(ctx context.Context) error {
+	ctx, span := g.Tracer.Start(ctx, "protocol.gateway.Ping")
+	defer span.End()
+
+	_, err := g.getBlock(ctx, emptyCID)
+	return err
+}
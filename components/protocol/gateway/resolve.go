@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	uio "github.com/ipfs/go-unixfs/io"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Resolve returns the resource for a CID or an IPFS path, e.g. `/ipfs/<cid>/sub/path`, walking the directories
+// along the path with verified blocks. Resources within a directory reference their parent directory by the last
+// path segment.
+func (g *Gateway) Resolve(ctx context.Context, path string) (*t.AnnotatedResource, error) {
+	ctx, span := g.Tracer.Start(ctx, "protocol.gateway.Resolve", trace.WithAttributes(label.String("path", path)))
+	defer span.End()
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, ipfsPrefix), "/"), "/")
+	if segments[0] == "" {
+		return nil, fmt.Errorf("%w: empty path", t.ErrInvalidResource)
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       segments[0],
+		},
+	}
+
+	if len(segments) == 1 {
+		return r, nil
+	}
+
+	c, err := decodeCID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := &dagService{g: g}
+
+	nd, err := ds.Get(ctx, c)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	for _, name := range segments[1:] {
+		dir, err := uio.NewDirectoryFromNode(ds, nd)
+		if err != nil {
+			return nil, t.Invalid(fmt.Errorf("resolving %s: %w", name, err))
+		}
+
+		if nd, err = dir.Find(ctx, name); err != nil {
+			err = fmt.Errorf("resolving %s: %w", name, err)
+			if errors.Is(err, os.ErrNotExist) {
+				err = t.Invalid(err)
+			}
+
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return nil, err
+		}
+
+		r = &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       nd.Cid().String(),
+			},
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   name,
+			},
+		}
+	}
+
+	return r, nil
+}
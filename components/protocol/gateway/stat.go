@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol/car"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// decodeCID decodes the CID of a resource.
+func decodeCID(r *t.AnnotatedResource) (cid.Cid, error) {
+	c, err := cid.Decode(r.ID)
+	if err != nil {
+		return cid.Undef, t.Invalid(err)
+	}
+
+	return c, nil
+}
+
+// Stat populates Type and Size of an AnnotatedResource, from its root block.
+func (g *Gateway) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := g.Tracer.Start(ctx, "protocol.gateway.Stat", trace.WithAttributes(label.String("cid", r.ID)))
+	defer span.End()
+
+	c, err := decodeCID(r)
+	if err != nil {
+		return err
+	}
+
+	nd, err := (&dagService{g: g}).Get(ctx, c)
+	if err == nil {
+		r.Stat, err = car.StatNode(nd)
+	}
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	// Override type for *unreferenced* partials, based on size
+	if r.Size == uint64(g.config.PartialSize) && r.Reference.Parent == nil {
+		r.Stat.Type = t.PartialType
+	}
+
+	return nil
+}
@@ -1,13 +1,30 @@
 package ipfs
 
 import (
+	"time"
+
 	"github.com/c2h5oh/datasize"
 )
 
+// Balancing strategies, selecting the node for requests.
+const (
+	LeastOutstanding = "least-outstanding" // Healthy node with the fewest requests in progress.
+	ConsistentHash   = "consistent-hash"   // Healthy node selected by CID, for cache locality.
+)
+
+// Node specifies an IPFS node.
+type Node struct {
+	APIURL     string // URL of the API endpoint of the node (for Ls and Stat calls).
+	GatewayURL string // URL of the Gateway of the node (to request content).
+}
+
 // Config specifies the configuration for the IPFS protocol.
 type Config struct {
-	APIURL      string            // URL of an IPFS API endpoint (for Ls and Stat calls).
-	GatewayURL  string            // URL of an IPFS Gateway (to request content).
+	APIURL      string            // URL of an IPFS API endpoint (for Ls and Stat calls), used when Nodes is empty.
+	GatewayURL  string            // URL of an IPFS Gateway (to request content), used when Nodes is empty.
+	Nodes       []Node            // IPFS nodes to spread Ls and Stat calls across.
+	Balancing   string            // Balancing strategy; either LeastOutstanding or ConsistentHash.
+	Backoff     time.Duration     // Duration for which nodes which could not be connected to are avoided.
 	PartialSize datasize.ByteSize // Filesize of items which are being considered partials (chunks).
 }
 
@@ -16,8 +33,19 @@ func DefaultConfig() *Config {
 	return &Config{
 		APIURL:      "http://localhost:5001",
 		GatewayURL:  "http://localhost:8080",
+		Balancing:   LeastOutstanding,
+		Backoff:     30 * time.Second,
 		PartialSize: 262144,
 		// 256KB is the default chunker block size. Therefore, unreferenced files with exactly
 		// this size are very likely to be chunks of files (partials) rather than full files.
 	}
 }
+
+// nodes returns the configured nodes, being the node in APIURL and GatewayURL unless Nodes is specified.
+func (c *Config) nodes() []Node {
+	if len(c.Nodes) > 0 {
+		return c.Nodes
+	}
+
+	return []Node{{c.APIURL, c.GatewayURL}}
+}
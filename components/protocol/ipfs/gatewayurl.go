@@ -21,9 +21,10 @@ func namedPath(r *t.AnnotatedResource) string {
 // GatewayURL returns the URL to request a resource from the gateway.
 // If a reference is available, it is used to generate the filename to facilitate content
 // type detection (e.g. /ipfs/<parent_hash>/my_file.jpg instead of /ipfs/<file_hash>/).
+// The gateway is that of the node which has fetched the resource, so that its content is likely to be cached.
 // Ref: http://docs.ipfs.io.ipns.localhost:8080/concepts/ipfs-gateway/#gateway-types
 func (i *IPFS) GatewayURL(r *t.AnnotatedResource) string {
-	url, err := i.gatewayURL.Parse(namedPath(r))
+	url, err := i.fetchedBy(r.ID).gatewayURL.Parse(namedPath(r))
 
 	if err != nil {
 		panic(fmt.Sprintf("error generating GatewayURL: %v", err))
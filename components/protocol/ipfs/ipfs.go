@@ -3,9 +3,8 @@ package ipfs
 import (
 	"fmt"
 	"net/http"
-	"net/url"
 
-	ipfs "github.com/ipfs/go-ipfs-api"
+	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/ipfs-search/ipfs-search/components/protocol"
 
@@ -13,12 +12,17 @@ import (
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
-// IPFS implements the Protocol interface for the Interplanery Filesystem. It is concurrency-safe.
+// fetchedSize is the number of resources for which the node which fetched them is remembered. It should comfortably
+// exceed the number of resources between being statted and their content being extracted.
+const fetchedSize = 100000
+
+// IPFS implements the Protocol interface for the Interplanery Filesystem, spreading requests across one or more
+// IPFS nodes. It is concurrency-safe.
 type IPFS struct {
 	config *Config
 
-	gatewayURL *url.URL
-	shell      *ipfs.Shell
+	nodes   []*node
+	fetched *lru.Cache // Node which fetched a resource, by resource ID.
 
 	*instr.Instrumentation
 }
@@ -30,23 +34,26 @@ func absolutePath(r *t.AnnotatedResource) string {
 
 // New returns a new IPFS protocol.
 func New(config *Config, client *http.Client, instr *instr.Instrumentation) *IPFS {
-	// Initialize gatewayURL
-	gatewayURL, err := url.Parse(config.GatewayURL)
-	if err != nil {
-		panic(fmt.Sprintf("could not parse IPFS Gateway URL, error: %v", err))
+	if config.Balancing != LeastOutstanding && config.Balancing != ConsistentHash {
+		panic(fmt.Sprintf("unknown balancing strategy '%s'", config.Balancing))
 	}
 
-	if !gatewayURL.IsAbs() {
-		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
+	nodeConfigs := config.nodes()
+	nodes := make([]*node, len(nodeConfigs))
+
+	for i, cfg := range nodeConfigs {
+		nodes[i] = newNode(cfg, client)
 	}
 
-	// Create IPFS shell
-	shell := ipfs.NewShellWithClient(config.APIURL, client)
+	fetched, err := lru.New(fetchedSize)
+	if err != nil {
+		panic(err)
+	}
 
 	return &IPFS{
 		config,
-		gatewayURL,
-		shell,
+		nodes,
+		fetched,
 		instr,
 	}
 }
@@ -128,72 +128,77 @@ func (i *IPFS) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.Ann
 	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Ls")
 	defer span.End()
 
-	path := absolutePath(r)
-
-	resp, err := i.shell.Request("ls", path).
-		Option("resolve-type", false).
-		Option("size", false).
-		Option("stream", true).
-		Send(ctx)
-	if err != nil {
-		return err
-	}
-
-	// If err == nil, response might be nil and cannot be closed.
-	defer resp.Close()
-
-	if err := resp.Error; err != nil {
-		if isInvalidResourceErr(resp.Error) {
-			// Wrap original error with ErrInvalidResource.
-			return fmt.Errorf("%w: %v", t.ErrInvalidResource, resp.Error)
+	return i.do(r.ID, func(n *node) error {
+		path := absolutePath(r)
+
+		resp, err := n.shell.Request("ls", path).
+			Option("resolve-type", false).
+			Option("size", false).
+			Option("stream", true).
+			Send(ctx)
+		if err != nil {
+			return err
 		}
 
-		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return err
-	}
+		// If err == nil, response might be nil and cannot be closed.
+		defer resp.Close()
 
-	dec := json.NewDecoder(resp.Output)
+		if err := resp.Error; err != nil {
+			if isInvalidResourceErr(resp.Error) {
+				// Wrap original error with ErrInvalidResource.
+				return fmt.Errorf("%w: %v", t.ErrInvalidResource, resp.Error)
+			}
 
-	for {
-		link, err := decodeLink(dec)
-		if err != nil {
-			// Decoding errors result in termination of the loop.
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
 
-			// TODO: Consider using an error channel here; don't abort on individual decoding errors?
-			// Alternativel: propagate an InvalidType object instead and log the error without propagating.
-			// Needs real world testing. How many directories with invalid entries are there,
-			// and should we care about them?
+		dec := json.NewDecoder(resp.Output)
 
-			if errors.Is(err, io.EOF) {
-				// EOF means we're done, hence err is cleared
-				err = nil
+		for {
+			link, err := decodeLink(dec)
+			if err != nil {
+				// Decoding errors result in termination of the loop.
+
+				// TODO: Consider using an error channel here; don't abort on individual decoding errors?
+				// Alternativel: propagate an InvalidType object instead and log the error without propagating.
+				// Needs real world testing. How many directories with invalid entries are there,
+				// and should we care about them?
+
+				if errors.Is(err, io.EOF) {
+					// EOF means we're done, hence err is cleared
+					err = nil
+				}
+
+				if err != nil {
+					span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+				}
+				return err
 			}
 
-			if err != nil {
-				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			refR := t.AnnotatedResource{
+				Resource: &t.Resource{
+					Protocol: t.IPFSProtocol,
+					ID:       link.Hash,
+				},
+				Reference: t.Reference{
+					Parent: r.Resource,
+					Name:   link.Name,
+				},
+				Stat: t.Stat{
+					Type: typeFromPb(link.Type),
+					Size: link.Size,
+				},
 			}
-			return err
-		}
 
-		refR := t.AnnotatedResource{
-			Resource: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       link.Hash,
-			},
-			Reference: t.Reference{
-				Parent: r.Resource,
-				Name:   link.Name,
-			},
-			Stat: t.Stat{
-				Type: typeFromPb(link.Type),
-				Size: link.Size,
-			},
-		}
+			// The listing node has the directory, through which the gateway URLs of entries are named.
+			i.fetched.Add(link.Hash, n)
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case out <- &refR:
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- &refR:
+			}
 		}
-	}
+	})
 }
@@ -0,0 +1,168 @@
+package ipfs
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	ipfs "github.com/ipfs/go-ipfs-api"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// node is an IPFS node, whose API is used for Ls and Stat calls and whose gateway is used to request content.
+type node struct {
+	apiURL     string
+	gatewayURL *url.URL
+	shell      *ipfs.Shell
+
+	outstanding  int64 // Requests in progress; accessed atomically.
+	backoffUntil int64 // Time until which the node is avoided, in Unix nanoseconds; accessed atomically.
+}
+
+func newNode(cfg Node, client *http.Client) *node {
+	gatewayURL, err := url.Parse(cfg.GatewayURL)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse IPFS Gateway URL, error: %v", err))
+	}
+
+	if !gatewayURL.IsAbs() {
+		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
+	}
+
+	return &node{
+		apiURL:     cfg.APIURL,
+		gatewayURL: gatewayURL,
+		shell:      ipfs.NewShellWithClient(cfg.APIURL, client),
+	}
+}
+
+func (n *node) String() string {
+	return n.apiURL
+}
+
+// healthy returns whether the node is not being avoided after failing to connect.
+func (n *node) healthy(now time.Time) bool {
+	return now.UnixNano() >= atomic.LoadInt64(&n.backoffUntil)
+}
+
+// record updates the health of the node from the result of a request: nodes which could not be connected to are
+// avoided for the backoff duration, any other result shows the node to be available.
+func (n *node) record(err error, backoff time.Duration) {
+	if t.ErrorClass(err) == t.ErrBackendUnavailable {
+		atomic.StoreInt64(&n.backoffUntil, time.Now().Add(backoff).UnixNano())
+	} else {
+		atomic.StoreInt64(&n.backoffUntil, 0)
+	}
+}
+
+// score returns the rendezvous hashing score of the node for a resource ID.
+func (n *node) score(id string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(n.apiURL))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+
+	// FNV barely mixes the high bits for keys differing in few bytes, e.g. ports; finalize as in SplitMix64.
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+
+	return x ^ (x >> 31)
+}
+
+// rendezvous returns the node with the highest score for a resource ID, such that a resource is consistently
+// mapped to the same node and only resources of a removed or unhealthy node are remapped.
+func rendezvous(nodes []*node, id string) *node {
+	var (
+		best      *node
+		bestScore uint64
+	)
+
+	for _, n := range nodes {
+		if s := n.score(id); best == nil || s > bestScore {
+			best, bestScore = n, s
+		}
+	}
+
+	return best
+}
+
+// leastOutstanding returns the node with the fewest requests in progress, breaking ties by rendezvous hashing.
+func leastOutstanding(nodes []*node, id string) *node {
+	var (
+		candidates []*node
+		min        int64
+	)
+
+	for _, n := range nodes {
+		outstanding := atomic.LoadInt64(&n.outstanding)
+
+		switch {
+		case candidates == nil || outstanding < min:
+			candidates, min = []*node{n}, outstanding
+		case outstanding == min:
+			candidates = append(candidates, n)
+		}
+	}
+
+	return rendezvous(candidates, id)
+}
+
+// nodeFor returns the node to send a request for a resource ID to, according to the balancing strategy. Unhealthy
+// nodes are avoided, unless all nodes are unhealthy.
+func (i *IPFS) nodeFor(id string) *node {
+	if len(i.nodes) == 1 {
+		return i.nodes[0]
+	}
+
+	now := time.Now()
+	candidates := make([]*node, 0, len(i.nodes))
+
+	for _, n := range i.nodes {
+		if n.healthy(now) {
+			candidates = append(candidates, n)
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = i.nodes
+	}
+
+	if i.config.Balancing == ConsistentHash {
+		return rendezvous(candidates, id)
+	}
+
+	return leastOutstanding(candidates, id)
+}
+
+// do calls f with the node for a resource ID, tracking requests in progress and the health of the node.
+// On success, the node is remembered as having fetched the resource.
+func (i *IPFS) do(id string, f func(*node) error) error {
+	n := i.nodeFor(id)
+
+	atomic.AddInt64(&n.outstanding, 1)
+	err := f(n)
+	atomic.AddInt64(&n.outstanding, -1)
+
+	n.record(err, i.config.Backoff)
+
+	if err == nil {
+		i.fetched.Add(id, n)
+	}
+
+	return err
+}
+
+// fetchedBy returns the node which has fetched a resource or, when unknown, the node a resource consistently
+// maps to.
+func (i *IPFS) fetchedBy(id string) *node {
+	if n, ok := i.fetched.Get(id); ok {
+		return n.(*node)
+	}
+
+	return rendezvous(i.nodes, id)
+}
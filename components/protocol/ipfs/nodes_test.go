@@ -0,0 +1,234 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// apiNode is a stand-in for the API of an IPFS node, counting stat requests.
+type apiNode struct {
+	*httptest.Server
+	stats int32
+}
+
+func newAPINode() *apiNode {
+	n := new(apiNode)
+
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/v0/files/stat"):
+			atomic.AddInt32(&n.stats, 1)
+			w.Write([]byte(`{"Size":5,"CumulativeSize":16,"Type":"file"}`))
+		case strings.HasPrefix(r.URL.Path, "/api/v0/ls"):
+			w.Write([]byte(`{"Objects":[{"Hash":"QmDir","Links":[{"Name":"a.txt","Hash":"QmChild","Size":5,"Type":2}]}]}`))
+		case strings.HasPrefix(r.URL.Path, "/api/v0/version"):
+			w.Write([]byte(`{"Version":"0.7.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return n
+}
+
+type NodesTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	api  []*apiNode
+	cfg  *Config
+	ipfs *IPFS
+}
+
+func (s *NodesTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.api = []*apiNode{newAPINode(), newAPINode(), newAPINode()}
+
+	s.cfg = DefaultConfig()
+	s.cfg.Nodes = nil
+
+	for i, n := range s.api {
+		s.cfg.Nodes = append(s.cfg.Nodes, Node{
+			APIURL:     n.URL,
+			GatewayURL: fmt.Sprintf("http://gateway%d:8080", i),
+		})
+	}
+
+	s.ipfs = New(s.cfg, http.DefaultClient, instr.New())
+}
+
+func (s *NodesTestSuite) TearDownTest() {
+	for _, n := range s.api {
+		n.Close()
+	}
+}
+
+func resource(id string) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       id,
+		},
+	}
+}
+
+// stats returns the number of stat requests per node.
+func (s *NodesTestSuite) stats() []int32 {
+	stats := make([]int32, len(s.api))
+	for i, n := range s.api {
+		stats[i] = atomic.LoadInt32(&n.stats)
+	}
+
+	return stats
+}
+
+func (s *NodesTestSuite) TestSingleNode() {
+	cfg := DefaultConfig()
+	cfg.APIURL = s.api[0].URL
+
+	i := New(cfg, http.DefaultClient, instr.New())
+
+	s.NoError(i.Stat(s.ctx, resource("QmA")))
+	s.Equal([]int32{1, 0, 0}, s.stats())
+	s.Equal("http://localhost:8080/ipfs/QmA", i.GatewayURL(resource("QmA")))
+}
+
+func (s *NodesTestSuite) TestConsistentHash() {
+	s.cfg.Balancing = ConsistentHash
+	s.ipfs = New(s.cfg, http.DefaultClient, instr.New())
+
+	n := s.ipfs.nodeFor("QmA")
+	for i := 0; i < 10; i++ {
+		s.Equal(n, s.ipfs.nodeFor("QmA"))
+	}
+
+	// Resources are spread across nodes.
+	for i := 0; i < 100; i++ {
+		s.NoError(s.ipfs.Stat(s.ctx, resource(fmt.Sprintf("Qm%d", i))))
+	}
+
+	for _, count := range s.stats() {
+		s.NotZero(count)
+	}
+}
+
+func (s *NodesTestSuite) TestLeastOutstanding() {
+	n := s.ipfs.nodeFor("QmA")
+	n.outstanding = 1
+
+	s.NotEqual(n, s.ipfs.nodeFor("QmA"))
+
+	// Without requests in progress, selection is consistent.
+	n.outstanding = 0
+	s.Equal(n, s.ipfs.nodeFor("QmA"))
+}
+
+func (s *NodesTestSuite) TestUnavailableNodeAvoided() {
+	s.cfg.Balancing = ConsistentHash
+	s.ipfs = New(s.cfg, http.DefaultClient, instr.New())
+
+	n := s.ipfs.nodeFor("QmA")
+	for i, node := range s.ipfs.nodes {
+		if node == n {
+			s.api[i].Close()
+		}
+	}
+
+	err := s.ipfs.Stat(s.ctx, resource("QmA"))
+	s.Equal(t.ErrBackendUnavailable, t.ErrorClass(err))
+
+	// Retried on another node.
+	s.NotEqual(n, s.ipfs.nodeFor("QmA"))
+	s.NoError(s.ipfs.Stat(s.ctx, resource("QmA")))
+}
+
+func (s *NodesTestSuite) TestAllNodesUnavailable() {
+	for _, n := range s.ipfs.nodes {
+		n.record(t.BackendUnavailable(fmt.Errorf("down")), s.cfg.Backoff)
+	}
+
+	s.NotNil(s.ipfs.nodeFor("QmA"))
+}
+
+func (s *NodesTestSuite) TestGatewayURLOfFetchingNode() {
+	// Occupy all but the last node, such that it fetches the resource.
+	for _, n := range s.ipfs.nodes[:2] {
+		n.outstanding = 1
+	}
+
+	s.NoError(s.ipfs.Stat(s.ctx, resource("QmA")))
+	s.Equal([]int32{0, 0, 1}, s.stats())
+
+	for _, n := range s.ipfs.nodes[:2] {
+		n.outstanding = 0
+	}
+
+	s.Equal("http://gateway2:8080/ipfs/QmA", s.ipfs.GatewayURL(resource("QmA")))
+}
+
+func (s *NodesTestSuite) TestGatewayURLOfListingNode() {
+	// List the directory on a node which the entry does not map to, by occupying the others.
+	lister := 0
+	if rendezvous(s.ipfs.nodes, "QmChild") == s.ipfs.nodes[lister] {
+		lister = 1
+	}
+
+	for i, n := range s.ipfs.nodes {
+		if i != lister {
+			n.outstanding = 1
+		}
+	}
+
+	dir := resource("QmDir")
+	out := make(chan *t.AnnotatedResource, 1)
+
+	s.NoError(s.ipfs.Ls(s.ctx, dir, out))
+	child := <-out
+
+	for _, n := range s.ipfs.nodes {
+		n.outstanding = 0
+	}
+
+	// Entries arrive typed and are not statted; they are extracted through the node which listed them.
+	s.Equal(t.FileType, child.Type)
+	s.Equal(fmt.Sprintf("http://gateway%d:8080/ipfs/QmDir/a.txt", lister), s.ipfs.GatewayURL(child))
+}
+
+func (s *NodesTestSuite) TestGatewayURLUnfetched() {
+	// Consistent for resources which have not been fetched.
+	url := s.ipfs.GatewayURL(resource("QmA"))
+	s.Equal(url, s.ipfs.GatewayURL(resource("QmA")))
+}
+
+func (s *NodesTestSuite) TestPing() {
+	s.api[0].Close()
+	s.NoError(s.ipfs.Ping(s.ctx))
+	s.False(s.ipfs.nodes[0].healthy(time.Now()))
+
+	for _, n := range s.api[1:] {
+		n.Close()
+	}
+	s.Error(s.ipfs.Ping(s.ctx))
+}
+
+func (s *NodesTestSuite) TestUnknownBalancing() {
+	s.cfg.Balancing = "random"
+	s.Panics(func() { New(s.cfg, http.DefaultClient, instr.New()) })
+}
+
+func TestNodesTestSuite(t *testing.T) {
+	suite.Run(t, new(NodesTestSuite))
+}
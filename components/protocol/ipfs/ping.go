@@ -0,0 +1,35 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ping checks whether the API of any of the IPFS nodes is available, returning an error otherwise. The health of
+// each node is updated accordingly.
+func (i *IPFS) Ping(ctx context.Context) error {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Ping")
+	defer span.End()
+
+	var (
+		available bool
+		lastErr   error
+	)
+
+	for _, n := range i.nodes {
+		err := n.shell.Request("version").Exec(ctx, nil)
+		n.record(err, i.config.Backoff)
+
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", n, err)
+		} else {
+			available = true
+		}
+	}
+
+	if available {
+		return nil
+	}
+
+	return lastErr
+}
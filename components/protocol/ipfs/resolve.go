@@ -0,0 +1,84 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const ipfsPrefix = "/ipfs/"
+
+type resolveResult struct {
+	Path string
+}
+
+// resolveCID returns the CID referred to by an IPFS path, requested from the node for the CID the path starts with.
+// Ref: https://docs.ipfs.io/reference/http/api/#api-v0-resolve
+func (i *IPFS) resolveCID(ctx context.Context, root string, path string) (string, error) {
+	result := new(resolveResult)
+
+	err := i.do(root, func(n *node) error {
+		return n.shell.Request("resolve", path).Exec(ctx, result)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(result.Path, ipfsPrefix), nil
+}
+
+// Resolve returns the resource for a CID or an IPFS path, e.g. `/ipfs/<cid>/sub/path`. Resources within a directory
+// reference their parent directory by the last path segment.
+func (i *IPFS) Resolve(ctx context.Context, path string) (*t.AnnotatedResource, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Resolve", trace.WithAttributes(label.String("path", path)))
+	defer span.End()
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, ipfsPrefix), "/"), "/")
+	if segments[0] == "" {
+		return nil, fmt.Errorf("%w: empty path", t.ErrInvalidResource)
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       segments[0],
+		},
+	}
+
+	if len(segments) == 1 {
+		return r, nil
+	}
+
+	var err error
+
+	if r.ID, err = i.resolveCID(ctx, segments[0], ipfsPrefix+strings.Join(segments, "/")); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	last := len(segments) - 1
+
+	parentID := segments[0]
+	if last > 1 {
+		if parentID, err = i.resolveCID(ctx, segments[0], ipfsPrefix+strings.Join(segments[:last], "/")); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return nil, err
+		}
+	}
+
+	r.Reference = t.Reference{
+		Parent: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       parentID,
+		},
+		Name: segments[last],
+	}
+
+	return r, nil
+}
@@ -0,0 +1,121 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type ResolveTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+	responseHeader http.Header
+}
+
+func (s *ResolveTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+	s.responseHeader = http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+}
+
+func (s *ResolveTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *ResolveTestSuite) expectResolve(path, result string) {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/resolve?arg="+path, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Path":"/ipfs/` + result + `"}`),
+		}).
+		Once()
+}
+
+func (s *ResolveTestSuite) TestCID() {
+	r, err := s.ipfs.Resolve(s.ctx, "QmRoot")
+
+	s.NoError(err)
+	s.Equal(&t.Resource{Protocol: t.IPFSProtocol, ID: "QmRoot"}, r.Resource)
+	s.Nil(r.Reference.Parent)
+}
+
+func (s *ResolveTestSuite) TestBarePath() {
+	r, err := s.ipfs.Resolve(s.ctx, "/ipfs/QmRoot/")
+
+	s.NoError(err)
+	s.Equal("QmRoot", r.ID)
+}
+
+func (s *ResolveTestSuite) TestDirectChild() {
+	s.expectResolve("%2Fipfs%2FQmRoot%2Ffile.txt", "QmFile")
+
+	r, err := s.ipfs.Resolve(s.ctx, "/ipfs/QmRoot/file.txt")
+
+	s.NoError(err)
+	s.Equal("QmFile", r.ID)
+	s.Equal(t.Reference{
+		Parent: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmRoot"},
+		Name:   "file.txt",
+	}, r.Reference)
+}
+
+func (s *ResolveTestSuite) TestNestedPath() {
+	s.expectResolve("%2Fipfs%2FQmRoot%2Fsub%2Ffile.txt", "QmFile")
+	s.expectResolve("%2Fipfs%2FQmRoot%2Fsub", "QmSub")
+
+	r, err := s.ipfs.Resolve(s.ctx, "/ipfs/QmRoot/sub/file.txt")
+
+	s.NoError(err)
+	s.Equal("QmFile", r.ID)
+	s.Equal("QmSub", r.Reference.Parent.ID)
+	s.Equal("file.txt", r.Reference.Name)
+}
+
+func (s *ResolveTestSuite) TestNotFound() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/resolve?arg=%2Fipfs%2FQmRoot%2Fmissing", mock.Anything).
+		Return(httpmock.Response{
+			Status: 500,
+			Header: s.responseHeader,
+			Body:   []byte(`{"Message":"no link named \"missing\" under QmRoot","Code":0,"Type":"error"}`),
+		}).
+		Once()
+
+	_, err := s.ipfs.Resolve(s.ctx, "/ipfs/QmRoot/missing")
+
+	s.Error(err)
+}
+
+func (s *ResolveTestSuite) TestEmpty() {
+	_, err := s.ipfs.Resolve(s.ctx, "/ipfs/")
+
+	s.True(errors.Is(err, t.ErrInvalidResource))
+}
+
+func TestResolveTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolveTestSuite))
+}
@@ -44,11 +44,12 @@ func (i *IPFS) Stat(ctx context.Context, r *t.AnnotatedResource) error {
 	const cmd = "files/stat"
 
 	path := absolutePath(r)
-	req := i.shell.Request(cmd, path)
-
 	result := new(statResult)
 
-	if err := req.Exec(ctx, result); err != nil {
+	err := i.do(r.ID, func(n *node) error {
+		return n.shell.Request(cmd, path).Exec(ctx, result)
+	})
+	if err != nil {
 		if isInvalidResourceErr(err) {
 			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
 		}
@@ -2,6 +2,9 @@ package amqp
 
 import (
 	"context"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel/api/trace"
@@ -11,18 +14,306 @@ import (
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
-// Channel wraps an AMQP channel
+// Channel wraps an AMQP channel, which is reopened when it is closed by the server or the connection is lost.
+// Upon reopening, queues are redeclared and consumers resubscribed.
 type Channel struct {
-	ch *amqp.Channel
+	conn          *Connection
+	prefetchCount int
+	confirm       bool // Whether publishing waits for confirmation by the server.
+
+	mu       sync.Mutex
+	ch       *amqp.Channel
+	ready    chan struct{}            // Closed when ch is usable, replaced when the channel is lost.
+	confirms <-chan amqp.Confirmation // Confirmations of publishings on ch, in confirm mode.
+	tag      uint64                   // Delivery tag of the last publishing on ch.
+	queues   []*Queue                 // Queues to recover.
+	closed   bool
+
+	publishMu sync.Mutex // Serializes publishing in confirm mode, matching confirmations to publishings.
+
 	*instr.Instrumentation
 }
 
+// open opens and configures a new underlying channel, returning it along with a channel notifying its closure.
+func (c *Channel) open(ctx context.Context) (*amqp.Channel, <-chan *amqp.Error, error) {
+	conn, err := c.conn.current(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeChan := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	c.mu.Lock()
+	prefetchCount := c.prefetchCount
+	c.mu.Unlock()
+
+	// Set Qos
+	err = ch.Qos(
+		prefetchCount,
+		0,     // prefetch size
+		false, // global
+	)
+	if err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
+
+	if c.confirm {
+		if err := ch.Confirm(false); err != nil {
+			ch.Close()
+			return nil, nil, err
+		}
+	}
+
+	return ch, closeChan, nil
+}
+
+// set makes ch available to queues and monitors it for closure.
+func (c *Channel) set(ctx context.Context, ch *amqp.Channel, closeChan <-chan *amqp.Error) {
+	c.mu.Lock()
+	c.ch = ch
+	c.tag = 0
+	if c.confirm {
+		c.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	}
+	close(c.ready)
+	c.mu.Unlock()
+
+	go c.monitor(ctx, closeChan)
+}
+
+// monitor waits for the channel to close, recovering it unless it was closed gracefully.
+func (c *Channel) monitor(ctx context.Context, closeChan <-chan *amqp.Error) {
+	err, ok := <-closeChan
+	if !ok {
+		// Graceful close, either of channel or connection.
+		return
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.ready = make(chan struct{})
+	c.mu.Unlock()
+
+	log.Printf("AMQP channel closed, recovering: %v", err)
+	c.recover(ctx)
+}
+
+// recover reopens the channel, redeclaring queues and resubscribing consumers, until it succeeds or the
+// connection is closed.
+func (c *Channel) recover(ctx context.Context) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel.recover")
+	defer span.End()
+
+	for {
+		ch, closeChan, err := c.open(ctx)
+		if err == nil {
+			if err = c.recoverQueues(ch); err == nil {
+				c.set(ctx, ch, closeChan)
+				log.Println("AMQP channel recovered")
+				return
+			}
+
+			ch.Close()
+		}
+
+		span.RecordError(ctx, err)
+
+		if err == amqp.ErrClosed || ctx.Err() != nil {
+			// Connection closed; nothing to recover.
+			return
+		}
+
+		log.Printf("Error recovering AMQP channel, retrying in %s: %v", c.conn.config.ReconnectTime, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.conn.config.ReconnectTime):
+		}
+	}
+}
+
+// recoverQueues redeclares queues and resubscribes their consumers on ch.
+func (c *Channel) recoverQueues(ch *amqp.Channel) error {
+	c.mu.Lock()
+	queues := c.queues
+	c.mu.Unlock()
+
+	for _, q := range queues {
+		if err := declare(ch, q.name); err != nil {
+			return err
+		}
+
+		if err := q.resubscribe(ch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// available returns the underlying channel without waiting, and whether it is currently usable.
+func (c *Channel) available() (*amqp.Channel, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.ready:
+		return c.ch, !c.closed
+	default:
+		return nil, false
+	}
+}
+
+// current returns the underlying channel, waiting for recovery when it is lost.
+func (c *Channel) current(ctx context.Context) (*amqp.Channel, error) {
+	c.mu.Lock()
+	ready, closed := c.ready, c.closed
+	c.mu.Unlock()
+
+	if closed {
+		return nil, amqp.ErrClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-ready:
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ch, nil
+}
+
+// publish publishes msg to the named queue. In confirm mode, it waits for the server to confirm the publishing,
+// returning ErrNotConfirmed when it is rejected.
+func (c *Channel) publish(ctx context.Context, name string, msg amqp.Publishing) error {
+	if c.confirm {
+		c.publishMu.Lock()
+		defer c.publishMu.Unlock()
+	}
+
+	if _, err := c.current(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	ch, confirms := c.ch, c.confirms
+	c.tag++
+	tag := c.tag
+	c.mu.Unlock()
+
+	err := ch.Publish(
+		"",    // exchange
+		name,  // routing key
+		true,  // mandatory
+		false, // immediate
+		msg,
+	)
+	if err != nil || !c.confirm {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case confirmation, ok := <-confirms:
+			if !ok {
+				// Channel lost before confirmation.
+				return amqp.ErrClosed
+			}
+
+			if confirmation.DeliveryTag < tag {
+				// Late confirmation of a publishing which stopped waiting.
+				continue
+			}
+
+			if !confirmation.Ack {
+				return ErrNotConfirmed
+			}
+
+			return nil
+		}
+	}
+}
+
+// SetPrefetch changes the prefetch count of the channel, which is retained when the channel is recovered.
+// As RabbitMQ only applies a changed (per-consumer) prefetch count to new consumers, consuming queues are
+// resubscribed. A count of 0 means no limit.
+func (c *Channel) SetPrefetch(ctx context.Context, count int) error {
+	c.mu.Lock()
+	c.prefetchCount = count
+	queues := c.queues
+	c.mu.Unlock()
+
+	ch, ok := c.available()
+	if !ok {
+		// Applied when the channel is recovered.
+		return nil
+	}
+
+	err := ch.Qos(
+		count,
+		0,     // prefetch size
+		false, // global
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, q := range queues {
+		if err := q.renew(ch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Queue creates a named queue on a given chennel
 func (c *Channel) Queue(ctx context.Context, name string) (*Queue, error) {
 	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel.Queue", trace.WithAttributes(label.String("queue", name)))
 	defer span.End()
 
-	_, err := c.ch.QueueDeclare(
+	ch, err := c.current(ctx)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	if err := declare(ch, name); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	q := &Queue{
+		channel:         c,
+		name:            name,
+		Instrumentation: c.Instrumentation,
+	}
+
+	c.mu.Lock()
+	c.queues = append(c.queues, q)
+	c.mu.Unlock()
+
+	return q, nil
+}
+
+// declare declares a queue along with the queues holding its failed deliveries.
+func declare(ch *amqp.Channel, name string) error {
+	_, err := ch.QueueDeclare(
 		name,  // name
 		true,  // durable
 		false, // delete when unused
@@ -35,18 +326,52 @@ func (c *Channel) Queue(ctx context.Context, name string) (*Queue, error) {
 		},
 	)
 	if err != nil {
-		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return nil, err
+		return err
 	}
 
-	return &Queue{
-		channel:         c,
-		name:            name,
-		Instrumentation: c.Instrumentation,
-	}, nil
+	return declareRetryQueues(ch, name)
+}
+
+// declareRetryQueues declares the queues holding failed deliveries for a queue.
+func declareRetryQueues(ch *amqp.Channel, name string) error {
+	// Messages in the retry queue are routed back to the original queue on expiry.
+	_, err := ch.QueueDeclare(
+		retryName(name), // name
+		true,            // durable
+		false,           // delete when unused
+		false,           // exclusive
+		false,           // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange":    "",   // Default exchange
+			"x-dead-letter-routing-key": name, // Original queue
+			"x-queue-mode":              "lazy",
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Dead-lettered messages are kept until requeued.
+	_, err = ch.QueueDeclare(
+		deadName(name), // name
+		true,           // durable
+		false,          // delete when unused
+		false,          // exclusive
+		false,          // no-wait
+		amqp.Table{
+			"x-queue-mode": "lazy",
+		},
+	)
+
+	return err
 }
 
 // Close closes a Channel
 func (c *Channel) Close() error {
-	return c.ch.Close()
+	c.mu.Lock()
+	c.closed = true
+	ch := c.ch
+	c.mu.Unlock()
+
+	return ch.Close()
 }
@@ -3,6 +3,7 @@ package amqp
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/streadway/amqp"
@@ -13,10 +14,17 @@ import (
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
-// Connection wraps an AMQP connection
+// Connection wraps an AMQP connection, transparently reconnecting when the connection is lost.
+// Channels created from it recover their queues and consumers after reconnecting.
 type Connection struct {
-	config *Config
+	config     *Config
+	amqpConfig *amqp.Config
+
+	mu     sync.Mutex
 	conn   *amqp.Connection
+	ready  chan struct{} // Closed when conn is usable, replaced when the connection is lost.
+	closed bool
+
 	*instr.Instrumentation
 }
 
@@ -25,27 +33,74 @@ func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i
 	ctx, span := i.Tracer.Start(ctx, "queue.amqp.NewConnection", trace.WithAttributes(label.String("amqp_url", cfg.URL)))
 	defer span.End()
 
-	amqpConn, err := amqp.DialConfig(cfg.URL, *amqpConfig)
+	c := &Connection{
+		config:          cfg,
+		amqpConfig:      amqpConfig,
+		ready:           make(chan struct{}),
+		Instrumentation: i,
+	}
 
+	amqpConn, err := c.dial()
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return nil, err
 	}
 
-	c := &Connection{
-		config:          cfg,
-		conn:            amqpConn,
-		Instrumentation: i,
+	c.setConn(amqpConn)
+
+	go c.monitor(ctx, amqpConn)
+
+	return c, nil
+}
+
+// dial connects to the server using the configured dialer.
+func (c *Connection) dial() (*amqp.Connection, error) {
+	return amqp.DialConfig(c.config.URL, *c.amqpConfig)
+}
+
+// setConn makes conn available to channels.
+func (c *Connection) setConn(conn *amqp.Connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conn = conn
+	close(c.ready)
+}
+
+// reconnect dials until a new connection is made, giving up after MaxReconnect attempts.
+func (c *Connection) reconnect(ctx context.Context) (*amqp.Connection, error) {
+	var err error
+
+	for attempt := 0; attempt <= c.config.MaxReconnect; attempt++ {
+		log.Printf("AMQP connection lost, attempting reconnect in %s", c.config.ReconnectTime)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.config.ReconnectTime):
+		}
+
+		var conn *amqp.Connection
+		if conn, err = c.dial(); err == nil {
+			return conn, nil
+		}
+
+		log.Printf("Error connecting to AMQP: %v", err)
 	}
 
-	blockChan := amqpConn.NotifyBlocked(make(chan amqp.Blocking, 1))
-	closeChan := amqpConn.NotifyClose(make(chan *amqp.Error, 1))
+	return nil, err
+}
+
+// monitor handles blocking and loss of the connection, reconnecting until the connection is closed.
+func (c *Connection) monitor(ctx context.Context, conn *amqp.Connection) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.monitorConn", trace.WithAttributes(label.Stringer("connection", c)))
+	defer span.End()
 
-	monitorConn := func() {
-		ctx, span := i.Tracer.Start(ctx, "queue.amqp.monitorConn", trace.WithAttributes(label.Stringer("connection", c)))
-		defer span.End()
+	for {
+		blockChan := conn.NotifyBlocked(make(chan amqp.Blocking, 1))
+		closeChan := conn.NotifyClose(make(chan *amqp.Error, 1))
 
-		errCnt := 0
+	monitorLoop:
 		for {
 			select {
 			case <-ctx.Done():
@@ -62,62 +117,112 @@ func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i
 					span.AddEvent(ctx, "amqp-connection-unblocked")
 					log.Println("AMQP connection unblocked")
 				}
-			case err := <-closeChan:
+			case err, ok := <-closeChan:
+				if !ok {
+					// Graceful close.
+					return
+				}
+
 				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-				log.Printf("AMQP connection lost, attempting reconnect in %s", cfg.ReconnectTime)
-				time.Sleep(cfg.ReconnectTime)
-
-				amqpConn, amqpErr := amqp.Dial(cfg.URL)
-				if amqpErr != nil {
-					if errCnt > cfg.MaxReconnect {
-						// TODO: Proper error propagation/recovery
-						span.RecordError(ctx, amqpErr, trace.WithErrorStatus(codes.Error))
-						panic("Repeated AMQP reconnect errors")
-					} else {
-						errCnt++
-						log.Printf("Error connecting to AMQP: %v", amqpErr)
-						span.RecordError(ctx, amqpErr)
-					}
+				break monitorLoop
+			}
+		}
 
-				}
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return
+		}
+		c.ready = make(chan struct{})
+		c.mu.Unlock()
+
+		var err error
+		if conn, err = c.reconnect(ctx); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 
-				// Set new connection
-				c.conn = amqpConn
+			if ctx.Err() != nil {
+				return
 			}
+
+			// TODO: Proper error propagation/recovery
+			panic("Repeated AMQP reconnect errors")
 		}
+
+		log.Println("AMQP connection recovered")
+		span.AddEvent(ctx, "amqp-connection-recovered")
+
+		c.setConn(conn)
 	}
-	go monitorConn()
+}
 
-	return c, nil
+// current returns the underlying connection, waiting for reconnection when it is lost.
+func (c *Connection) current(ctx context.Context) (*amqp.Connection, error) {
+	c.mu.Lock()
+	ready, closed := c.ready, c.closed
+	c.mu.Unlock()
+
+	if closed {
+		return nil, amqp.ErrClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-ready:
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn, nil
+}
+
+// Ping returns an error when the connection is not usable by the time ctx is done, for example while reconnecting.
+func (c *Connection) Ping(ctx context.Context) error {
+	conn, err := c.current(ctx)
+	if err != nil {
+		return err
+	}
+
+	if conn.IsClosed() {
+		return amqp.ErrClosed
+	}
+
+	return nil
 }
 
 // Channel creates an AMQP channel
 func (c *Connection) Channel(ctx context.Context, prefetchCount int) (*Channel, error) {
-	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel")
+	return c.newChannel(ctx, prefetchCount, false)
+}
+
+// ConfirmChannel creates an AMQP channel in confirm mode, on which publishing waits for confirmation by the server.
+// Queues on the channel share a single publisher; publishing to them is serialized.
+func (c *Connection) ConfirmChannel(ctx context.Context) (*Channel, error) {
+	return c.newChannel(ctx, 1, true)
+}
+
+func (c *Connection) newChannel(ctx context.Context, prefetchCount int, confirm bool) (*Channel, error) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel", trace.WithAttributes(label.Bool("confirm", confirm)))
 	defer span.End()
 
-	// Create channel
-	ch, err := c.conn.Channel()
-	if err != nil {
-		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return nil, err
+	ch := &Channel{
+		conn:            c,
+		prefetchCount:   prefetchCount,
+		confirm:         confirm,
+		ready:           make(chan struct{}),
+		Instrumentation: c.Instrumentation,
 	}
 
-	// Set Qos
-	err = ch.Qos(
-		prefetchCount,
-		0,     // prefetch size
-		false, // global
-	)
+	amqpCh, closeChan, err := ch.open(ctx)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return nil, err
 	}
 
-	return &Channel{
-		ch:              ch,
-		Instrumentation: c.Instrumentation,
-	}, nil
+	ch.set(ctx, amqpCh, closeChan)
+
+	return ch, nil
 }
 
 // NewChannelQueue returns a new queue on a new channel
@@ -134,10 +239,18 @@ func (c *Connection) NewChannelQueue(ctx context.Context, name string, prefetchC
 }
 
 func (c *Connection) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.conn.LocalAddr().String()
 }
 
-// Close closes the channel
+// Close closes the connection, stopping recovery.
 func (c *Connection) Close() error {
-	return c.conn.Close()
+	c.mu.Lock()
+	c.closed = true
+	conn := c.conn
+	c.mu.Unlock()
+
+	return conn.Close()
 }
@@ -0,0 +1,40 @@
+package amqp
+
+import (
+	"github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// delivery adapts an AMQP delivery to the queue.Delivery interface.
+type delivery struct {
+	d amqp.Delivery
+}
+
+// Body returns the message body.
+func (d *delivery) Body() []byte {
+	return d.d.Body
+}
+
+// Headers returns the message headers.
+func (d *delivery) Headers() map[string]interface{} {
+	return d.d.Headers
+}
+
+// Priority returns the message priority.
+func (d *delivery) Priority() uint8 {
+	return d.d.Priority
+}
+
+// Ack acknowledges the delivery.
+func (d *delivery) Ack() error {
+	return d.d.Ack(false)
+}
+
+// Nack rejects the delivery, requeueing it when requeue is true.
+func (d *delivery) Nack(requeue bool) error {
+	return d.d.Nack(false, requeue)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Delivery = &delivery{}
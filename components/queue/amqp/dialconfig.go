@@ -0,0 +1,44 @@
+package amqp
+
+import (
+	"errors"
+	"net"
+
+	"github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// ErrUnsupportedCredentials is returned for credentials other than a username and password.
+var ErrUnsupportedCredentials = errors.New("AMQP only supports username and password credentials")
+
+// DialConfig returns a connection configuration dialing with dial. TLS settings apply to amqps:// URLs and
+// credentials, when specified, replace those from the URL.
+func DialConfig(dial func(network, addr string) (net.Conn, error), tlsConfig *utils.TLSConfig, credentials *utils.Credentials) (*amqp.Config, error) {
+	tlsClientConfig, err := tlsConfig.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := credentials.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &amqp.Config{
+		Dial:            dial,
+		TLSClientConfig: tlsClientConfig,
+	}
+
+	if loaded != nil {
+		if !loaded.IsBasic() {
+			return nil, ErrUnsupportedCredentials
+		}
+
+		cfg.SASL = []amqp.Authentication{
+			&amqp.PlainAuth{Username: loaded.Username, Password: loaded.Password},
+		}
+	}
+
+	return cfg, nil
+}
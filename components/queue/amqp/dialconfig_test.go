@@ -0,0 +1,44 @@
+package amqp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+type DialConfigTestSuite struct {
+	suite.Suite
+}
+
+func (s *DialConfigTestSuite) TestDefaults() {
+	cfg, err := DialConfig(net.Dial, &utils.TLSConfig{}, &utils.Credentials{})
+	s.NoError(err)
+	s.NotNil(cfg.Dial)
+	s.Nil(cfg.TLSClientConfig)
+	s.Empty(cfg.SASL)
+}
+
+func (s *DialConfigTestSuite) TestTLS() {
+	cfg, err := DialConfig(net.Dial, &utils.TLSConfig{ServerName: "rabbitmq"}, nil)
+	s.NoError(err)
+	s.Equal("rabbitmq", cfg.TLSClientConfig.ServerName)
+}
+
+func (s *DialConfigTestSuite) TestBasicCredentials() {
+	cfg, err := DialConfig(net.Dial, nil, &utils.Credentials{Username: "user", Password: "pass"})
+	s.NoError(err)
+	s.Equal([]amqp.Authentication{&amqp.PlainAuth{Username: "user", Password: "pass"}}, cfg.SASL)
+}
+
+func (s *DialConfigTestSuite) TestUnsupportedCredentials() {
+	_, err := DialConfig(net.Dial, nil, &utils.Credentials{BearerToken: "token"})
+	s.Equal(ErrUnsupportedCredentials, err)
+}
+
+func TestDialConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(DialConfigTestSuite))
+}
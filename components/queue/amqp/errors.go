@@ -0,0 +1,31 @@
+package amqp
+
+import (
+	"errors"
+
+	"github.com/streadway/amqp"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// ErrNotConfirmed is returned when the server rejects a publishing in confirm mode.
+var ErrNotConfirmed = errors.New("publishing not confirmed by server")
+
+// classify annotates errors from AMQP with their error class; recoverable errors are transient,
+// others (e.g. closed channels or connections) imply the server is unavailable.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrNotConfirmed) {
+		return t.Transient(err)
+	}
+
+	var e *amqp.Error
+	if errors.As(err, &e) && e.Recover {
+		return t.Transient(err)
+	}
+
+	return t.BackendUnavailable(err)
+}
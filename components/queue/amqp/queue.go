@@ -3,6 +3,10 @@ package amqp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
 
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel/api/trace"
@@ -11,12 +15,22 @@ import (
 
 	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+// consumerSeq is used to generate unique consumer tags.
+var consumerSeq uint64
+
 // Queue wraps an channel/queue for tasks
 type Queue struct {
 	name    string
 	channel *Channel
+
+	mu        sync.Mutex
+	consumer  string                      // Consumer tag, set by Consume.
+	sources   chan (<-chan amqp.Delivery) // Deliveries from resubscribed consumers.
+	cancelled chan struct{}               // Closed by Cancel.
+
 	*instr.Instrumentation
 }
 
@@ -25,6 +39,11 @@ func (q *Queue) String() string {
 	return q.name
 }
 
+// SetPrefetch changes the number of unacknowledged deliveries on the queue's channel.
+func (q *Queue) SetPrefetch(ctx context.Context, count int) error {
+	return q.channel.SetPrefetch(ctx, count)
+}
+
 // Publish adds a task with specified params to the Queue
 // priority: higher number, higher priority
 // TODO: Add context parameter, allow for timeouts etc
@@ -39,50 +58,183 @@ func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8)
 	body, err := json.Marshal(params)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return err
+		return t.Permanent(err)
 	}
 
-	err = q.channel.ch.Publish(
-		"",     // exchange
-		q.name, // routing key
-		true,   // mandatory
-		false,  // immediate
-		amqp.Publishing{
-			DeliveryMode: amqp.Transient,
-			ContentType:  "application/json",
-			Body:         body,
-			Priority:     priority,
-		})
+	err = q.channel.publish(ctx, q.name, amqp.Publishing{
+		DeliveryMode: amqp.Transient,
+		ContentType:  "application/json",
+		Headers:      queue.TraceHeaders(ctx),
+		Body:         body,
+		Priority:     priority,
+	})
 
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 	}
 
-	return err
+	return classify(err)
 }
 
-// Consume consumes messages from a queue
-func (q *Queue) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
+// Consume consumes messages from a queue. The returned channel keeps delivering after the underlying channel
+// has been recovered, until Cancel is called.
+func (q *Queue) Consume(ctx context.Context) (<-chan queue.Delivery, error) {
 	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Consume")
 	defer span.End()
 
-	c, err := q.channel.ch.Consume(
-		q.name, // queue
-		"",     // consumer
-		false,  // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
+	ch, err := q.channel.current(ctx)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
 
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.consumer = fmt.Sprintf("ipfs-search-%d-%d", os.Getpid(), atomic.AddUint64(&consumerSeq, 1))
+	q.sources = make(chan (<-chan amqp.Delivery), 1)
+	q.cancelled = make(chan struct{})
+
+	src, err := q.subscribe(ch)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return nil, err
 	}
 
-	return c, err
+	out := make(chan queue.Delivery)
+	go q.forward(ctx, src, q.sources, q.cancelled, out)
+
+	return out, nil
+}
+
+// subscribe starts consuming on ch with the current consumer tag.
+func (q *Queue) subscribe(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+	return ch.Consume(
+		q.name,     // queue
+		q.consumer, // consumer
+		false,      // auto-ack
+		false,      // exclusive
+		false,      // no-local
+		false,      // no-wait
+		nil,        // args
+	)
+}
+
+// resubscribe resumes consuming on a recovered channel, if the queue was being consumed.
+func (q *Queue) resubscribe(ch *amqp.Channel) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.sources == nil || isClosed(q.cancelled) {
+		return nil
+	}
+
+	src, err := q.subscribe(ch)
+	if err != nil {
+		return err
+	}
+
+	// Replace a source which has not yet been picked up; it has been closed with its channel.
+	select {
+	case <-q.sources:
+	default:
+	}
+
+	q.sources <- src
+
+	return nil
+}
+
+// renew replaces the consumer of a consuming queue on ch, such that it gets the channel's current prefetch count.
+// Deliveries received by the old consumer are forwarded before those of the new one and remain to be settled.
+func (q *Queue) renew(ch *amqp.Channel) error {
+	q.mu.Lock()
+	consuming := q.sources != nil && !isClosed(q.cancelled)
+	consumer := q.consumer
+	q.mu.Unlock()
+
+	if !consuming {
+		return nil
+	}
+
+	// Closes the source of the old consumer once its buffered deliveries have been forwarded.
+	if err := ch.Cancel(consumer, false); err != nil {
+		return err
+	}
+
+	return q.resubscribe(ch)
+}
+
+// forward copies deliveries from src to out, switching to new sources after resubscribing. Out is closed
+// when consumption has been cancelled and the last source is drained.
+func (q *Queue) forward(ctx context.Context, src <-chan amqp.Delivery, sources <-chan (<-chan amqp.Delivery), cancelled <-chan struct{}, out chan<- queue.Delivery) {
+	for {
+		for d := range src {
+			select {
+			case out <- &delivery{d}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// Source closed: either cancelled or the channel was lost.
+		if isClosed(cancelled) {
+			close(out)
+			return
+		}
+
+		select {
+		case <-cancelled:
+			close(out)
+			return
+		case src = <-sources:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// isClosed returns whether c has been closed, without blocking.
+func isClosed(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+// Cancel stops consuming messages from the queue; the channel returned by Consume is closed once the
+// server has stopped delivering. Deliveries received before remain to be acknowledged or rejected.
+func (q *Queue) Cancel(ctx context.Context) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Cancel")
+	defer span.End()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.consumer == "" || isClosed(q.cancelled) {
+		// Not consuming.
+		return nil
+	}
+
+	close(q.cancelled)
+
+	ch, ok := q.channel.available()
+	if !ok {
+		// Channel lost; its consumers are gone and will not be resubscribed.
+		return nil
+	}
+
+	err := ch.Cancel(q.consumer, false)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
 }
 
 // Compile-time assurance that implementation satisfies interface.
 var _ queue.Queue = &Queue{}
+var _ queue.Retrier = &Queue{}
+var _ queue.Prefetcher = &Queue{}
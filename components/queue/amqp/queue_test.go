@@ -0,0 +1,138 @@
+package amqp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testTimeout = time.Second
+
+type QueueTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cancel func()
+
+	sources   chan (<-chan amqp.Delivery)
+	cancelled chan struct{}
+	out       chan queue.Delivery
+	done      chan struct{}
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithTimeout(context.Background(), testTimeout)
+	s.sources = make(chan (<-chan amqp.Delivery), 1)
+	s.cancelled = make(chan struct{})
+	s.out = make(chan queue.Delivery)
+	s.done = make(chan struct{})
+}
+
+func (s *QueueTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+// startForward forwards from src in the background, closing s.done when forwarding stops.
+func (s *QueueTestSuite) startForward(src <-chan amqp.Delivery) {
+	q := &Queue{}
+
+	go func() {
+		q.forward(s.ctx, src, s.sources, s.cancelled, s.out)
+		close(s.done)
+	}()
+}
+
+func (s *QueueTestSuite) receive() amqp.Delivery {
+	select {
+	case d, ok := <-s.out:
+		s.True(ok)
+		return d.(*delivery).d
+	case <-time.After(testTimeout):
+		s.FailNow("timeout waiting for delivery")
+	}
+
+	return amqp.Delivery{}
+}
+
+func (s *QueueTestSuite) waitDone() {
+	select {
+	case <-s.done:
+	case <-time.After(testTimeout):
+		s.FailNow("timeout waiting for forward to return")
+	}
+}
+
+func (s *QueueTestSuite) TestForwardResubscribed() {
+	src := make(chan amqp.Delivery, 1)
+	s.startForward(src)
+
+	src <- amqp.Delivery{DeliveryTag: 1}
+	s.Equal(uint64(1), s.receive().DeliveryTag)
+
+	// Channel lost, then recovered.
+	close(src)
+	resubscribed := make(chan amqp.Delivery, 1)
+	s.sources <- resubscribed
+
+	resubscribed <- amqp.Delivery{DeliveryTag: 2}
+	s.Equal(uint64(2), s.receive().DeliveryTag)
+}
+
+func (s *QueueTestSuite) TestForwardCancelled() {
+	src := make(chan amqp.Delivery, 1)
+	s.startForward(src)
+
+	src <- amqp.Delivery{DeliveryTag: 1}
+	close(s.cancelled)
+	close(src)
+
+	// Pending deliveries are forwarded before closing.
+	s.Equal(uint64(1), s.receive().DeliveryTag)
+
+	s.waitDone()
+
+	_, ok := <-s.out
+	s.False(ok)
+}
+
+func (s *QueueTestSuite) TestForwardContextDone() {
+	src := make(chan amqp.Delivery)
+	s.startForward(src)
+
+	close(src)
+	s.cancel()
+
+	s.waitDone()
+
+	// Out is left open as consumption has not been cancelled.
+	select {
+	case <-s.out:
+		s.Fail("out should not be closed")
+	default:
+	}
+}
+
+func (s *QueueTestSuite) TestCancelNotConsuming() {
+	q := &Queue{Instrumentation: instr.New()}
+	s.NoError(q.Cancel(s.ctx))
+}
+
+func (s *QueueTestSuite) TestResubscribeNotConsuming() {
+	q := &Queue{}
+	s.NoError(q.resubscribe(nil))
+}
+
+func (s *QueueTestSuite) TestRenewNotConsuming() {
+	q := &Queue{}
+	s.NoError(q.renew(nil))
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueTestSuite))
+}
@@ -0,0 +1,148 @@
+package amqp
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// retryName returns the name of the queue holding deliveries to be retried after a delay.
+func retryName(name string) string {
+	return name + ".retry"
+}
+
+// deadName returns the name of the queue holding dead-lettered deliveries.
+func deadName(name string) string {
+	return name + ".dead"
+}
+
+// republishing returns a Publishing with the body and priority of a delivery and the given headers, with
+// properties matching Publish.
+func republishing(d queue.Delivery, headers map[string]interface{}) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:      headers,
+		DeliveryMode: amqp.Transient,
+		ContentType:  "application/json",
+		Priority:     d.Priority(),
+		Body:         d.Body(),
+	}
+}
+
+// republish publishes p to the queue named key and acknowledges d; d is only acknowledged when publishing succeeded.
+func (q *Queue) republish(ctx context.Context, key string, d queue.Delivery, p amqp.Publishing) error {
+	if err := q.channel.publish(ctx, key, p); err != nil {
+		return err
+	}
+
+	return d.Ack()
+}
+
+// Retry publishes a failed delivery to be redelivered after delay, incrementing its attempt counter, and
+// acknowledges the original delivery.
+//
+// Note that RabbitMQ only expires messages at the head of a queue; a delivery will not be retried before
+// deliveries in the retry queue with a longer delay which were published before it.
+func (q *Queue) Retry(ctx context.Context, d queue.Delivery, delay time.Duration) error {
+	attempts := queue.Attempts(d) + 1
+
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Retry",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Int("attempts", attempts)),
+		trace.WithAttributes(label.String("delay", delay.String())),
+	)
+	defer span.End()
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = int32(attempts)
+
+	p := republishing(d, headers)
+	p.Expiration = strconv.FormatInt(delay.Milliseconds(), 10)
+
+	err := q.republish(ctx, retryName(q.name), d, p)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// DeadLetter publishes a failed delivery to the dead-letter queue, recording its cause, and acknowledges
+// the original delivery.
+func (q *Queue) DeadLetter(ctx context.Context, d queue.Delivery, cause error) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.DeadLetter",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = int32(queue.Attempts(d) + 1)
+	if cause != nil {
+		headers[queue.ErrorHeader] = cause.Error()
+	}
+
+	err := q.republish(ctx, deadName(q.name), d, republishing(d, headers))
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// RequeueDead moves all dead-lettered messages back to the queue, resetting their attempt counter.
+// It returns the number of requeued messages.
+func (q *Queue) RequeueDead(ctx context.Context) (int, error) {
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.RequeueDead",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	count := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		ch, err := q.channel.current(ctx)
+		if err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return count, err
+		}
+
+		msg, ok, err := ch.Get(deadName(q.name), false)
+		if err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return count, err
+		}
+
+		if !ok {
+			// Queue empty
+			return count, nil
+		}
+
+		d := &delivery{msg}
+
+		headers := queue.CopyHeaders(d)
+		delete(headers, queue.AttemptsHeader)
+		delete(headers, queue.ErrorHeader)
+
+		if err := q.republish(ctx, q.name, d, republishing(d, headers)); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+
+			if err := d.Nack(true); err != nil {
+				span.RecordError(ctx, err)
+			}
+
+			return count, err
+		}
+
+		count++
+	}
+}
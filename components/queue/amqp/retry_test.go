@@ -0,0 +1,45 @@
+package amqp
+
+import (
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+type RetryTestSuite struct {
+	suite.Suite
+}
+
+func (s *RetryTestSuite) TestRepublishing() {
+	d := &delivery{amqp.Delivery{
+		Headers:     amqp.Table{"other": "header"},
+		ContentType: "application/json",
+		Priority:    7,
+		Body:        []byte("{}"),
+	}}
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = int32(1)
+
+	p := republishing(d, headers)
+
+	s.Equal(d.d.Body, p.Body)
+	s.Equal(d.d.Priority, p.Priority)
+	s.Equal(d.d.ContentType, p.ContentType)
+	s.Equal(amqp.Table{"other": "header", queue.AttemptsHeader: int32(1)}, amqp.Table(p.Headers))
+
+	// Original headers untouched
+	s.NotContains(d.d.Headers, queue.AttemptsHeader)
+}
+
+func (s *RetryTestSuite) TestNames() {
+	s.Equal("hashes.retry", retryName("hashes"))
+	s.Equal("hashes.dead", deadName("hashes"))
+}
+
+func TestRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
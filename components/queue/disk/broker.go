@@ -0,0 +1,155 @@
+// Package disk implements persistent priority queues in an embedded database on local disk, allowing crawling
+// without a message broker.
+package disk
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// Broker holds queues stored in a single database file, sharing them between publishers and consumers
+// within a process.
+type Broker struct {
+	config *Config
+
+	mu sync.RWMutex // Held exclusively while compacting.
+	db *bolt.DB
+
+	queuesMu sync.Mutex
+	queues   map[string]*Queue
+
+	stop chan struct{}
+	done chan struct{}
+
+	*instr.Instrumentation
+}
+
+// Open opens or creates the database file and starts moving retried messages back to their queues when due.
+func Open(cfg *Config, i *instr.Instrumentation) (*Broker, error) {
+	db, err := bolt.Open(cfg.Path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Broker{
+		config:          cfg,
+		db:              db,
+		queues:          make(map[string]*Queue),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+		Instrumentation: i,
+	}
+
+	go b.run()
+
+	return b, nil
+}
+
+func (b *Broker) view(fn func(*bolt.Tx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.db.View(fn)
+}
+
+func (b *Broker) update(fn func(*bolt.Tx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.db.Update(fn)
+}
+
+// batch combines fn with concurrent writes in a single transaction; fn may be called more than once.
+func (b *Broker) batch(fn func(*bolt.Tx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.db.Batch(fn)
+}
+
+// Queue returns the queue with the given name, creating it when it does not exist yet. Messages delivered
+// but not acknowledged before the process exited are returned to the queue.
+func (b *Broker) Queue(name string) (*Queue, error) {
+	b.queuesMu.Lock()
+	defer b.queuesMu.Unlock()
+
+	if q, ok := b.queues[name]; ok {
+		return q, nil
+	}
+
+	q := newQueue(name, b)
+	if err := b.update(q.init); err != nil {
+		return nil, err
+	}
+
+	b.queues[name] = q
+
+	return q, nil
+}
+
+// run moves due messages back to their queues and compacts the database, until the broker is closed.
+func (b *Broker) run() {
+	defer close(b.done)
+
+	poll := time.NewTicker(b.config.PollInterval)
+	defer poll.Stop()
+
+	compact := time.NewTicker(b.config.CompactInterval)
+	defer compact.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case now := <-poll.C:
+			b.queuesMu.Lock()
+			queues := make([]*Queue, 0, len(b.queues))
+			for _, q := range b.queues {
+				queues = append(queues, q)
+			}
+			b.queuesMu.Unlock()
+
+			for _, q := range queues {
+				if err := q.promote(now); err != nil {
+					log.Printf("Error moving due messages to %s: %v", q, err)
+				}
+			}
+		case <-compact.C:
+			if err := b.compactIfNeeded(); err != nil {
+				log.Printf("Error compacting queues: %v", err)
+			}
+		}
+	}
+}
+
+// Close stops background processing and closes the database.
+func (b *Broker) Close() error {
+	close(b.stop)
+	<-b.done
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.db.Close()
+}
+
+// PublisherFactory automates creation of publishers for a queue on a Broker.
+type PublisherFactory struct {
+	Broker *Broker
+	Queue  string
+}
+
+// NewPublisher returns the named queue on the broker.
+func (f PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, error) {
+	return f.Broker.Queue(f.Queue)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.PublisherFactory = PublisherFactory{}
@@ -0,0 +1,150 @@
+package disk
+
+import (
+	"log"
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// compactTxSize is the number of keys copied per transaction while compacting.
+const compactTxSize = 10000
+
+// compactIfNeeded compacts the database when more than half of its file consists of free pages, as is the case
+// after a large backlog has been worked through.
+func (b *Broker) compactIfNeeded() error {
+	b.mu.RLock()
+	free := b.db.Stats().FreeAlloc
+	b.mu.RUnlock()
+
+	info, err := os.Stat(b.config.Path)
+	if err != nil {
+		return err
+	}
+
+	if int64(free) < info.Size()/2 {
+		return nil
+	}
+
+	log.Printf("Compacting queues; %d of %d bytes free", free, info.Size())
+
+	return b.Compact()
+}
+
+// Compact rewrites the database to a new file without free pages, replacing the original. Queue operations
+// block while compacting.
+func (b *Broker) Compact() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tmpPath := b.config.Path + ".compact"
+
+	dst, err := bolt.Open(tmpPath, 0600, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		return compactTx(tx, dst)
+	}); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, b.config.Path); err != nil {
+		return err
+	}
+
+	b.db, err = bolt.Open(b.config.Path, 0600, nil)
+
+	return err
+}
+
+// compactTx copies all buckets in src to dst, committing every compactTxSize keys.
+func compactTx(src *bolt.Tx, dst *bolt.DB) error {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+
+	// Path of bucket names leading to the bucket being copied.
+	var path [][]byte
+	n := 0
+
+	// bucket returns the destination bucket for the current path in the current transaction.
+	bucket := func() (*bolt.Bucket, error) {
+		b, err := tx.CreateBucketIfNotExists(path[0])
+		for _, name := range path[1:] {
+			if err != nil {
+				break
+			}
+			b, err = b.CreateBucketIfNotExists(name)
+		}
+
+		return b, err
+	}
+
+	var copyBucket func(src *bolt.Bucket) error
+	copyBucket = func(src *bolt.Bucket) error {
+		dstBucket, err := bucket()
+		if err != nil {
+			return err
+		}
+
+		if err := dstBucket.SetSequence(src.Sequence()); err != nil {
+			return err
+		}
+
+		return src.ForEach(func(k, v []byte) error {
+			if v == nil {
+				// Nested bucket.
+				path = append(path, k)
+				if err := copyBucket(src.Bucket(k)); err != nil {
+					return err
+				}
+				path = path[:len(path)-1]
+
+				// The transaction may have been committed while copying the nested bucket.
+				dstBucket, err = bucket()
+				return err
+			}
+
+			if n++; n%compactTxSize == 0 {
+				if err := tx.Commit(); err != nil {
+					return err
+				}
+
+				if tx, err = dst.Begin(true); err != nil {
+					return err
+				}
+
+				if dstBucket, err = bucket(); err != nil {
+					return err
+				}
+			}
+
+			return dstBucket.Put(k, v)
+		})
+	}
+
+	err = src.ForEach(func(name []byte, b *bolt.Bucket) error {
+		path = [][]byte{name}
+		return copyBucket(b)
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
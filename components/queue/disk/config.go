@@ -0,0 +1,21 @@
+package disk
+
+import (
+	"time"
+)
+
+// Config specifies the configuration for disk-backed queues.
+type Config struct {
+	Path            string        // File in which queues are stored.
+	PollInterval    time.Duration // Interval at which retried messages are checked for being due.
+	CompactInterval time.Duration // Interval at which the file is checked for compaction.
+}
+
+// DefaultConfig generates a default configuration for disk-backed queues.
+func DefaultConfig() *Config {
+	return &Config{
+		Path:            "queues.db",
+		PollInterval:    time.Second,
+		CompactInterval: 10 * time.Minute,
+	}
+}
@@ -0,0 +1,46 @@
+package disk
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// delivery is a message delivered to a consumer, to be acknowledged or rejected exactly once.
+type delivery struct {
+	q       *Queue
+	key     []byte // Key in the unacked bucket.
+	m       *message
+	settled int32 // Set atomically when acknowledged or rejected.
+}
+
+// Body returns the message body.
+func (d *delivery) Body() []byte {
+	return d.m.Body
+}
+
+// Headers returns the message headers.
+func (d *delivery) Headers() map[string]interface{} {
+	return d.m.Headers
+}
+
+// Priority returns the message priority.
+func (d *delivery) Priority() uint8 {
+	return d.m.Priority
+}
+
+// Ack acknowledges the delivery, removing it from the queue.
+func (d *delivery) Ack() error {
+	return d.q.settle(d, d.q.ack)
+}
+
+// Nack rejects the delivery. When requeue is true it is returned to its original position in the queue,
+// otherwise it is dropped.
+func (d *delivery) Nack(requeue bool) error {
+	if requeue {
+		return d.q.settle(d, d.q.requeue)
+	}
+
+	return d.q.settle(d, d.q.ack)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Delivery = &delivery{}
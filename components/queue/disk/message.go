@@ -0,0 +1,75 @@
+package disk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"time"
+)
+
+// MaxPriority is the highest message priority; messages published with a higher priority get MaxPriority.
+const MaxPriority = 9
+
+// Buckets within the bucket of a queue.
+var (
+	pendingBucket = []byte("pending") // Messages to be delivered, by priority and sequence.
+	unackedBucket = []byte("unacked") // Delivered messages which have not been acknowledged, by pending key.
+	delayedBucket = []byte("delayed") // Messages to be retried, by due time and sequence.
+	deadBucket    = []byte("dead")    // Dead-lettered messages, by sequence.
+)
+
+// message is a message stored in a queue.
+type message struct {
+	Body     []byte
+	Headers  map[string]interface{}
+	Priority uint8
+}
+
+func (m *message) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeMessage(v []byte) (*message, error) {
+	m := new(message)
+	if err := gob.NewDecoder(bytes.NewReader(v)).Decode(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// pendingKey orders pending messages by descending priority, then ascending sequence.
+func pendingKey(priority uint8, seq uint64) []byte {
+	k := make([]byte, 9)
+	k[0] = MaxPriority - priority
+	binary.BigEndian.PutUint64(k[1:], seq)
+
+	return k
+}
+
+// delayedKey orders delayed messages by due time.
+func delayedKey(due time.Time, seq uint64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uint64(due.UnixNano()))
+	binary.BigEndian.PutUint64(k[8:], seq)
+
+	return k
+}
+
+// delayedDue returns the time a delayed message is due from its key.
+func delayedDue(k []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(k)))
+}
+
+// seqKey returns a key ordered by sequence only.
+func seqKey(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+
+	return k
+}
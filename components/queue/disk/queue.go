@@ -0,0 +1,520 @@
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// requeueTxSize is the number of dead-lettered messages requeued per transaction.
+const requeueTxSize = 1000
+
+var (
+	// ErrSettled is returned when acknowledging or rejecting a delivery which has already been acknowledged or rejected.
+	ErrSettled = errors.New("delivery already acknowledged or rejected")
+
+	// ErrForeignDelivery is returned when retrying or dead-lettering a delivery from a different queue.
+	ErrForeignDelivery = errors.New("delivery not from this queue")
+)
+
+// Queue is a persistent priority queue. Messages are delivered at least once: deliveries which have not been
+// acknowledged when the process exits are delivered again after restarting.
+type Queue struct {
+	name   string
+	broker *Broker
+
+	mu      sync.Mutex
+	changed chan struct{} // Closed and replaced whenever messages become available.
+
+	cancelOnce sync.Once
+	cancelled  chan struct{} // Closed by Cancel.
+}
+
+func newQueue(name string, b *Broker) *Queue {
+	return &Queue{
+		name:      name,
+		broker:    b,
+		changed:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+}
+
+// String returns the name of the queue
+func (q *Queue) String() string {
+	return q.name
+}
+
+// bucket returns the bucket holding the queue.
+func (q *Queue) bucket(tx *bolt.Tx) *bolt.Bucket {
+	return tx.Bucket([]byte(q.name))
+}
+
+// init creates the buckets for the queue and returns unacknowledged messages to the pending messages.
+func (q *Queue) init(tx *bolt.Tx) error {
+	qb, err := tx.CreateBucketIfNotExists([]byte(q.name))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range [][]byte{pendingBucket, unackedBucket, delayedBucket, deadBucket} {
+		if _, err := qb.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	pending, unacked := qb.Bucket(pendingBucket), qb.Bucket(unackedBucket)
+
+	// Unacked messages retain their pending key.
+	if err := unacked.ForEach(func(k, v []byte) error {
+		return pending.Put(k, v)
+	}); err != nil {
+		return err
+	}
+
+	if err := qb.DeleteBucket(unackedBucket); err != nil {
+		return err
+	}
+
+	_, err = qb.CreateBucket(unackedBucket)
+
+	return err
+}
+
+// waitChan returns a channel which is closed when messages become available.
+func (q *Queue) waitChan() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.changed
+}
+
+// notify wakes up waiting consumers.
+func (q *Queue) notify() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// putPending adds m to the pending messages with a new sequence number.
+func putPending(qb *bolt.Bucket, m *message) error {
+	seq, err := qb.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	v, err := m.encode()
+	if err != nil {
+		return err
+	}
+
+	return qb.Bucket(pendingBucket).Put(pendingKey(m.Priority, seq), v)
+}
+
+// Len returns the number of messages pending delivery.
+func (q *Queue) Len() (n int, err error) {
+	err = q.broker.view(func(tx *bolt.Tx) error {
+		n = q.bucket(tx).Bucket(pendingBucket).Stats().KeyN
+		return nil
+	})
+
+	return
+}
+
+// Publish adds a task with specified params to the Queue
+// priority: higher number, higher priority
+func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	ctx, span := q.broker.Tracer.Start(ctx, "queue.disk.Publish",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Any("params", params)),
+		trace.WithAttributes(label.Uint("priority", uint(priority))),
+	)
+	defer span.End()
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return t.Permanent(err)
+	}
+
+	if priority > MaxPriority {
+		priority = MaxPriority
+	}
+
+	m := &message{Body: body, Headers: queue.TraceHeaders(ctx), Priority: priority}
+
+	err = q.broker.batch(func(tx *bolt.Tx) error {
+		return putPending(q.bucket(tx), m)
+	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	q.notify()
+
+	return nil
+}
+
+// next moves the oldest pending message with the highest priority to the unacknowledged messages and returns
+// it as a delivery, or nil when there are no pending messages.
+func (q *Queue) next() (d *delivery, err error) {
+	err = q.broker.update(func(tx *bolt.Tx) error {
+		qb := q.bucket(tx)
+		pending := qb.Bucket(pendingBucket)
+
+		k, v := pending.Cursor().First()
+		if k == nil {
+			return nil
+		}
+
+		m, err := decodeMessage(v)
+		if err != nil {
+			return err
+		}
+
+		key := append([]byte(nil), k...)
+		if err := qb.Bucket(unackedBucket).Put(key, append([]byte(nil), v...)); err != nil {
+			return err
+		}
+
+		if err := pending.Delete(key); err != nil {
+			return err
+		}
+
+		d = &delivery{q: q, key: key, m: m}
+
+		return nil
+	})
+
+	return
+}
+
+// receive waits for the next pending message, returning nil when ctx is done or the queue has been cancelled.
+func (q *Queue) receive(ctx context.Context) *delivery {
+	for {
+		// Get wait channel before checking for messages, so as to not miss notifications.
+		changed := q.waitChan()
+
+		d, err := q.next()
+		if err != nil {
+			log.Printf("Error receiving from %s, retrying in %s: %v", q, q.broker.config.PollInterval, err)
+			changed = nil
+		}
+
+		if d != nil {
+			return d
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.cancelled:
+			return nil
+		case <-changed:
+		case <-time.After(q.broker.config.PollInterval):
+		}
+	}
+}
+
+// Consume returns a channel delivering messages in order of priority, which is closed when ctx is done or
+// after Cancel.
+func (q *Queue) Consume(ctx context.Context) (<-chan queue.Delivery, error) {
+	out := make(chan queue.Delivery)
+
+	go func() {
+		defer close(out)
+
+		for {
+			d := q.receive(ctx)
+			if d == nil {
+				return
+			}
+
+			select {
+			case out <- d:
+			case <-ctx.Done():
+				d.Nack(true)
+				return
+			case <-q.cancelled:
+				d.Nack(true)
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Cancel stops consuming; channels returned by Consume are closed. Deliveries received before remain to be
+// acknowledged or rejected.
+func (q *Queue) Cancel(ctx context.Context) error {
+	q.cancelOnce.Do(func() {
+		close(q.cancelled)
+	})
+
+	return nil
+}
+
+// settle calls f for an unsettled delivery within a transaction, marking it settled and waking up consumers
+// when f succeeds.
+func (q *Queue) settle(d *delivery, f func(qb *bolt.Bucket, d *delivery) error) error {
+	if !atomic.CompareAndSwapInt32(&d.settled, 0, 1) {
+		return ErrSettled
+	}
+
+	err := q.broker.batch(func(tx *bolt.Tx) error {
+		return f(q.bucket(tx), d)
+	})
+	if err != nil {
+		atomic.StoreInt32(&d.settled, 0)
+		return err
+	}
+
+	q.notify()
+
+	return nil
+}
+
+// ack removes a delivered message.
+func (q *Queue) ack(qb *bolt.Bucket, d *delivery) error {
+	return qb.Bucket(unackedBucket).Delete(d.key)
+}
+
+// requeue returns a delivered message to its original position.
+func (q *Queue) requeue(qb *bolt.Bucket, d *delivery) error {
+	v, err := d.m.encode()
+	if err != nil {
+		return err
+	}
+
+	if err := qb.Bucket(pendingBucket).Put(d.key, v); err != nil {
+		return err
+	}
+
+	return qb.Bucket(unackedBucket).Delete(d.key)
+}
+
+// own returns d as a delivery of this queue.
+func (q *Queue) own(d queue.Delivery) (*delivery, error) {
+	if d, ok := d.(*delivery); ok && d.q == q {
+		return d, nil
+	}
+
+	return nil, ErrForeignDelivery
+}
+
+// Retry acknowledges a failed delivery and requeues it after delay, incrementing its attempt counter.
+func (q *Queue) Retry(ctx context.Context, d queue.Delivery, delay time.Duration) error {
+	attempts := queue.Attempts(d) + 1
+
+	ctx, span := q.broker.Tracer.Start(ctx, "queue.disk.Retry",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Int("attempts", attempts)),
+		trace.WithAttributes(label.String("delay", delay.String())),
+	)
+	defer span.End()
+
+	own, err := q.own(d)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = attempts
+
+	m := &message{Body: own.m.Body, Headers: headers, Priority: own.m.Priority}
+	due := time.Now().Add(delay)
+
+	err = q.settle(own, func(qb *bolt.Bucket, d *delivery) error {
+		seq, err := qb.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		v, err := m.encode()
+		if err != nil {
+			return err
+		}
+
+		if err := qb.Bucket(delayedBucket).Put(delayedKey(due, seq), v); err != nil {
+			return err
+		}
+
+		return q.ack(qb, d)
+	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// promote moves delayed messages which are due at now to the pending messages.
+func (q *Queue) promote(now time.Time) error {
+	moved := 0
+
+	err := q.broker.update(func(tx *bolt.Tx) error {
+		qb := q.bucket(tx)
+		delayed := qb.Bucket(delayedBucket)
+
+		var due [][]byte
+
+		c := delayed.Cursor()
+		for k, _ := c.First(); k != nil && !delayedDue(k).After(now); k, _ = c.Next() {
+			due = append(due, append([]byte(nil), k...))
+		}
+
+		for _, k := range due {
+			m, err := decodeMessage(delayed.Get(k))
+			if err != nil {
+				return err
+			}
+
+			if err := putPending(qb, m); err != nil {
+				return err
+			}
+
+			if err := delayed.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		moved = len(due)
+
+		return nil
+	})
+
+	if moved > 0 {
+		q.notify()
+	}
+
+	return err
+}
+
+// DeadLetter acknowledges a failed delivery and moves it to the dead-lettered messages, recording its cause.
+func (q *Queue) DeadLetter(ctx context.Context, d queue.Delivery, cause error) error {
+	ctx, span := q.broker.Tracer.Start(ctx, "queue.disk.DeadLetter",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	own, err := q.own(d)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = queue.Attempts(d) + 1
+	if cause != nil {
+		headers[queue.ErrorHeader] = cause.Error()
+	}
+
+	m := &message{Body: own.m.Body, Headers: headers, Priority: own.m.Priority}
+
+	err = q.settle(own, func(qb *bolt.Bucket, d *delivery) error {
+		seq, err := qb.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		v, err := m.encode()
+		if err != nil {
+			return err
+		}
+
+		if err := qb.Bucket(deadBucket).Put(seqKey(seq), v); err != nil {
+			return err
+		}
+
+		return q.ack(qb, d)
+	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// RequeueDead moves all dead-lettered messages back to the queue, resetting their attempt counter.
+// It returns the number of requeued messages.
+func (q *Queue) RequeueDead(ctx context.Context) (int, error) {
+	ctx, span := q.broker.Tracer.Start(ctx, "queue.disk.RequeueDead",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	count := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		n := 0
+
+		err := q.broker.update(func(tx *bolt.Tx) error {
+			qb := q.bucket(tx)
+			dead := qb.Bucket(deadBucket)
+
+			var keys [][]byte
+
+			c := dead.Cursor()
+			for k, _ := c.First(); k != nil && len(keys) < requeueTxSize; k, _ = c.Next() {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+
+			for _, k := range keys {
+				m, err := decodeMessage(dead.Get(k))
+				if err != nil {
+					return err
+				}
+
+				delete(m.Headers, queue.AttemptsHeader)
+				delete(m.Headers, queue.ErrorHeader)
+
+				if err := putPending(qb, m); err != nil {
+					return err
+				}
+
+				if err := dead.Delete(k); err != nil {
+					return err
+				}
+			}
+
+			n = len(keys)
+
+			return nil
+		})
+		if err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return count, err
+		}
+
+		if n == 0 {
+			return count, nil
+		}
+
+		count += n
+		q.notify()
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Queue = &Queue{}
+var _ queue.Retrier = &Queue{}
@@ -0,0 +1,248 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testTimeout = 5 * time.Second
+
+type QueueTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cancel func()
+
+	cfg *Config
+	b   *Broker
+	q   *Queue
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithTimeout(context.Background(), testTimeout)
+
+	s.cfg = &Config{
+		Path:            filepath.Join(s.T().TempDir(), "queues.db"),
+		PollInterval:    5 * time.Millisecond,
+		CompactInterval: time.Hour,
+	}
+
+	s.open()
+}
+
+func (s *QueueTestSuite) TearDownTest() {
+	s.cancel()
+	s.NoError(s.b.Close())
+}
+
+// open opens the broker and test queue.
+func (s *QueueTestSuite) open() {
+	var err error
+
+	s.b, err = Open(s.cfg, instr.New())
+	s.Require().NoError(err)
+
+	s.q, err = s.b.Queue("test")
+	s.Require().NoError(err)
+}
+
+// restart closes and reopens the broker, as if the process was restarted.
+func (s *QueueTestSuite) restart() {
+	s.Require().NoError(s.b.Close())
+	s.open()
+}
+
+func (s *QueueTestSuite) consume() <-chan queue.Delivery {
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	return c
+}
+
+func (s *QueueTestSuite) receive(c <-chan queue.Delivery) queue.Delivery {
+	select {
+	case d, ok := <-c:
+		s.Require().True(ok)
+		return d
+	case <-time.After(testTimeout):
+		s.FailNow("timeout waiting for delivery")
+	}
+
+	return nil
+}
+
+func (s *QueueTestSuite) len() int {
+	n, err := s.q.Len()
+	s.NoError(err)
+
+	return n
+}
+
+func (s *QueueTestSuite) TestPriorityOrder() {
+	s.NoError(s.q.Publish(s.ctx, "low", 1))
+	s.NoError(s.q.Publish(s.ctx, "high", 9))
+	s.NoError(s.q.Publish(s.ctx, "overflow", 20))
+	s.Equal(3, s.len())
+
+	c := s.consume()
+
+	// Equal priorities are delivered in order of publishing.
+	d := s.receive(c)
+	s.Equal(`"high"`, string(d.Body()))
+	s.Equal(uint8(9), d.Priority())
+
+	d = s.receive(c)
+	s.Equal(`"overflow"`, string(d.Body()))
+	s.Equal(uint8(MaxPriority), d.Priority())
+
+	s.Equal(`"low"`, string(s.receive(c).Body()))
+}
+
+func (s *QueueTestSuite) TestConsumeWaitsForPublish() {
+	c := s.consume()
+
+	s.NoError(s.q.Publish(s.ctx, "later", 0))
+	s.Equal(`"later"`, string(s.receive(c).Body()))
+}
+
+func (s *QueueTestSuite) TestAckSettlesOnce() {
+	s.NoError(s.q.Publish(s.ctx, "ack", 0))
+
+	d := s.receive(s.consume())
+	s.NoError(d.Ack())
+	s.Equal(ErrSettled, d.Ack())
+	s.Equal(ErrSettled, d.Nack(true))
+}
+
+func (s *QueueTestSuite) TestNackRequeue() {
+	s.NoError(s.q.Publish(s.ctx, "requeued", 0))
+
+	c := s.consume()
+
+	d := s.receive(c)
+	s.NoError(d.Nack(true))
+
+	d = s.receive(c)
+	s.Equal(`"requeued"`, string(d.Body()))
+	s.NoError(d.Nack(false))
+}
+
+func (s *QueueTestSuite) TestUnackedRedeliveredAfterRestart() {
+	s.NoError(s.q.Publish(s.ctx, "unacked", 0))
+	s.NoError(s.q.Publish(s.ctx, "acked", 0))
+
+	c := s.consume()
+	unacked := s.receive(c)
+	s.Equal(`"unacked"`, string(unacked.Body()))
+	s.NoError(s.receive(c).Ack())
+
+	s.NoError(s.q.Cancel(s.ctx))
+	s.restart()
+
+	s.Equal(1, s.len())
+	s.Equal(`"unacked"`, string(s.receive(s.consume()).Body()))
+}
+
+func (s *QueueTestSuite) TestRetry() {
+	s.NoError(s.q.Publish(s.ctx, "retry", 5))
+
+	c := s.consume()
+
+	d := s.receive(c)
+	s.NoError(s.q.Retry(s.ctx, d, 10*time.Millisecond))
+	s.Equal(ErrSettled, d.Ack())
+
+	d = s.receive(c)
+	s.Equal(`"retry"`, string(d.Body()))
+	s.Equal(uint8(5), d.Priority())
+	s.Equal(1, queue.Attempts(d))
+}
+
+func (s *QueueTestSuite) TestRetrySurvivesRestart() {
+	s.NoError(s.q.Publish(s.ctx, "retry", 0))
+
+	d := s.receive(s.consume())
+	s.NoError(s.q.Retry(s.ctx, d, 10*time.Millisecond))
+
+	s.NoError(s.q.Cancel(s.ctx))
+	s.restart()
+
+	d = s.receive(s.consume())
+	s.Equal(`"retry"`, string(d.Body()))
+	s.Equal(1, queue.Attempts(d))
+}
+
+func (s *QueueTestSuite) TestDeadLetter() {
+	s.NoError(s.q.Publish(s.ctx, "dead", 0))
+
+	c := s.consume()
+
+	d := s.receive(c)
+	s.NoError(s.q.DeadLetter(s.ctx, d, errors.New("cause")))
+	s.Equal(0, s.len())
+
+	n, err := s.q.RequeueDead(s.ctx)
+	s.NoError(err)
+	s.Equal(1, n)
+
+	d = s.receive(c)
+	s.Equal(`"dead"`, string(d.Body()))
+	s.Equal(0, queue.Attempts(d))
+	s.NotContains(d.Headers(), queue.ErrorHeader)
+}
+
+func (s *QueueTestSuite) TestForeignDelivery() {
+	other, err := s.b.Queue("other")
+	s.Require().NoError(err)
+	s.NoError(other.Publish(s.ctx, "other", 0))
+
+	c, err := other.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	d := s.receive(c)
+	s.Equal(ErrForeignDelivery, s.q.Retry(s.ctx, d, 0))
+	s.Equal(ErrForeignDelivery, s.q.DeadLetter(s.ctx, d, nil))
+}
+
+func (s *QueueTestSuite) TestCompact() {
+	for i := 0; i < 20; i++ {
+		s.NoError(s.q.Publish(s.ctx, i, uint8(i%10)))
+	}
+
+	c := s.consume()
+	for i := 0; i < 18; i++ {
+		s.NoError(s.receive(c).Ack())
+	}
+	s.NoError(s.q.Cancel(s.ctx))
+
+	s.NoError(s.b.Compact())
+
+	// Remaining messages retained, new messages ordered after them.
+	s.NoError(s.q.Publish(s.ctx, "new", 0))
+	s.Equal(3, s.len())
+
+	s.restart()
+
+	c = s.consume()
+	s.Equal(`0`, string(s.receive(c).Body()))
+	s.Equal(`10`, string(s.receive(c).Body()))
+	s.Equal(`"new"`, string(s.receive(c).Body()))
+}
+
+func (s *QueueTestSuite) TestPublisherFactory() {
+	p, err := PublisherFactory{Broker: s.b, Queue: "test"}.NewPublisher(s.ctx)
+	s.NoError(err)
+	s.Same(s.q, p)
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueTestSuite))
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// Broker holds in-memory queues by name, sharing them between publishers and consumers within a process.
+type Broker struct {
+	config *Config
+
+	mu     sync.Mutex
+	queues map[string]*Queue
+
+	*instr.Instrumentation
+}
+
+// NewBroker returns a new Broker without any queues.
+func NewBroker(cfg *Config, i *instr.Instrumentation) *Broker {
+	return &Broker{
+		config:          cfg,
+		queues:          make(map[string]*Queue),
+		Instrumentation: i,
+	}
+}
+
+// Queue returns the queue with the given name, creating it when it does not exist yet.
+func (b *Broker) Queue(name string) *Queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		q = newQueue(name, b.config, b.Instrumentation)
+		b.queues[name] = q
+	}
+
+	return q
+}
+
+// PublisherFactory automates creation of publishers for a queue on a Broker.
+type PublisherFactory struct {
+	Broker *Broker
+	Queue  string
+}
+
+// NewPublisher returns the named queue on the broker.
+func (f PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, error) {
+	return f.Broker.Queue(f.Queue), nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.PublisherFactory = PublisherFactory{}
@@ -0,0 +1,13 @@
+package memory
+
+// Config specifies the configuration for in-memory queues.
+type Config struct {
+	Capacity int // Maximum number of messages pending delivery in a queue.
+}
+
+// DefaultConfig generates a default configuration for in-memory queues.
+func DefaultConfig() *Config {
+	return &Config{
+		Capacity: 100000,
+	}
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// message is a message held by a queue.
+type message struct {
+	body     []byte
+	headers  map[string]interface{}
+	priority uint8
+}
+
+// delivery is a message delivered to a consumer, to be acknowledged or rejected exactly once.
+type delivery struct {
+	q       *Queue
+	m       *message
+	settled bool // Guarded by q.mu.
+}
+
+// Body returns the message body.
+func (d *delivery) Body() []byte {
+	return d.m.body
+}
+
+// Headers returns the message headers.
+func (d *delivery) Headers() map[string]interface{} {
+	return d.m.headers
+}
+
+// Priority returns the message priority.
+func (d *delivery) Priority() uint8 {
+	return d.m.priority
+}
+
+// Ack acknowledges the delivery, removing it from the queue.
+func (d *delivery) Ack() error {
+	return d.q.settle(d, func() {})
+}
+
+// Nack rejects the delivery. When requeue is true it is returned to the front of the queue, otherwise it is dropped.
+func (d *delivery) Nack(requeue bool) error {
+	if requeue {
+		return d.q.settle(d, func() { d.q.requeue(d.m) })
+	}
+
+	return d.q.settle(d, func() {})
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Delivery = &delivery{}
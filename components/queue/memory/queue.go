@@ -0,0 +1,344 @@
+// Package memory implements in-process priority queues, for single-process deployments and development.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// MaxPriority is the highest message priority; messages published with a higher priority get MaxPriority.
+const MaxPriority = 9
+
+var (
+	// ErrSettled is returned when acknowledging or rejecting a delivery which has already been acknowledged or rejected.
+	ErrSettled = errors.New("delivery already acknowledged or rejected")
+
+	// ErrForeignDelivery is returned when retrying or dead-lettering a delivery from a different queue.
+	ErrForeignDelivery = errors.New("delivery not from this queue")
+)
+
+// Queue is an in-memory priority queue. Publish blocks while Capacity messages are pending delivery. Delivered
+// messages which have not been acknowledged yet and messages waiting to be retried do not count, so that consumers
+// publishing to their own queue cannot block themselves.
+type Queue struct {
+	name   string
+	config *Config
+
+	mu      sync.Mutex
+	pending [MaxPriority + 1][]*message // FIFO per priority.
+	delayed map[*time.Timer]*message    // Messages waiting to be retried, by the timer requeueing them.
+	changed chan struct{}               // Closed and replaced whenever messages are added or removed.
+
+	cancelOnce sync.Once
+	cancelled  chan struct{} // Closed by Cancel.
+
+	*instr.Instrumentation
+}
+
+func newQueue(name string, cfg *Config, i *instr.Instrumentation) *Queue {
+	return &Queue{
+		name:            name,
+		config:          cfg,
+		delayed:         make(map[*time.Timer]*message),
+		changed:         make(chan struct{}),
+		cancelled:       make(chan struct{}),
+		Instrumentation: i,
+	}
+}
+
+// String returns the name of the queue
+func (q *Queue) String() string {
+	return q.name
+}
+
+// Len returns the number of messages pending delivery.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.len()
+}
+
+// len returns the number of messages pending delivery; must be called with mu held.
+func (q *Queue) len() int {
+	n := 0
+	for _, p := range q.pending {
+		n += len(p)
+	}
+
+	return n
+}
+
+// notify wakes up waiting publishers and consumers; must be called with mu held.
+func (q *Queue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// push appends m to the pending messages of its priority; must be called with mu held.
+func (q *Queue) push(m *message) {
+	q.pending[m.priority] = append(q.pending[m.priority], m)
+	q.notify()
+}
+
+// next removes and returns the oldest pending message with the highest priority, or nil when there is none;
+// must be called with mu held.
+func (q *Queue) next() *message {
+	for p := MaxPriority; p >= 0; p-- {
+		if len(q.pending[p]) > 0 {
+			m := q.pending[p][0]
+			q.pending[p][0] = nil
+			q.pending[p] = q.pending[p][1:]
+			q.notify()
+
+			return m
+		}
+	}
+
+	return nil
+}
+
+// requeue returns a delivered message to the front of its priority; must be called with mu held.
+func (q *Queue) requeue(m *message) {
+	q.pending[m.priority] = append([]*message{m}, q.pending[m.priority]...)
+	q.notify()
+}
+
+// settle calls f for an unsettled delivery, marking it settled.
+func (q *Queue) settle(d *delivery, f func()) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if d.settled {
+		return ErrSettled
+	}
+
+	d.settled = true
+	f()
+
+	return nil
+}
+
+// publish adds m, waiting for capacity.
+func (q *Queue) publish(ctx context.Context, m *message) error {
+	for {
+		q.mu.Lock()
+		if q.len() < q.config.Capacity {
+			q.push(m)
+			q.mu.Unlock()
+
+			return nil
+		}
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Publish adds a task with specified params to the Queue, blocking while the queue is at capacity.
+// priority: higher number, higher priority
+func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.memory.Publish",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Any("params", params)),
+		trace.WithAttributes(label.Uint("priority", uint(priority))),
+	)
+	defer span.End()
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return t.Permanent(err)
+	}
+
+	if priority > MaxPriority {
+		priority = MaxPriority
+	}
+
+	if err := q.publish(ctx, &message{body: body, headers: queue.TraceHeaders(ctx), priority: priority}); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
+}
+
+// receive waits for the next pending message, returning nil when ctx is done or the queue has been cancelled.
+func (q *Queue) receive(ctx context.Context) *message {
+	for {
+		q.mu.Lock()
+		m := q.next()
+		changed := q.changed
+		q.mu.Unlock()
+
+		if m != nil {
+			return m
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.cancelled:
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// Consume returns a channel delivering messages in order of priority, which is closed when ctx is done or
+// after Cancel.
+func (q *Queue) Consume(ctx context.Context) (<-chan queue.Delivery, error) {
+	out := make(chan queue.Delivery)
+
+	go func() {
+		defer close(out)
+
+		for {
+			m := q.receive(ctx)
+			if m == nil {
+				return
+			}
+
+			select {
+			case out <- &delivery{q: q, m: m}:
+			case <-ctx.Done():
+				q.returnUndelivered(m)
+				return
+			case <-q.cancelled:
+				q.returnUndelivered(m)
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// returnUndelivered returns a message which was received but could not be delivered.
+func (q *Queue) returnUndelivered(m *message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.requeue(m)
+}
+
+// Cancel stops consuming; channels returned by Consume are closed. Deliveries received before remain to be
+// acknowledged or rejected. Messages waiting to be retried are returned to the queue right away.
+func (q *Queue) Cancel(ctx context.Context) error {
+	q.cancelOnce.Do(func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		close(q.cancelled)
+
+		for timer, m := range q.delayed {
+			timer.Stop()
+			q.push(m)
+		}
+		q.delayed = nil
+	})
+
+	return nil
+}
+
+// own returns d as a delivery of this queue.
+func (q *Queue) own(d queue.Delivery) (*delivery, error) {
+	if d, ok := d.(*delivery); ok && d.q == q {
+		return d, nil
+	}
+
+	return nil, ErrForeignDelivery
+}
+
+// Retry acknowledges a failed delivery and requeues it after delay, incrementing its attempt counter.
+// Delayed messages are requeued regardless of capacity; after Cancel, they are requeued right away.
+func (q *Queue) Retry(ctx context.Context, d queue.Delivery, delay time.Duration) error {
+	attempts := queue.Attempts(d) + 1
+
+	ctx, span := q.Tracer.Start(ctx, "queue.memory.Retry",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Int("attempts", attempts)),
+		trace.WithAttributes(label.String("delay", delay.String())),
+	)
+	defer span.End()
+
+	own, err := q.own(d)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = attempts
+
+	m := &message{
+		body:     own.m.body,
+		headers:  headers,
+		priority: own.m.priority,
+	}
+
+	return q.settle(own, func() {
+		select {
+		case <-q.cancelled:
+			q.push(m)
+			return
+		default:
+		}
+
+		// The timer fires after settle releases mu, by which time it is registered.
+		var timer *time.Timer
+		timer = time.AfterFunc(delay, func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			// Already requeued by Cancel.
+			if _, ok := q.delayed[timer]; !ok {
+				return
+			}
+
+			delete(q.delayed, timer)
+			q.push(m)
+		})
+		q.delayed[timer] = m
+	})
+}
+
+// DeadLetter acknowledges a failed delivery and drops it, logging its cause. As in-memory queues are lost on
+// exit, there is no dead-letter queue from which messages could be requeued later.
+func (q *Queue) DeadLetter(ctx context.Context, d queue.Delivery, cause error) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.memory.DeadLetter",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	own, err := q.own(d)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return q.settle(own, func() {
+		log.Printf("Dropping message from %s after %d attempts: %s, cause: %v", q.name, queue.Attempts(d)+1, own.m.body, cause)
+	})
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Queue = &Queue{}
+var _ queue.Retrier = &Queue{}
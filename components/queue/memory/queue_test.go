@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testTimeout = time.Second
+
+type QueueTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cancel func()
+
+	b *Broker
+	q *Queue
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithTimeout(context.Background(), testTimeout)
+	s.b = NewBroker(&Config{Capacity: 3}, instr.New())
+	s.q = s.b.Queue("test")
+}
+
+func (s *QueueTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *QueueTestSuite) receive(c <-chan queue.Delivery) queue.Delivery {
+	select {
+	case d, ok := <-c:
+		s.Require().True(ok)
+		return d
+	case <-time.After(testTimeout):
+		s.FailNow("timeout waiting for delivery")
+	}
+
+	return nil
+}
+
+func (s *QueueTestSuite) TestBrokerSharesQueues() {
+	s.Same(s.q, s.b.Queue("test"))
+	s.NotSame(s.q, s.b.Queue("other"))
+
+	p, err := PublisherFactory{Broker: s.b, Queue: "test"}.NewPublisher(s.ctx)
+	s.NoError(err)
+	s.Same(s.q, p)
+}
+
+func (s *QueueTestSuite) TestPriorityOrder() {
+	s.NoError(s.q.Publish(s.ctx, "low", 1))
+	s.NoError(s.q.Publish(s.ctx, "high", 9))
+	s.NoError(s.q.Publish(s.ctx, "overflow", 20))
+
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	// Equal priorities are delivered in order of publishing.
+	d := s.receive(c)
+	s.Equal(`"high"`, string(d.Body()))
+	s.Equal(uint8(9), d.Priority())
+
+	d = s.receive(c)
+	s.Equal(`"overflow"`, string(d.Body()))
+	s.Equal(uint8(MaxPriority), d.Priority())
+
+	s.Equal(`"low"`, string(s.receive(c).Body()))
+}
+
+func (s *QueueTestSuite) TestBackpressure() {
+	for i := 0; i < 3; i++ {
+		s.NoError(s.q.Publish(s.ctx, i, 0))
+	}
+
+	// Full; blocks until the context expires.
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+	s.Equal(context.DeadlineExceeded, s.q.Publish(ctx, 3, 0))
+
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	// Delivering a message frees capacity, also before it is acknowledged.
+	d := s.receive(c)
+	s.NoError(s.q.Publish(s.ctx, 3, 0))
+	s.NoError(d.Ack())
+}
+
+func (s *QueueTestSuite) TestPublishFromConsumer() {
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	// Consumers holding unacknowledged deliveries can fill the queue, like directory workers listing entries.
+	s.NoError(s.q.Publish(s.ctx, "dir", 0))
+	d := s.receive(c)
+
+	for i := 0; i < 3; i++ {
+		s.NoError(s.q.Publish(s.ctx, i, 0))
+	}
+
+	s.NoError(d.Ack())
+	s.Equal(3, s.q.Len())
+}
+
+func (s *QueueTestSuite) TestNackRequeue() {
+	s.NoError(s.q.Publish(s.ctx, "requeued", 0))
+
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	d := s.receive(c)
+	s.NoError(d.Nack(true))
+
+	d = s.receive(c)
+	s.Equal(`"requeued"`, string(d.Body()))
+
+	// Settled only once.
+	s.NoError(d.Nack(false))
+	s.Equal(ErrSettled, d.Ack())
+	s.Equal(ErrSettled, d.Nack(true))
+}
+
+func (s *QueueTestSuite) TestRetry() {
+	s.NoError(s.q.Publish(s.ctx, "retry", 5))
+
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	d := s.receive(c)
+	s.NoError(s.q.Retry(s.ctx, d, 10*time.Millisecond))
+	s.Equal(ErrSettled, d.Ack())
+	s.Equal(0, s.q.Len())
+
+	d = s.receive(c)
+	s.Equal(`"retry"`, string(d.Body()))
+	s.Equal(uint8(5), d.Priority())
+	s.Equal(1, queue.Attempts(d))
+}
+
+func (s *QueueTestSuite) TestRetryCapacity() {
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	s.NoError(s.q.Publish(s.ctx, "retry", 0))
+	d := s.receive(c)
+	s.NoError(s.q.Retry(s.ctx, d, time.Hour))
+
+	// Delayed messages do not hold capacity.
+	for i := 0; i < 3; i++ {
+		s.NoError(s.q.Publish(s.ctx, i, 0))
+	}
+}
+
+func (s *QueueTestSuite) TestRetryCancel() {
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	s.NoError(s.q.Publish(s.ctx, "retry", 0))
+	d := s.receive(c)
+	s.NoError(s.q.Retry(s.ctx, d, time.Hour))
+	s.Equal(0, s.q.Len())
+
+	// Pending retries are requeued on Cancel, rather than firing afterwards.
+	s.NoError(s.q.Cancel(s.ctx))
+	s.Equal(1, s.q.Len())
+	s.Empty(s.q.delayed)
+}
+
+func (s *QueueTestSuite) TestRetryAfterCancel() {
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	s.NoError(s.q.Publish(s.ctx, "retry", 0))
+	d := s.receive(c)
+
+	s.NoError(s.q.Cancel(s.ctx))
+	s.NoError(s.q.Retry(s.ctx, d, time.Hour))
+	s.Equal(1, s.q.Len())
+	s.Empty(s.q.delayed)
+}
+
+func (s *QueueTestSuite) TestDeadLetter() {
+	s.NoError(s.q.Publish(s.ctx, "dead", 0))
+
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	d := s.receive(c)
+	s.NoError(s.q.DeadLetter(s.ctx, d, errors.New("cause")))
+	s.Equal(ErrSettled, d.Ack())
+
+	// Dropped.
+	s.Equal(0, s.q.Len())
+}
+
+func (s *QueueTestSuite) TestForeignDelivery() {
+	other := s.b.Queue("other")
+	s.NoError(other.Publish(s.ctx, "other", 0))
+
+	c, err := other.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	d := s.receive(c)
+	s.Equal(ErrForeignDelivery, s.q.Retry(s.ctx, d, 0))
+	s.Equal(ErrForeignDelivery, s.q.DeadLetter(s.ctx, d, nil))
+}
+
+func (s *QueueTestSuite) TestCancel() {
+	c, err := s.q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	s.NoError(s.q.Cancel(s.ctx))
+	s.NoError(s.q.Cancel(s.ctx))
+
+	select {
+	case _, ok := <-c:
+		s.False(ok)
+	case <-time.After(testTimeout):
+		s.Fail("timeout waiting for close")
+	}
+
+	// Messages published after cancelling remain queued.
+	s.NoError(s.q.Publish(s.ctx, "queued", 0))
+	s.Equal(1, s.q.Len())
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueTestSuite))
+}
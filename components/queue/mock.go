@@ -2,7 +2,8 @@ package queue
 
 import (
 	"context"
-	"github.com/streadway/amqp"
+	"time"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -18,9 +19,62 @@ func (m *Mock) Publish(ctx context.Context, pub interface{}, priority uint8) err
 }
 
 // Consume mocks the corresponding method on the Queue interface.
-func (m *Mock) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
+func (m *Mock) Consume(ctx context.Context) (<-chan Delivery, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(<-chan Delivery), args.Error(1)
+}
+
+// Cancel mocks the corresponding method on the Queue interface.
+func (m *Mock) Cancel(ctx context.Context) error {
 	args := m.Called(ctx)
-	return args.Get(0).(<-chan amqp.Delivery), args.Error(1)
+	return args.Error(0)
+}
+
+// Retry mocks the corresponding method on the Retrier interface.
+func (m *Mock) Retry(ctx context.Context, d Delivery, delay time.Duration) error {
+	args := m.Called(ctx, d, delay)
+	return args.Error(0)
+}
+
+// DeadLetter mocks the corresponding method on the Retrier interface.
+func (m *Mock) DeadLetter(ctx context.Context, d Delivery, cause error) error {
+	args := m.Called(ctx, d, cause)
+	return args.Error(0)
+}
+
+// MockDelivery mocks the Delivery interface.
+type MockDelivery struct {
+	mock.Mock
+}
+
+// Body mocks the corresponding method on the Delivery interface.
+func (m *MockDelivery) Body() []byte {
+	args := m.Called()
+	return args.Get(0).([]byte)
+}
+
+// Headers mocks the corresponding method on the Delivery interface.
+func (m *MockDelivery) Headers() map[string]interface{} {
+	args := m.Called()
+	return args.Get(0).(map[string]interface{})
+}
+
+// Priority mocks the corresponding method on the Delivery interface.
+func (m *MockDelivery) Priority() uint8 {
+	args := m.Called()
+	return args.Get(0).(uint8)
+}
+
+// Ack mocks the corresponding method on the Delivery interface.
+func (m *MockDelivery) Ack() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// Nack mocks the corresponding method on the Delivery interface.
+func (m *MockDelivery) Nack(requeue bool) error {
+	args := m.Called(requeue)
+	return args.Error(0)
 }
 
 // MockFactory mocks the Factory interface.
@@ -36,4 +90,6 @@ func (f *MockFactory) NewPublisher(ctx context.Context) (Publisher, error) {
 
 // Compile-time assurance that implementation satisfies interface.
 var _ Queue = &Mock{}
+var _ Retrier = &Mock{}
+var _ Delivery = &MockDelivery{}
 var _ PublisherFactory = &MockFactory{}
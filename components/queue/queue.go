@@ -3,7 +3,7 @@ package queue
 
 import (
 	"context"
-	"github.com/streadway/amqp"
+	"time"
 )
 
 // Publisher allows publishing of sniffed items.
@@ -11,9 +11,43 @@ type Publisher interface {
 	Publish(context.Context, interface{}, uint8) error
 }
 
+// Delivery is a message received from a queue, which should be either acknowledged or rejected.
+type Delivery interface {
+	// Body returns the message body.
+	Body() []byte
+
+	// Headers returns the message headers.
+	Headers() map[string]interface{}
+
+	// Priority returns the message priority; higher number, higher priority.
+	Priority() uint8
+
+	// Ack acknowledges the delivery, removing it from the queue.
+	Ack() error
+
+	// Nack rejects the delivery; when requeue is true, it is returned to the queue to be delivered again.
+	Nack(requeue bool) error
+}
+
 // Consumer allows consuming of published items.
 type Consumer interface {
-	Consume(context.Context) (<-chan amqp.Delivery, error)
+	// Consume returns a channel of deliveries, which is closed after Cancel.
+	Consume(context.Context) (<-chan Delivery, error)
+
+	// Cancel stops consumption; deliveries received before remain to be acknowledged or rejected.
+	Cancel(context.Context) error
+}
+
+// Retrier allows failed deliveries to be retried later or dead-lettered, acknowledging the original delivery.
+type Retrier interface {
+	Retry(ctx context.Context, d Delivery, delay time.Duration) error
+	DeadLetter(ctx context.Context, d Delivery, cause error) error
+}
+
+// Prefetcher is implemented by queues which deliver a limited number of unacknowledged items to a consumer in advance.
+type Prefetcher interface {
+	// SetPrefetch changes the number of unacknowledged deliveries, e.g. when the number of workers changes.
+	SetPrefetch(ctx context.Context, count int) error
 }
 
 // PublisherFactory creates Publishers.
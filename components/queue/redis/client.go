@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/go-redis/redis/v7"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// NewClient returns a client for the configured Redis server, after verifying the connection.
+func NewClient(ctx context.Context, cfg *Config, i *instr.Instrumentation) (*goredis.Client, error) {
+	ctx, span := i.Tracer.Start(ctx, "queue.redis.NewClient", trace.WithAttributes(label.String("redis_url", cfg.URL)))
+	defer span.End()
+
+	opts, err := goredis.ParseURL(cfg.URL)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	client := goredis.NewClient(opts)
+
+	if err := client.WithContext(ctx).Ping().Err(); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,25 @@
+package redis
+
+import (
+	"time"
+)
+
+// Config specifies the configuration for Redis Streams queues.
+type Config struct {
+	URL           string
+	Group         string        // Consumer group shared by all crawlers.
+	MinIdle       time.Duration // Time after which unacknowledged deliveries of other consumers are reclaimed.
+	ClaimInterval time.Duration // Interval at which unacknowledged and retried deliveries are checked.
+	BlockTime     time.Duration // Maximum time to block waiting for new messages.
+}
+
+// DefaultConfig generates a default configuration for Redis Streams queues.
+func DefaultConfig() *Config {
+	return &Config{
+		URL:           "redis://localhost:6379/0",
+		Group:         "ipfs-search",
+		MinIdle:       30 * time.Minute,
+		ClaimInterval: time.Minute,
+		BlockTime:     time.Second,
+	}
+}
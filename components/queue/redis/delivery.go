@@ -0,0 +1,57 @@
+package redis
+
+import (
+	goredis "github.com/go-redis/redis/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// delivery is a stream entry delivered to a consumer, to be acknowledged or rejected exactly once.
+type delivery struct {
+	q        *Queue
+	stream   string
+	id       string
+	priority uint8
+	body     []byte
+	headers  map[string]interface{}
+	settled  int32 // Set atomically when acknowledged or rejected.
+}
+
+// Body returns the message body.
+func (d *delivery) Body() []byte {
+	return d.body
+}
+
+// Headers returns the message headers.
+func (d *delivery) Headers() map[string]interface{} {
+	return d.headers
+}
+
+// Priority returns the message priority.
+func (d *delivery) Priority() uint8 {
+	return d.priority
+}
+
+// Ack acknowledges the delivery, removing it from its stream.
+func (d *delivery) Ack() error {
+	return d.q.settle(d, nil)
+}
+
+// Nack rejects the delivery. When requeue is true, it is added to the end of its stream, otherwise it is dropped.
+func (d *delivery) Nack(requeue bool) error {
+	if !requeue {
+		return d.q.settle(d, nil)
+	}
+
+	v, err := values(d.body, d.headers)
+	if err != nil {
+		return err
+	}
+
+	return d.q.settle(d, func(pipe goredis.Pipeliner) {
+		pipe.XAdd(&goredis.XAddArgs{Stream: d.stream, Values: v})
+	})
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Delivery = &delivery{}
@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MaxPriority is the highest message priority; messages published with a higher priority get MaxPriority.
+const MaxPriority = 9
+
+// Fields of stream entries.
+const (
+	bodyField     = "body"
+	headersField  = "headers"
+	priorityField = "priority" // Only for dead-lettered entries, which are kept in a single stream.
+)
+
+// streamName returns the name of the stream holding messages with the given priority.
+func streamName(name string, priority uint8) string {
+	return fmt.Sprintf("%s:%d", name, priority)
+}
+
+// delayedName returns the name of the sorted set holding messages to be retried, scored by due time.
+func delayedName(name string) string {
+	return name + ":delayed"
+}
+
+// deadName returns the name of the stream holding dead-lettered messages.
+func deadName(name string) string {
+	return name + ":dead"
+}
+
+// delayed is a message waiting to be retried; ID makes otherwise identical messages unique within the set.
+type delayed struct {
+	ID       string          `json:"id"`
+	Priority uint8           `json:"priority"`
+	Body     []byte          `json:"body"`
+	Headers  json.RawMessage `json:"headers"`
+}
+
+// values returns the fields of a stream entry for a message.
+func values(body []byte, headers map[string]interface{}) (map[string]interface{}, error) {
+	v := map[string]interface{}{
+		bodyField: body,
+	}
+
+	if len(headers) > 0 {
+		h, err := json.Marshal(headers)
+		if err != nil {
+			return nil, err
+		}
+
+		v[headersField] = h
+	}
+
+	return v, nil
+}
+
+// decodeHeaders decodes JSON-encoded headers, retaining integers as such.
+func decodeHeaders(data []byte) (map[string]interface{}, error) {
+	var headers map[string]interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&headers); err != nil {
+		return nil, err
+	}
+
+	for k, v := range headers {
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				headers[k] = i
+			} else if f, err := n.Float64(); err == nil {
+				headers[k] = f
+			}
+		}
+	}
+
+	return headers, nil
+}
+
+// field returns a string field from a stream entry.
+func field(values map[string]interface{}, name string) string {
+	s, _ := values[name].(string)
+	return s
+}
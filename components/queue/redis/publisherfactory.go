@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// PublisherFactory automates creation of Redis Streams Publishers.
+type PublisherFactory struct {
+	*Config
+	Queue string
+	*instr.Instrumentation
+}
+
+// NewPublisher generates a new publisher or returns an error.
+func (f PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, error) {
+	ctx, span := f.Tracer.Start(ctx, "queue.redis.NewPublisher",
+		trace.WithAttributes(label.String("redis_url", f.Config.URL)),
+		trace.WithAttributes(label.String("queue", f.Queue)),
+	)
+	defer span.End()
+
+	client, err := NewClient(ctx, f.Config, f.Instrumentation)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	// Close client when context closes
+	go func() {
+		<-ctx.Done()
+		log.Printf("Closing Redis client; context closed")
+		client.Close()
+	}()
+
+	return NewQueue(ctx, client, f.Config, f.Queue, f.Instrumentation)
+}
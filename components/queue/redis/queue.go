@@ -0,0 +1,611 @@
+// Package redis implements queues on Redis Streams, with priorities emulated by one stream per priority.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/go-redis/redis/v7"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// batchSize is the number of entries handled per request when reclaiming, retrying or requeueing.
+const batchSize = 100
+
+// consumerSeq is used to generate unique consumer names.
+var consumerSeq uint64
+
+var (
+	// ErrSettled is returned when acknowledging or rejecting a delivery which has already been acknowledged or rejected.
+	ErrSettled = errors.New("delivery already acknowledged or rejected")
+
+	// ErrForeignDelivery is returned when retrying or dead-lettering a delivery from a different queue.
+	ErrForeignDelivery = errors.New("delivery not from this queue")
+)
+
+// Queue is a queue on Redis Streams, consumed through a consumer group. Deliveries which are not acknowledged
+// within MinIdle, for example because a crawler crashed, are reclaimed by other consumers.
+type Queue struct {
+	name     string
+	client   *goredis.Client
+	config   *Config
+	streams  []string // Stream names, by priority.
+	consumer string   // Name of the consumer within the group.
+
+	cancelOnce sync.Once
+	cancelled  chan struct{} // Closed by Cancel.
+
+	*instr.Instrumentation
+}
+
+// NewQueue returns a queue with the given name, creating its streams and consumer group when they do not exist.
+func NewQueue(ctx context.Context, client *goredis.Client, cfg *Config, name string, i *instr.Instrumentation) (*Queue, error) {
+	ctx, span := i.Tracer.Start(ctx, "queue.redis.NewQueue", trace.WithAttributes(label.String("queue", name)))
+	defer span.End()
+
+	q := &Queue{
+		name:            name,
+		client:          client,
+		config:          cfg,
+		streams:         make([]string, MaxPriority+1),
+		consumer:        fmt.Sprintf("ipfs-search-%d-%d", os.Getpid(), atomic.AddUint64(&consumerSeq, 1)),
+		cancelled:       make(chan struct{}),
+		Instrumentation: i,
+	}
+
+	c := client.WithContext(ctx)
+
+	for p := range q.streams {
+		q.streams[p] = streamName(name, uint8(p))
+
+		// Start at the beginning, consuming messages published before the group was created.
+		err := c.XGroupCreateMkStream(q.streams[p], cfg.Group, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+// String returns the name of the queue
+func (q *Queue) String() string {
+	return q.name
+}
+
+// add adds a message to the stream for its priority.
+func (q *Queue) add(ctx context.Context, priority uint8, body []byte, headers map[string]interface{}) error {
+	v, err := values(body, headers)
+	if err != nil {
+		return err
+	}
+
+	return q.client.WithContext(ctx).XAdd(&goredis.XAddArgs{
+		Stream: q.streams[priority],
+		Values: v,
+	}).Err()
+}
+
+// Publish adds a task with specified params to the Queue
+// priority: higher number, higher priority
+func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.redis.Publish",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Any("params", params)),
+		trace.WithAttributes(label.Uint("priority", uint(priority))),
+	)
+	defer span.End()
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return t.Permanent(err)
+	}
+
+	if priority > MaxPriority {
+		priority = MaxPriority
+	}
+
+	if err := q.add(ctx, priority, body, queue.TraceHeaders(ctx)); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
+}
+
+// newDelivery returns a delivery for a stream entry.
+func (q *Queue) newDelivery(stream string, msg goredis.XMessage) (*delivery, error) {
+	p, err := strconv.Atoi(stream[strings.LastIndexByte(stream, ':')+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	d := &delivery{
+		q:        q,
+		stream:   stream,
+		id:       msg.ID,
+		priority: uint8(p),
+		body:     []byte(field(msg.Values, bodyField)),
+	}
+
+	if h := field(msg.Values, headersField); h != "" {
+		if d.headers, err = decodeHeaders([]byte(h)); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// readGroup reads new entries for the consumer from streams, blocking for at most block; negative block
+// returns immediately.
+func (q *Queue) readGroup(ctx context.Context, streams []string, block time.Duration) ([]*delivery, error) {
+	args := make([]string, 0, 2*len(streams))
+	args = append(args, streams...)
+	for range streams {
+		args = append(args, ">")
+	}
+
+	res, err := q.client.WithContext(ctx).XReadGroup(&goredis.XReadGroupArgs{
+		Group:    q.config.Group,
+		Consumer: q.consumer,
+		Streams:  args,
+		Count:    1,
+		Block:    block,
+	}).Result()
+	if err == goredis.Nil {
+		// Nothing available.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ds []*delivery
+
+	for _, s := range res {
+		for _, msg := range s.Messages {
+			d, err := q.newDelivery(s.Stream, msg)
+			if err != nil {
+				return ds, err
+			}
+
+			ds = append(ds, d)
+		}
+	}
+
+	return ds, nil
+}
+
+// read returns new deliveries with the highest available priority, blocking for at most BlockTime when there
+// are none.
+func (q *Queue) read(ctx context.Context) ([]*delivery, error) {
+	for p := MaxPriority; p >= 0; p-- {
+		ds, err := q.readGroup(ctx, q.streams[p:p+1], -1)
+		if err != nil || len(ds) > 0 {
+			return ds, err
+		}
+	}
+
+	ds, err := q.readGroup(ctx, q.streams, q.config.BlockTime)
+
+	// When blocking, entries may be returned from several streams; deliver the highest priority first.
+	for i, j := 0, len(ds)-1; i < j; i, j = i+1, j-1 {
+		ds[i], ds[j] = ds[j], ds[i]
+	}
+
+	return ds, err
+}
+
+// reclaim claims deliveries which have not been acknowledged by their consumer within MinIdle.
+func (q *Queue) reclaim(ctx context.Context) ([]*delivery, error) {
+	c := q.client.WithContext(ctx)
+
+	var ds []*delivery
+
+	for p := MaxPriority; p >= 0; p-- {
+		stream := q.streams[p]
+
+		pending, err := c.XPendingExt(&goredis.XPendingExtArgs{
+			Stream: stream,
+			Group:  q.config.Group,
+			Start:  "-",
+			End:    "+",
+			Count:  batchSize,
+		}).Result()
+		if err != nil && err != goredis.Nil {
+			return ds, err
+		}
+
+		var ids []string
+		for _, e := range pending {
+			// Deliveries to this consumer are still being processed.
+			if e.Consumer != q.consumer && e.Idle >= q.config.MinIdle {
+				ids = append(ids, e.ID)
+			}
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		msgs, err := c.XClaim(&goredis.XClaimArgs{
+			Stream:   stream,
+			Group:    q.config.Group,
+			Consumer: q.consumer,
+			MinIdle:  q.config.MinIdle,
+			Messages: ids,
+		}).Result()
+		if err != nil {
+			return ds, err
+		}
+
+		for _, msg := range msgs {
+			d, err := q.newDelivery(stream, msg)
+			if err != nil {
+				return ds, err
+			}
+
+			ds = append(ds, d)
+		}
+	}
+
+	if len(ds) > 0 {
+		log.Printf("Reclaimed %d unacknowledged deliveries on %s", len(ds), q)
+	}
+
+	return ds, nil
+}
+
+// promoteScript atomically moves a message to be retried from the delayed set to its stream, so that it is
+// added once when several consumers promote concurrently.
+var promoteScript = goredis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 1 then
+	return redis.call("XADD", KEYS[2], "*", unpack(ARGV, 2))
+end
+return false
+`)
+
+// promote moves messages to be retried which are due to their streams.
+func (q *Queue) promote(ctx context.Context) error {
+	c := q.client.WithContext(ctx)
+	key := delayedName(q.name)
+
+	members, err := c.ZRangeByScore(key, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(unixMilli(time.Now()), 10),
+		Count: batchSize,
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		var m delayed
+		if err := json.Unmarshal([]byte(member), &m); err != nil {
+			return err
+		}
+
+		args := []interface{}{member, bodyField, m.Body}
+		if len(m.Headers) > 0 {
+			args = append(args, headersField, []byte(m.Headers))
+		}
+
+		err := promoteScript.Run(c, []string{key, q.streams[m.Priority]}, args...).Err()
+		if err != nil && err != goredis.Nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unixMilli returns t in milliseconds since the Unix epoch, used to score delayed messages.
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// maintain promotes messages to be retried and returns reclaimed deliveries.
+func (q *Queue) maintain(ctx context.Context) []*delivery {
+	if err := q.promote(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("Error promoting retried messages on %s: %v", q, err)
+	}
+
+	ds, err := q.reclaim(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Error reclaiming deliveries on %s: %v", q, err)
+	}
+
+	return ds
+}
+
+// Consume returns a channel delivering messages in order of priority, which is closed when ctx is done or
+// after Cancel.
+func (q *Queue) Consume(ctx context.Context) (<-chan queue.Delivery, error) {
+	out := make(chan queue.Delivery)
+
+	go func() {
+		defer close(out)
+
+		// Start with a check for abandoned and retried deliveries.
+		var lastClaim time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.cancelled:
+				return
+			default:
+			}
+
+			var ds []*delivery
+
+			if time.Since(lastClaim) >= q.config.ClaimInterval {
+				ds = q.maintain(ctx)
+				lastClaim = time.Now()
+			}
+
+			if len(ds) == 0 {
+				var err error
+				if ds, err = q.read(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("Error reading from %s: %v", q, err)
+
+					// Back off, e.g. while Redis is unavailable.
+					select {
+					case <-time.After(q.config.BlockTime):
+					case <-ctx.Done():
+					case <-q.cancelled:
+					}
+				}
+			}
+
+			if !q.deliver(ctx, ds, out) {
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// deliver sends ds to out, returning false when consuming has stopped. Deliveries which could not be sent are
+// returned to their streams.
+func (q *Queue) deliver(ctx context.Context, ds []*delivery, out chan<- queue.Delivery) bool {
+	for i, d := range ds {
+		select {
+		case out <- d:
+			continue
+		case <-ctx.Done():
+		case <-q.cancelled:
+		}
+
+		for _, d := range ds[i:] {
+			if err := d.Nack(true); err != nil {
+				// Reclaimed after MinIdle.
+				log.Printf("Error returning undelivered message to %s: %v", q, err)
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// Cancel stops consuming; channels returned by Consume are closed. Deliveries received before remain to be
+// acknowledged or rejected.
+func (q *Queue) Cancel(ctx context.Context) error {
+	q.cancelOnce.Do(func() {
+		close(q.cancelled)
+	})
+
+	return nil
+}
+
+// settle acknowledges d and removes it from its stream, along with the commands added by f in the same
+// transaction.
+func (q *Queue) settle(d *delivery, f func(goredis.Pipeliner)) error {
+	if !atomic.CompareAndSwapInt32(&d.settled, 0, 1) {
+		return ErrSettled
+	}
+
+	_, err := q.client.TxPipelined(func(pipe goredis.Pipeliner) error {
+		if f != nil {
+			f(pipe)
+		}
+
+		pipe.XAck(d.stream, q.config.Group, d.id)
+		pipe.XDel(d.stream, d.id)
+
+		return nil
+	})
+
+	if err != nil {
+		// Allow settling again.
+		atomic.StoreInt32(&d.settled, 0)
+	}
+
+	return err
+}
+
+// own returns d as a delivery of this queue.
+func (q *Queue) own(d queue.Delivery) (*delivery, error) {
+	if d, ok := d.(*delivery); ok && d.q == q {
+		return d, nil
+	}
+
+	return nil, ErrForeignDelivery
+}
+
+// Retry acknowledges a failed delivery and requeues it after delay, incrementing its attempt counter.
+func (q *Queue) Retry(ctx context.Context, d queue.Delivery, delay time.Duration) error {
+	attempts := queue.Attempts(d) + 1
+
+	ctx, span := q.Tracer.Start(ctx, "queue.redis.Retry",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.Int("attempts", attempts)),
+		trace.WithAttributes(label.String("delay", delay.String())),
+	)
+	defer span.End()
+
+	own, err := q.own(d)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = attempts
+
+	h, err := json.Marshal(headers)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	member, err := json.Marshal(&delayed{
+		ID:       own.stream + "/" + own.id,
+		Priority: own.priority,
+		Body:     own.body,
+		Headers:  h,
+	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	err = q.settle(own, func(pipe goredis.Pipeliner) {
+		pipe.ZAdd(delayedName(q.name), &goredis.Z{
+			Score:  float64(unixMilli(time.Now().Add(delay))),
+			Member: member,
+		})
+	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// DeadLetter acknowledges a failed delivery and moves it to the dead-letter stream, recording its cause.
+func (q *Queue) DeadLetter(ctx context.Context, d queue.Delivery, cause error) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.redis.DeadLetter",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	own, err := q.own(d)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	headers := queue.CopyHeaders(d)
+	headers[queue.AttemptsHeader] = queue.Attempts(d) + 1
+	if cause != nil {
+		headers[queue.ErrorHeader] = cause.Error()
+	}
+
+	v, err := values(own.body, headers)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+	v[priorityField] = own.priority
+
+	err = q.settle(own, func(pipe goredis.Pipeliner) {
+		pipe.XAdd(&goredis.XAddArgs{Stream: deadName(q.name), Values: v})
+	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// requeue returns a dead-lettered message to the stream for its priority, resetting its attempt counter.
+func (q *Queue) requeue(c *goredis.Client, msg goredis.XMessage) error {
+	p, err := strconv.Atoi(field(msg.Values, priorityField))
+	if err != nil || p < 0 || p > MaxPriority {
+		return fmt.Errorf("invalid priority for dead-lettered message %s: %q", msg.ID, field(msg.Values, priorityField))
+	}
+
+	var headers map[string]interface{}
+	if h := field(msg.Values, headersField); h != "" {
+		if headers, err = decodeHeaders([]byte(h)); err != nil {
+			return err
+		}
+
+		delete(headers, queue.AttemptsHeader)
+		delete(headers, queue.ErrorHeader)
+	}
+
+	v, err := values([]byte(field(msg.Values, bodyField)), headers)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.TxPipelined(func(pipe goredis.Pipeliner) error {
+		pipe.XAdd(&goredis.XAddArgs{Stream: q.streams[p], Values: v})
+		pipe.XDel(deadName(q.name), msg.ID)
+
+		return nil
+	})
+
+	return err
+}
+
+// RequeueDead moves all dead-lettered messages back to the queue, resetting their attempt counter.
+// It returns the number of requeued messages.
+func (q *Queue) RequeueDead(ctx context.Context) (int, error) {
+	ctx, span := q.Tracer.Start(ctx, "queue.redis.RequeueDead",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	c := q.client.WithContext(ctx)
+	n := 0
+
+	for {
+		msgs, err := c.XRangeN(deadName(q.name), "-", "+", batchSize).Result()
+		if err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return n, err
+		}
+
+		if len(msgs) == 0 {
+			return n, nil
+		}
+
+		for _, msg := range msgs {
+			if err := q.requeue(c, msg); err != nil {
+				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+				return n, err
+			}
+
+			n++
+		}
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.Queue = &Queue{}
+var _ queue.Retrier = &Queue{}
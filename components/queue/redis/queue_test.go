@@ -0,0 +1,232 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testTimeout = 5 * time.Second
+
+type QueueTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cancel func()
+
+	srv    *miniredis.Miniredis
+	cfg    *Config
+	client *goredis.Client
+	q      *Queue
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithTimeout(context.Background(), testTimeout)
+
+	var err error
+
+	s.srv, err = miniredis.Run()
+	s.Require().NoError(err)
+
+	s.cfg = &Config{
+		URL:           "redis://" + s.srv.Addr() + "/0",
+		Group:         "test",
+		MinIdle:       time.Hour,
+		ClaimInterval: 10 * time.Millisecond,
+		BlockTime:     10 * time.Millisecond,
+	}
+
+	s.client, err = NewClient(s.ctx, s.cfg, instr.New())
+	s.Require().NoError(err)
+
+	s.q = s.newQueue(s.cfg)
+}
+
+func (s *QueueTestSuite) TearDownTest() {
+	s.cancel()
+	s.NoError(s.client.Close())
+	s.srv.Close()
+}
+
+func (s *QueueTestSuite) newQueue(cfg *Config) *Queue {
+	q, err := NewQueue(s.ctx, s.client, cfg, "test", instr.New())
+	s.Require().NoError(err)
+
+	return q
+}
+
+func (s *QueueTestSuite) consume(q *Queue) <-chan queue.Delivery {
+	c, err := q.Consume(s.ctx)
+	s.Require().NoError(err)
+
+	return c
+}
+
+func (s *QueueTestSuite) receive(c <-chan queue.Delivery) queue.Delivery {
+	select {
+	case d, ok := <-c:
+		s.Require().True(ok)
+		return d
+	case <-time.After(testTimeout):
+		s.FailNow("timeout waiting for delivery")
+	}
+
+	return nil
+}
+
+func (s *QueueTestSuite) TestNewQueueExisting() {
+	// Creating a queue again keeps the existing group.
+	s.newQueue(s.cfg)
+}
+
+func (s *QueueTestSuite) TestPriorityOrder() {
+	s.NoError(s.q.Publish(s.ctx, "low", 1))
+	s.NoError(s.q.Publish(s.ctx, "high", 9))
+	s.NoError(s.q.Publish(s.ctx, "overflow", 20))
+
+	c := s.consume(s.q)
+
+	// Equal priorities are delivered in order of publishing.
+	d := s.receive(c)
+	s.Equal(`"high"`, string(d.Body()))
+	s.Equal(uint8(9), d.Priority())
+
+	d = s.receive(c)
+	s.Equal(`"overflow"`, string(d.Body()))
+	s.Equal(uint8(MaxPriority), d.Priority())
+
+	s.Equal(`"low"`, string(s.receive(c).Body()))
+}
+
+func (s *QueueTestSuite) TestConsumeWaitsForPublish() {
+	c := s.consume(s.q)
+
+	s.NoError(s.q.Publish(s.ctx, "later", 0))
+	s.Equal(`"later"`, string(s.receive(c).Body()))
+}
+
+func (s *QueueTestSuite) TestAck() {
+	s.NoError(s.q.Publish(s.ctx, "ack", 0))
+
+	d := s.receive(s.consume(s.q))
+	s.NoError(d.Ack())
+	s.Equal(ErrSettled, d.Ack())
+	s.Equal(ErrSettled, d.Nack(true))
+
+	// Removed from the stream.
+	s.Equal(int64(0), s.client.XLen(streamName("test", 0)).Val())
+}
+
+func (s *QueueTestSuite) TestNackRequeue() {
+	s.NoError(s.q.Publish(s.ctx, "requeued", 0))
+
+	c := s.consume(s.q)
+
+	d := s.receive(c)
+	s.NoError(d.Nack(true))
+
+	d = s.receive(c)
+	s.Equal(`"requeued"`, string(d.Body()))
+	s.NoError(d.Nack(false))
+}
+
+func (s *QueueTestSuite) TestReclaim() {
+	s.NoError(s.q.Publish(s.ctx, "abandoned", 3))
+
+	// Received but never acknowledged, as if the consumer crashed.
+	s.receive(s.consume(s.q))
+	s.NoError(s.q.Cancel(s.ctx))
+
+	cfg := *s.cfg
+	cfg.MinIdle = time.Millisecond
+	other := s.newQueue(&cfg)
+
+	d := s.receive(s.consume(other))
+	s.Equal(`"abandoned"`, string(d.Body()))
+	s.Equal(uint8(3), d.Priority())
+	s.NoError(d.Ack())
+}
+
+func (s *QueueTestSuite) TestRetry() {
+	s.NoError(s.q.Publish(s.ctx, "retry", 5))
+
+	c := s.consume(s.q)
+
+	d := s.receive(c)
+	s.NoError(s.q.Retry(s.ctx, d, 10*time.Millisecond))
+	s.Equal(ErrSettled, d.Ack())
+
+	d = s.receive(c)
+	s.Equal(`"retry"`, string(d.Body()))
+	s.Equal(uint8(5), d.Priority())
+	s.Equal(1, queue.Attempts(d))
+	s.Equal(int64(0), s.client.ZCard(delayedName("test")).Val())
+}
+
+func (s *QueueTestSuite) TestDeadLetter() {
+	s.NoError(s.q.Publish(s.ctx, "dead", 7))
+
+	c := s.consume(s.q)
+
+	d := s.receive(c)
+	s.NoError(s.q.DeadLetter(s.ctx, d, errors.New("cause")))
+	s.Equal(int64(1), s.client.XLen(deadName("test")).Val())
+
+	n, err := s.q.RequeueDead(s.ctx)
+	s.NoError(err)
+	s.Equal(1, n)
+	s.Equal(int64(0), s.client.XLen(deadName("test")).Val())
+
+	d = s.receive(c)
+	s.Equal(`"dead"`, string(d.Body()))
+	s.Equal(uint8(7), d.Priority())
+	s.Equal(0, queue.Attempts(d))
+	s.NotContains(d.Headers(), queue.ErrorHeader)
+}
+
+func (s *QueueTestSuite) TestForeignDelivery() {
+	other := s.newQueue(s.cfg)
+	s.NoError(other.Publish(s.ctx, "other", 0))
+
+	d := s.receive(s.consume(other))
+	s.Equal(ErrForeignDelivery, s.q.Retry(s.ctx, d, 0))
+	s.Equal(ErrForeignDelivery, s.q.DeadLetter(s.ctx, d, nil))
+}
+
+func (s *QueueTestSuite) TestCancel() {
+	c := s.consume(s.q)
+
+	s.NoError(s.q.Cancel(s.ctx))
+	s.NoError(s.q.Cancel(s.ctx))
+
+	select {
+	case _, ok := <-c:
+		s.False(ok)
+	case <-time.After(testTimeout):
+		s.Fail("timeout waiting for close")
+	}
+
+	// Messages published after cancelling remain queued.
+	s.NoError(s.q.Publish(s.ctx, "queued", 0))
+	s.Equal(int64(1), s.client.XLen(streamName("test", 0)).Val())
+}
+
+func (s *QueueTestSuite) TestPublisherFactory() {
+	p, err := PublisherFactory{Config: s.cfg, Queue: "test", Instrumentation: instr.New()}.NewPublisher(s.ctx)
+	s.Require().NoError(err)
+	s.NoError(p.Publish(s.ctx, "published", 0))
+
+	s.Equal(`"published"`, string(s.receive(s.consume(s.q)).Body()))
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueTestSuite))
+}
@@ -0,0 +1,35 @@
+package queue
+
+const (
+	// AttemptsHeader is the message header counting failed delivery attempts.
+	AttemptsHeader = "x-attempts"
+
+	// ErrorHeader is the message header containing the error causing a message to be dead-lettered.
+	ErrorHeader = "x-error"
+)
+
+// Attempts returns the number of failed attempts recorded for a delivery.
+func Attempts(d Delivery) int {
+	switch v := d.Headers()[AttemptsHeader].(type) {
+	case int:
+		return v
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// CopyHeaders returns a copy of the headers of a delivery, to be modified when republishing it.
+func CopyHeaders(d Delivery) map[string]interface{} {
+	headers := make(map[string]interface{}, len(d.Headers())+2)
+	for k, v := range d.Headers() {
+		headers[k] = v
+	}
+
+	return headers
+}
@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RetryTestSuite struct {
+	suite.Suite
+}
+
+func (s *RetryTestSuite) delivery(headers map[string]interface{}) *MockDelivery {
+	d := &MockDelivery{}
+	d.On("Headers").Return(headers)
+
+	return d
+}
+
+func (s *RetryTestSuite) TestAttempts() {
+	s.Equal(0, Attempts(s.delivery(nil)))
+	s.Equal(3, Attempts(s.delivery(map[string]interface{}{AttemptsHeader: int32(3)})))
+	s.Equal(4, Attempts(s.delivery(map[string]interface{}{AttemptsHeader: int64(4)})))
+	s.Equal(0, Attempts(s.delivery(map[string]interface{}{AttemptsHeader: "invalid"})))
+}
+
+func (s *RetryTestSuite) TestCopyHeaders() {
+	headers := map[string]interface{}{"other": "header"}
+
+	copied := CopyHeaders(s.delivery(headers))
+	copied[AttemptsHeader] = 1
+
+	s.Equal(map[string]interface{}{"other": "header", AttemptsHeader: 1}, copied)
+	s.NotContains(headers, AttemptsHeader)
+}
+
+func TestRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/global"
+)
+
+// headerCarrier carries trace context and baggage in message headers.
+type headerCarrier map[string]interface{}
+
+// Get returns the value of a header, or an empty string when it is not set or not a string.
+func (c headerCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+// Set sets a header.
+func (c headerCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// TraceHeaders returns message headers carrying the W3C trace context and baggage of ctx, using the globally
+// registered propagator, or nil when there is nothing to propagate.
+func TraceHeaders(ctx context.Context) map[string]interface{} {
+	headers := headerCarrier{}
+	global.TextMapPropagator().Inject(ctx, headers)
+
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return headers
+}
+
+// ExtractTrace returns a copy of ctx with the trace context and baggage propagated in the headers of d, so that
+// spans for processing d continue the trace in which it was published.
+func ExtractTrace(ctx context.Context, d Delivery) context.Context {
+	return global.TextMapPropagator().Extract(ctx, headerCarrier(d.Headers()))
+}
@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/api/trace/tracetest"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+type TraceTestSuite struct {
+	suite.Suite
+}
+
+func (s *TraceTestSuite) SetupTest() {
+	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+}
+
+func (s *TraceTestSuite) TestNothingToPropagate() {
+	s.Nil(TraceHeaders(context.Background()))
+}
+
+func (s *TraceTestSuite) TestPropagate() {
+	ctx := otel.ContextWithBaggageValues(context.Background(), label.String("cid", "Qm"))
+	ctx, span := tracetest.NewTracerProvider().Tracer("test").Start(ctx, "publish")
+	defer span.End()
+
+	headers := TraceHeaders(ctx)
+	s.Contains(headers, "traceparent")
+
+	d := &MockDelivery{}
+	d.On("Headers").Return(headers)
+
+	extracted := ExtractTrace(context.Background(), d)
+
+	sc := trace.RemoteSpanContextFromContext(extracted)
+	s.Equal(span.SpanContext().TraceID, sc.TraceID)
+	s.Equal(span.SpanContext().SpanID, sc.SpanID)
+	s.Equal("Qm", otel.BaggageValue(extracted, "cid").AsString())
+}
+
+func TestTraceTestSuite(t *testing.T) {
+	suite.Run(t, new(TraceTestSuite))
+}
@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ipfs-search/ipfs-search/components/metrics"
+	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
 	"github.com/ipfs-search/ipfs-search/components/sniffer"
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
 	"github.com/ipfs-search/ipfs-search/utils"
 	"github.com/ipfs/go-datastore"
-	samqp "github.com/streadway/amqp"
 	"net"
 )
 
@@ -36,7 +38,32 @@ func getInstr(cfg *instr.Config) (*instr.Instrumentation, func(), error) {
 	return instr.New(), instFlusher, nil
 }
 
-func getQueue(ctx context.Context, cfg *amqp.Config, i *instr.Instrumentation) amqp.PublisherFactory {
+func getQueue(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (queue.PublisherFactory, error) {
+	f, err := getBackendQueue(ctx, cfg, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics.NewPublisherFactory(f, "hashes", i), nil
+}
+
+func getBackendQueue(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (queue.PublisherFactory, error) {
+	switch cfg.Queues.Backend {
+	case config.AMQPBackend:
+		return getAMQPQueue(ctx, cfg, i)
+	case config.RedisBackend:
+		return redis.PublisherFactory{
+			Config:          cfg.RedisConfig(),
+			Queue:           "hashes",
+			Instrumentation: i,
+		}, nil
+	default:
+		// Memory and disk queues are local to the crawler process.
+		return nil, fmt.Errorf("sniffing is unsupported for queue backend '%s'", cfg.Queues.Backend)
+	}
+}
+
+func getAMQPQueue(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (queue.PublisherFactory, error) {
 	// Retrying dialer for connecting
 	dialer := &utils.RetryingDialer{
 		Dialer: net.Dialer{
@@ -46,19 +73,20 @@ func getQueue(ctx context.Context, cfg *amqp.Config, i *instr.Instrumentation) a
 		},
 		Context: ctx,
 	}
-	samqpConfig := &samqp.Config{
-		Dial: dialer.Dial,
+	samqpConfig, err := amqp.DialConfig(dialer.Dial, cfg.AMQP.TLSConfig(), cfg.AMQP.CredentialsConfig())
+	if err != nil {
+		return nil, fmt.Errorf("configuring AMQP: %w", err)
 	}
 
 	return amqp.PublisherFactory{
-		Config:          cfg,
+		Config:          cfg.AMQPConfig(),
 		AMQPConfig:      samqpConfig,
 		Queue:           "hashes",
 		Instrumentation: i,
-	}
+	}, nil
 }
 
-func getSniffer(ds datastore.Batching, q amqp.PublisherFactory, i *instr.Instrumentation) (*sniffer.Sniffer, error) {
+func getSniffer(ds datastore.Batching, q queue.PublisherFactory, i *instr.Instrumentation) (*sniffer.Sniffer, error) {
 	c := sniffer.DefaultConfig()
 	return sniffer.New(c, ds, q, i)
 }
@@ -79,7 +107,16 @@ func Start(ctx context.Context, ds datastore.Batching) (context.Context, datasto
 	// Create context which can be canceled by sniffer so as to propagate failure from sniffer goroutine.
 	ctx, cancel := context.WithCancel(ctx)
 
-	q := getQueue(ctx, cfg.AMQPConfig(), i)
+	if err := instr.ServeMetrics(ctx, cfg.Instr.SnifferMetricsAddress); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	q, err := getQueue(ctx, cfg, i)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
 
 	s, err := getSniffer(ds, q, i)
 	if err != nil {
@@ -1,9 +1,13 @@
 package providerfilters
 
 import (
+	"context"
 	"log"
 	"time"
 
+	"go.opentelemetry.io/otel/api/metric"
+
+	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
@@ -12,6 +16,8 @@ type LastSeenFilter struct {
 	resources  map[t.Resource]time.Time
 	Expiration time.Duration
 	PruneLen   int
+
+	size metric.Int64UpDownCounter // Number of resources in the map.
 }
 
 // NewLastSeenFilter initialises a new LastSeenFilter and returns a pointer to it.
@@ -23,9 +29,19 @@ func NewLastSeenFilter(expiration time.Duration, pruneLen int) *LastSeenFilter {
 		Expiration: expiration,
 		PruneLen:   pruneLen,
 		resources:  r,
+		size: metric.Must(instr.New().Meter).NewInt64UpDownCounter(
+			"ipfs_search.sniffer.lastseen.size",
+			metric.WithDescription("Resources tracked by the last-seen filter."),
+		),
 	}
 }
 
+// Discard stops counting the resources of a filter which is no longer used as tracked; it must not be used afterwards.
+func (f *LastSeenFilter) Discard() {
+	f.size.Add(context.Background(), int64(-len(f.resources)))
+	f.resources = nil
+}
+
 func (f *LastSeenFilter) prune() {
 	if len(f.resources) > f.PruneLen {
 		// Delete all expired items
@@ -39,6 +55,8 @@ func (f *LastSeenFilter) prune() {
 			}
 		}
 
+		f.size.Add(context.Background(), int64(-cnt))
+
 		log.Printf("Pruned %d resources, len: %d, pruneLen: %d", cnt, len(f.resources), f.PruneLen)
 	}
 }
@@ -54,6 +72,7 @@ func (f *LastSeenFilter) Filter(p t.Provider) (bool, error) {
 		// Not present, add it!
 		log.Printf("Adding LastSeen: %v, len: %d", p, len(f.resources))
 		f.resources[*(p.Resource)] = p.Date
+		f.size.Add(context.Background(), 1)
 
 		// Index it!
 		return true, nil
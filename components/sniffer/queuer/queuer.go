@@ -43,7 +43,7 @@ func (q *Queuer) iterate(ctx context.Context) error {
 	case p := <-q.providers:
 		return func() error {
 			ctx = trace.ContextWithRemoteSpanContext(ctx, p.SpanContext)
-			_, span := q.Tracer.Start(ctx, "queue.Publish", trace.WithAttributes(
+			ctx, span := q.Tracer.Start(ctx, "queue.Publish", trace.WithAttributes(
 				label.String("cid", p.ID),
 				label.String("peerid", p.Provider),
 			), trace.WithSpanKind(trace.SpanKindProducer))
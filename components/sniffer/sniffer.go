@@ -80,6 +80,8 @@ func (s *Sniffer) filter(ctx context.Context, in <-chan t.Provider, out chan<- t
 	// defer span.End()
 
 	lastSeenFilter := filters.NewLastSeenFilter(s.cfg.LastSeenExpiration, s.cfg.LastSeenPruneLen)
+	defer lastSeenFilter.Discard()
+
 	cidFilter := filters.NewCidFilter()
 	mutliFilter := filters.NewMultiFilter(lastSeenFilter, cidFilter)
 	f := filter.New(mutliFilter, in, out)
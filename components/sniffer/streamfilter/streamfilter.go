@@ -3,6 +3,7 @@ package streamfilter
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/label"
 
@@ -12,21 +13,42 @@ import (
 	filters "github.com/ipfs-search/ipfs-search/components/sniffer/providerfilters"
 )
 
+// filterResult returns the result of filtering a provider, as recorded in metrics.
+func filterResult(include bool, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case include:
+		return "pass"
+	default:
+		return "drop"
+	}
+}
+
 // Filter filters a stream of Providers through filters.Filter.
 type Filter struct {
 	f   filters.Filter
 	in  <-chan t.Provider
 	out chan<- t.Provider
+
+	filtered metric.Int64Counter // Filtered providers, by result.
+
 	*instr.Instrumentation
 }
 
 // New creates a new Filter based on a Filter, an incoming and an outgoing channel.
 func New(f filters.Filter, in <-chan t.Provider, out chan<- t.Provider) Filter {
+	i := instr.New()
+
 	return Filter{
-		f:               f,
-		in:              in,
-		out:             out,
-		Instrumentation: instr.New(),
+		f:   f,
+		in:  in,
+		out: out,
+		filtered: metric.Must(i.Meter).NewInt64Counter(
+			"ipfs_search.sniffer.filtered",
+			metric.WithDescription("Sniffed providers by filter result; pass, drop or error."),
+		),
+		Instrumentation: i,
 	}
 }
 
@@ -46,6 +68,8 @@ func (f *Filter) iterate(ctx context.Context) error {
 
 			include, err := f.f.Filter(p)
 
+			f.filtered.Add(ctx, 1, label.String("result", filterResult(include, err)))
+
 			if err != nil {
 				span.RecordError(ctx, err)
 			}
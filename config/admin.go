@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+)
+
+/*
+Admin contains the configuration for the admin HTTP server of the crawler.
+
+It is fully contained here in order to avoid cyclic imports as the admin package uses the worker pool, which uses the central Config struct.
+*/
+type Admin struct {
+	Enabled      bool          `yaml:"enabled" env:"ADMIN_ENABLED"` // Serve health, readiness, status, pause/resume and pprof endpoints.
+	Address      string        `yaml:"address" env:"ADMIN_ADDRESS"` // Address to serve admin endpoints on.
+	CheckTimeout time.Duration `yaml:"check_timeout"`               // Time allowed for readiness checks of backends.
+}
+
+// AdminDefaults returns the default configuration for the admin server.
+func AdminDefaults() Admin {
+	return Admin{
+		Enabled:      false,
+		Address:      "localhost:9466", // Endpoints are unauthenticated; only expose them deliberately.
+		CheckTimeout: 5 * time.Second,
+	}
+}
@@ -7,18 +7,28 @@ import (
 
 // AMQP contains configuration pertaining to AMQP.
 type AMQP struct {
-	URL           string        `yaml:"url" env:"AMQP_URL"` // URL of AMQP server.
+	URL           string        `yaml:"url" env:"AMQP_URL"` // URL of AMQP server; use amqps:// for TLS.
 	MaxReconnect  int           `yaml:"max_reconnect"`      // The maximum number of reconnection attempts after the server connection is lost.
 	ReconnectTime time.Duration `yaml:"reconnect_time"`     // The time to wait in between reconnect attempts.
+	Security      `yaml:",inline"`
 }
 
 // AMQPConfig returns component-specific configuration from the canonical configuration.
 func (c *Config) AMQPConfig() *amqp.Config {
-	cfg := amqp.Config(c.AMQP)
-	return &cfg
+	return &amqp.Config{
+		URL:           c.AMQP.URL,
+		MaxReconnect:  c.AMQP.MaxReconnect,
+		ReconnectTime: c.AMQP.ReconnectTime,
+	}
 }
 
 // AMQPDefaults returns the defaults for component configuration, based on the component-specific configuration.
 func AMQPDefaults() AMQP {
-	return AMQP(*amqp.DefaultConfig())
+	cfg := amqp.DefaultConfig()
+
+	return AMQP{
+		URL:           cfg.URL,
+		MaxReconnect:  cfg.MaxReconnect,
+		ReconnectTime: cfg.ReconnectTime,
+	}
 }
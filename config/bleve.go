@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/index/bleve"
+)
+
+// Bleve holds configuration for the embedded Bleve index backend.
+type Bleve struct {
+	Path string `yaml:"path" env:"BLEVE_PATH"` // Directory in which index files are stored.
+}
+
+// BleveConfig returns component-specific configuration for the index with the specified name.
+func (c *Config) BleveConfig(name string) *bleve.Config {
+	return &bleve.Config{
+		Name: name,
+		Path: c.Bleve.Path,
+	}
+}
+
+// BleveDefaults returns the defaults for Bleve.
+func BleveDefaults() Bleve {
+	return Bleve{
+		Path: "indexes",
+	}
+}
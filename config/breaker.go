@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/breaker"
+)
+
+// Breaker specifies the configuration for the circuit breakers around backends.
+type Breaker struct {
+	Threshold     float64       `yaml:"threshold"`      // Ratio of failed requests at which consumption is paused.
+	MinRequests   int           `yaml:"min_requests"`   // Minimum number of requests within window before pausing.
+	Window        time.Duration `yaml:"window"`         // Period over which the failure ratio is determined.
+	ProbeInterval time.Duration `yaml:"probe_interval"` // Interval at which unavailable backends are probed.
+}
+
+// BreakerConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) BreakerConfig() *breaker.Config {
+	cfg := breaker.Config(c.Breaker)
+	return &cfg
+}
+
+// BreakerDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func BreakerDefaults() Breaker {
+	return Breaker(*breaker.DefaultConfig())
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/protocol/car"
+)
+
+// CAR specifies the configuration for the CAR protocol.
+type CAR struct {
+	Files         string `yaml:"files" env:"CAR_FILES"`                   // Glob pattern matching the CAR files to crawl, e.g. `/data/*.car`.
+	ServerAddress string `yaml:"server_address" env:"CAR_SERVER_ADDRESS"` // Address to serve file contents on, for metadata extraction.
+	GatewayURL    string `yaml:"gateway_url" env:"CAR_GATEWAY_URL"`       // URL under which the served file contents are reachable for the extractor.
+}
+
+// CARConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) CARConfig() *car.Config {
+	cfg := car.Config(c.CAR)
+	return &cfg
+}
+
+// CARDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func CARDefaults() CAR {
+	return CAR(*car.DefaultConfig())
+}
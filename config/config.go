@@ -37,9 +37,15 @@ import (
 
 // Config contains the configuration for all components.
 type Config struct {
+	Protocol      `yaml:"protocol"`
 	IPFS          `yaml:"ipfs"`
+	Filesystem    `yaml:"filesystem"`
+	CAR           `yaml:"car"`
+	Gateway       `yaml:"gateway"`
 	ElasticSearch `yaml:"elasticsearch"`
+	Bleve         `yaml:"bleve"`
 	AMQP          `yaml:"amqp"`
+	Redis         `yaml:"redis"`
 	Tika          `yaml:"tika"`
 
 	Instr   `yaml:"instrumentation"`
@@ -48,6 +54,9 @@ type Config struct {
 	Indexes `yaml:"indexes"`
 	Queues  `yaml:"queues"`
 	Workers `yaml:"workers"`
+	Breaker `yaml:"breaker"`
+	Admin   `yaml:"admin"`
+	Limiter `yaml:"limiter"`
 }
 
 // String renders config as YAML
@@ -3,9 +3,15 @@ package config
 // Default returns default configuration.
 func Default() *Config {
     return &Config{
+        ProtocolDefaults(),
         IPFSDefaults(),
+        FilesystemDefaults(),
+        CARDefaults(),
+        GatewayDefaults(),
         ElasticSearchDefaults(),
+        BleveDefaults(),
         AMQPDefaults(),
+        RedisDefaults(),
         TikaDefaults(),
         InstrDefaults(),
         CrawlerDefaults(),
@@ -13,5 +19,8 @@ func Default() *Config {
         IndexesDefaults(),
         QueuesDefaults(),
         WorkersDefaults(),
+        BreakerDefaults(),
+        AdminDefaults(),
+        LimiterDefaults(),
     }
 }
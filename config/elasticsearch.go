@@ -2,7 +2,8 @@ package config
 
 // ElasticSearch holds configuration for ElasticSearch.
 type ElasticSearch struct {
-	URL string `yaml:"url" env:"ELASTICSEARCH_URL"`
+	URL      string `yaml:"url" env:"ELASTICSEARCH_URL"`
+	Security `yaml:",inline"`
 }
 
 // ElasticSearchDefaults returns the defaults for ElasticSearch.
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/protocol/filesystem"
+)
+
+// Filesystem specifies the configuration for the filesystem protocol.
+type Filesystem struct {
+	Root          string `yaml:"root" env:"FILESYSTEM_ROOT"`                     // Directory containing the resources; resource IDs are absolute paths within it.
+	ServerAddress string `yaml:"server_address" env:"FILESYSTEM_SERVER_ADDRESS"` // Address to serve file contents on, for metadata extraction.
+	GatewayURL    string `yaml:"gateway_url" env:"FILESYSTEM_GATEWAY_URL"`       // URL under which the served file contents are reachable for the extractor.
+}
+
+// FilesystemConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) FilesystemConfig() *filesystem.Config {
+	cfg := filesystem.Config(c.Filesystem)
+	return &cfg
+}
+
+// FilesystemDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func FilesystemDefaults() Filesystem {
+	return Filesystem(*filesystem.DefaultConfig())
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/c2h5oh/datasize"
+	"github.com/ipfs-search/ipfs-search/components/protocol/gateway"
+)
+
+// Gateway specifies the configuration for the trustless gateway protocol.
+type Gateway struct {
+	TrustlessGatewayURL string            `yaml:"trustless_gateway_url" env:"GATEWAY_TRUSTLESS_URL"` // Trustless gateway to fetch verifiable blocks and CARs from.
+	GatewayURL          string            `yaml:"gateway_url"`                                       // Gateway to request content from, for metadata extraction.
+	PartialSize         datasize.ByteSize `yaml:"partial_size"`                                      // Filesize of items which are being considered partials (chunks).
+	MaxBlockSize        datasize.ByteSize `yaml:"max_block_size"`                                    // Maximum size of blocks accepted from the trustless gateway.
+}
+
+// GatewayConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) GatewayConfig() *gateway.Config {
+	cfg := gateway.Config(c.Gateway)
+	return &cfg
+}
+
+// GatewayDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func GatewayDefaults() Gateway {
+	return Gateway(*gateway.DefaultConfig())
+}
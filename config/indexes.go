@@ -1,28 +1,80 @@
 package config
 
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+)
+
+// Index backends.
+const (
+	ElasticsearchBackend = "elasticsearch" // Index in Elasticsearch.
+	BleveBackend         = "bleve"         // Index in embedded Bleve index on local disk.
+)
+
 // Index represents the configuration for a single Index.
 type Index struct {
-    Name string
+	Name    string `yaml:"name"`    // Name of the Index.
+	Backend string `yaml:"backend"` // Backend storing the Index; either "elasticsearch" or "bleve".
+}
+
+// Bulk represents the configuration for bulk indexing.
+type Bulk struct {
+	Enabled       bool              `yaml:"enabled" env:"ELASTICSEARCH_BULK"` // Batch index and update requests.
+	Workers       int               `yaml:"workers"`                          // Number of concurrent workers committing bulk requests.
+	FlushActions  int               `yaml:"flush_actions"`                    // Commit when this many requests are pending.
+	FlushBytes    datasize.ByteSize `yaml:"flush_bytes"`                      // Commit when pending requests exceed this size.
+	FlushInterval time.Duration     `yaml:"flush_interval"`                   // Commit pending requests at least this often.
 }
 
 // Indexes represents the various indexes we're using
 type Indexes struct {
-    Files       Index `yaml:"files"`
-    Directories Index `yaml:"directories"`
-    Invalids    Index `yaml:"invalids"`
+	Files       Index `yaml:"files"`
+	Directories Index `yaml:"directories"`
+	Invalids    Index `yaml:"invalids"`
+	Bulk        Bulk  `yaml:"bulk"`
+}
+
+// BulkConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) BulkConfig() *elasticsearch.BulkConfig {
+	return &elasticsearch.BulkConfig{
+		Workers:       c.Indexes.Bulk.Workers,
+		FlushActions:  c.Indexes.Bulk.FlushActions,
+		FlushBytes:    c.Indexes.Bulk.FlushBytes,
+		FlushInterval: c.Indexes.Bulk.FlushInterval,
+	}
+}
+
+// BulkDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func BulkDefaults() Bulk {
+	cfg := elasticsearch.DefaultBulkConfig()
+
+	return Bulk{
+		Enabled:       false,
+		Workers:       cfg.Workers,
+		FlushActions:  cfg.FlushActions,
+		FlushBytes:    cfg.FlushBytes,
+		FlushInterval: cfg.FlushInterval,
+	}
 }
 
 // IndexesDefaults returns the default indexes.
 func IndexesDefaults() Indexes {
-    return Indexes{
-        Files: Index{
-            Name: "ipfs_files",
-        },
-        Directories: Index{
-            Name: "ipfs_directories",
-        },
-        Invalids: Index{
-            Name: "ipfs_invalids",
-        },
-    }
+	return Indexes{
+		Files: Index{
+			Name:    "ipfs_files",
+			Backend: ElasticsearchBackend,
+		},
+		Directories: Index{
+			Name:    "ipfs_directories",
+			Backend: ElasticsearchBackend,
+		},
+		Invalids: Index{
+			Name:    "ipfs_invalids",
+			Backend: ElasticsearchBackend,
+		},
+		Bulk: BulkDefaults(),
+	}
 }
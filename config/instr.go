@@ -6,8 +6,17 @@ import (
 
 // Instr specifies the configuration for instrumentation.
 type Instr struct {
-	SamplingRatio  float64 `yaml:"sampling_ratio" env:"OTEL_TRACE_SAMPLER_ARG"`         // Parent-based sampling ratio (fraction of sniffed hashes traced). Defaults to `0.01` (1%). For some reason, setting this as an environment option fails.
-	JaegerEndpoint string  `yaml:"jaeger_endpoint" env:"OTEL_EXPORTER_JAEGER_ENDPOINT"` // Send spans to Jaeger HTTP endpoint, for example `http://jaeger:14268/api/traces`.
+	Exporter           string             `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`                       // Trace exporter; one of `none`, `stdout`, `file`, `jaeger`, `otlp-grpc` or `otlp-http`.
+	SamplingRatio      float64            `yaml:"sampling_ratio" env:"OTEL_TRACE_SAMPLER_ARG"`               // Parent-based sampling ratio (fraction of sniffed hashes traced). Defaults to `0.01` (1%). For some reason, setting this as an environment option fails.
+	SamplingRatios     map[string]float64 `yaml:"sampling_ratios,omitempty"`                                 // Sampling ratio by exporter, e.g. `stdout: 1`, overriding `sampling_ratio` when that exporter is used.
+	JaegerEndpoint     string             `yaml:"jaeger_endpoint" env:"OTEL_EXPORTER_JAEGER_ENDPOINT"`       // Send spans to Jaeger HTTP endpoint, for example `http://jaeger:14268/api/traces`.
+	OTLPEndpoint       string             `yaml:"otlp_endpoint,omitempty" env:"OTEL_EXPORTER_OTLP_ENDPOINT"` // Address of the OTLP collector, for example `collector:4317` for gRPC or `collector:4318` for HTTP. Defaults to `localhost` on the port of the exporter.
+	OTLPInsecure       bool               `yaml:"otlp_insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`           // Connect to the OTLP collector without TLS.
+	TraceFile          string             `yaml:"trace_file"`                                                // File to which the `file` exporter appends spans.
+	ResourceAttributes map[string]string  `yaml:"resource_attributes"`                                       // Attributes added to all spans, besides service name, version and hostname.
+
+	MetricsAddress        string `yaml:"metrics_address,omitempty" env:"METRICS_ADDRESS"`                 // Serve Prometheus metrics of the crawler on this address, at `/metrics`. Empty to disable.
+	SnifferMetricsAddress string `yaml:"sniffer_metrics_address,omitempty" env:"SNIFFER_METRICS_ADDRESS"` // Serve Prometheus metrics of the sniffer on this address, at `/metrics`. Empty to disable.
 }
 
 // InstrConfig returns component-specific configuration from the canonical central configuration.
@@ -1,24 +1,55 @@
 package config
 
 import (
+	"time"
+
 	"github.com/c2h5oh/datasize"
 	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
 )
 
+// IPFSNode specifies an IPFS node, for spreading requests across multiple nodes.
+type IPFSNode struct {
+	APIURL     string `yaml:"api_url"`
+	GatewayURL string `yaml:"gateway_url"`
+}
+
 // IPFS specifies the configuration for the IPFS protocol.
 type IPFS struct {
 	APIURL      string            `yaml:"api_url" env:"IPFS_API_URL"`
 	GatewayURL  string            `yaml:"gateway_url"`
+	Nodes       []IPFSNode        `yaml:"nodes"`                          // Nodes to spread requests across; when empty, api_url and gateway_url are used.
+	Balancing   string            `yaml:"balancing" env:"IPFS_BALANCING"` // Either "least-outstanding" or "consistent-hash".
+	Backoff     time.Duration     `yaml:"backoff"`                        // Duration for which nodes which could not be connected to are avoided.
 	PartialSize datasize.ByteSize `yaml:"partial_size"`
+	Security    `yaml:",inline"`  // Applies to the API of all nodes.
 }
 
 // IPFSConfig returns component-specific configuration from the canonical central configuration.
 func (c *Config) IPFSConfig() *ipfs.Config {
-	cfg := ipfs.Config(c.IPFS)
-	return &cfg
+	nodes := make([]ipfs.Node, len(c.IPFS.Nodes))
+	for i, n := range c.IPFS.Nodes {
+		nodes[i] = ipfs.Node(n)
+	}
+
+	return &ipfs.Config{
+		APIURL:      c.IPFS.APIURL,
+		GatewayURL:  c.IPFS.GatewayURL,
+		Nodes:       nodes,
+		Balancing:   c.IPFS.Balancing,
+		Backoff:     c.IPFS.Backoff,
+		PartialSize: c.IPFS.PartialSize,
+	}
 }
 
 // IPFSDefaults returns the defaults for component configuration, based on the component-specific configuration.
 func IPFSDefaults() IPFS {
-	return IPFS(*ipfs.DefaultConfig())
+	cfg := ipfs.DefaultConfig()
+
+	return IPFS{
+		APIURL:      cfg.APIURL,
+		GatewayURL:  cfg.GatewayURL,
+		Balancing:   cfg.Balancing,
+		Backoff:     cfg.Backoff,
+		PartialSize: cfg.PartialSize,
+	}
 }
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/limiter"
+)
+
+// Limiter specifies the configuration for adaptive concurrency limits of the workers consuming each queue.
+type Limiter struct {
+	Enabled          bool          `yaml:"enabled" env:"LIMITER_ENABLED"` // Adapt the number of concurrent crawls per queue to the latency of IPFS and ipfs-tika.
+	MinLimit         int           `yaml:"min_limit"`                     // Lower bound of concurrent crawls per queue.
+	MaxLimit         int           `yaml:"max_limit"`                     // Upper bound of concurrent crawls per queue, also bounded by the number of workers.
+	LatencyThreshold time.Duration `yaml:"latency_threshold"`             // Requests slower than this decrease the limit, as do timeouts and unavailable backends.
+	Backoff          float64       `yaml:"backoff"`                       // Factor by which the limit is multiplied on overload.
+	DecreaseInterval time.Duration `yaml:"decrease_interval"`             // Minimum time between decreases of the limit.
+}
+
+// LimiterConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) LimiterConfig() *limiter.Config {
+	cfg := limiter.Config(c.Limiter)
+	return &cfg
+}
+
+// LimiterDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func LimiterDefaults() Limiter {
+	return Limiter(*limiter.DefaultConfig())
+}
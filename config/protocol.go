@@ -0,0 +1,21 @@
+package config
+
+// Protocol backends.
+const (
+	IPFSBackend       = "ipfs"       // Resources on IPFS, through the API of an IPFS node.
+	FilesystemBackend = "filesystem" // Resources in a directory on the local filesystem.
+	CARBackend        = "car"        // IPFS resources in CAR files on the local filesystem.
+	GatewayBackend    = "gateway"    // Resources on IPFS, through verifiable responses of a trustless gateway.
+)
+
+// Protocol selects the protocol backend resources are crawled from.
+type Protocol struct {
+	Backend string `yaml:"backend" env:"PROTOCOL_BACKEND"` // Backend for the protocol; "ipfs", "filesystem", "car" or "gateway".
+}
+
+// ProtocolDefaults returns the default protocol backend.
+func ProtocolDefaults() Protocol {
+	return Protocol{
+		Backend: IPFSBackend,
+	}
+}
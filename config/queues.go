@@ -1,20 +1,54 @@
 package config
 
+import (
+	"github.com/ipfs-search/ipfs-search/components/queue/disk"
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
+)
+
+// Queue backends.
+const (
+	AMQPBackend   = "amqp"   // Queue in RabbitMQ.
+	MemoryBackend = "memory" // Queue in process memory; only suitable when publishing and consuming in the same process.
+	DiskBackend   = "disk"   // Queue in embedded database on local disk.
+	RedisBackend  = "redis"  // Queue in Redis Streams.
+)
+
 // Queue holds the configuration for a single Queue.
 type Queue struct {
-	Name string `yaml:"name` // Name of the Queue.
+	Name string `yaml:"name"` // Name of the Queue.
 }
 
 // Queues represents the various queues we're using
 type Queues struct {
-	Files       Queue `yaml:"files"`       // Resources known to be files.
-	Directories Queue `yaml:"directories"` // Resources known to be directories.
-	Hashes      Queue `yaml:"hashes"`      // Resources with unknown type.
+	Backend     string `yaml:"backend"`     // Backend for the queues; either "amqp", "memory", "disk" or "redis".
+	Capacity    int    `yaml:"capacity"`    // Maximum number of messages pending delivery per queue for the memory backend.
+	Path        string `yaml:"path"`        // File in which the disk backend stores queues.
+	Files       Queue  `yaml:"files"`       // Resources known to be files.
+	Directories Queue  `yaml:"directories"` // Resources known to be directories.
+	Hashes      Queue  `yaml:"hashes"`      // Resources with unknown type.
+}
+
+// MemoryQueueConfig returns component-specific configuration for the memory queue backend.
+func (c *Config) MemoryQueueConfig() *memory.Config {
+	return &memory.Config{
+		Capacity: c.Queues.Capacity,
+	}
+}
+
+// DiskQueueConfig returns component-specific configuration for the disk queue backend.
+func (c *Config) DiskQueueConfig() *disk.Config {
+	cfg := disk.DefaultConfig()
+	cfg.Path = c.Queues.Path
+
+	return cfg
 }
 
 // QueuesDefaults returns the default queues.
 func QueuesDefaults() Queues {
 	return Queues{
+		Backend:  AMQPBackend,
+		Capacity: memory.DefaultConfig().Capacity,
+		Path:     disk.DefaultConfig().Path,
 		Files: Queue{
 			Name: "files",
 		},
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/queue/redis"
+	"time"
+)
+
+// Redis contains configuration pertaining to Redis Streams queues.
+type Redis struct {
+	URL           string        `yaml:"url" env:"REDIS_URL"` // URL of Redis server.
+	Group         string        `yaml:"group"`               // Consumer group shared by all crawlers.
+	MinIdle       time.Duration `yaml:"min_idle"`            // Time after which unacknowledged deliveries of other crawlers are reclaimed.
+	ClaimInterval time.Duration `yaml:"claim_interval"`      // Interval at which unacknowledged and retried deliveries are checked.
+	BlockTime     time.Duration `yaml:"block_time"`          // Maximum time to block waiting for new messages.
+}
+
+// RedisConfig returns component-specific configuration from the canonical configuration.
+func (c *Config) RedisConfig() *redis.Config {
+	cfg := redis.Config(c.Redis)
+	return &cfg
+}
+
+// RedisDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func RedisDefaults() Redis {
+	return Redis(*redis.DefaultConfig())
+}
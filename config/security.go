@@ -0,0 +1,42 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// TLS specifies TLS settings for connecting to a backend.
+type TLS struct {
+	CAFile     string `yaml:"ca_file,omitempty"`     // PEM file with CA certificates, replacing the system roots.
+	CertFile   string `yaml:"cert_file,omitempty"`   // PEM file with a client certificate, for mutual TLS.
+	KeyFile    string `yaml:"key_file,omitempty"`    // PEM file with the private key of the client certificate.
+	ServerName string `yaml:"server_name,omitempty"` // Name to verify the server certificate for.
+}
+
+// Credentials specifies credentials for authenticating with a backend; only one kind can be used at a time.
+type Credentials struct {
+	Username        string `yaml:"username,omitempty"`
+	Password        string `yaml:"password,omitempty"`
+	PasswordFile    string `yaml:"password_file,omitempty"` // Read the password from a file, e.g. a mounted secret.
+	APIKey          string `yaml:"api_key,omitempty"`
+	APIKeyFile      string `yaml:"api_key_file,omitempty"`
+	BearerToken     string `yaml:"bearer_token,omitempty"`
+	BearerTokenFile string `yaml:"bearer_token_file,omitempty"`
+}
+
+// Security holds the optional TLS and credential settings of a backend connection.
+type Security struct {
+	TLS         TLS         `yaml:"tls,omitempty"`
+	Credentials Credentials `yaml:"credentials,omitempty"`
+}
+
+// TLSConfig returns TLS settings for utils.GetSecureHTTPClient and amqp.DialConfig.
+func (s *Security) TLSConfig() *utils.TLSConfig {
+	cfg := utils.TLSConfig(s.TLS)
+	return &cfg
+}
+
+// CredentialsConfig returns credentials for utils.GetSecureHTTPClient and amqp.DialConfig.
+func (s *Security) CredentialsConfig() *utils.Credentials {
+	cfg := utils.Credentials(s.Credentials)
+	return &cfg
+}
@@ -13,15 +13,27 @@ type Tika struct {
 	TikaServerURL  string            `yaml:"url" env:"IPFS_TIKA_URL"`
 	RequestTimeout time.Duration     `yaml:"timeout"`
 	MaxFileSize    datasize.ByteSize `yaml:"max_file_size"`
+	GatewayServers map[string]string `yaml:"gateway_servers,omitempty"` // ipfs-tika servers by the gateway URL they fetch from.
+	Security       `yaml:",inline"`
 }
 
 // TikaConfig returns component-specific configuration from the canonical central configuration.
 func (c *Config) TikaConfig() *tika.Config {
-	cfg := tika.Config(c.Tika)
-	return &cfg
+	return &tika.Config{
+		TikaServerURL:  c.Tika.TikaServerURL,
+		RequestTimeout: c.Tika.RequestTimeout,
+		MaxFileSize:    c.Tika.MaxFileSize,
+		GatewayServers: c.Tika.GatewayServers,
+	}
 }
 
 // TikaDefaults returns the defaults for component configuration, based on the component-specific configuration.
 func TikaDefaults() Tika {
-	return Tika(*tika.DefaultConfig())
+	cfg := tika.DefaultConfig()
+
+	return Tika{
+		TikaServerURL:  cfg.TikaServerURL,
+		RequestTimeout: cfg.RequestTimeout,
+		MaxFileSize:    cfg.MaxFileSize,
+	}
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // findZeroElements returns a slice of all (nested) struct fields with a zero value.
@@ -16,6 +17,11 @@ func findZeroElements(s interface{}) []string {
 		f := v.Field(i)
 		name := v.Type().Field(i).Tag.Get("yaml")
 
+		if strings.HasSuffix(name, ",omitempty") || strings.HasSuffix(name, ",inline") {
+			// Optional and inlined fields are not required, e.g. backend security settings.
+			continue
+		}
+
 		switch f.Kind() {
 		case reflect.Struct:
 			// It's a struct - recurse!
@@ -33,6 +39,10 @@ func findZeroElements(s interface{}) []string {
 			// 	v := f.MapIndex(e)
 			// 	findZeroElements(v.Interface())
 			// }
+		case reflect.Slice:
+			// Lists are optional; an empty list is not a missing value.
+		case reflect.Bool:
+			// Booleans are valid either way; false is not a missing value.
 		default:
 			if f.Interface() == reflect.Zero(f.Type()).Interface() {
 				output = append(output, name)
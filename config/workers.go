@@ -1,14 +1,22 @@
 package config
 
+import (
+	"time"
+)
+
 /*
 Workers contains the configuration for the worker pool.
 
 It is fully contained here in order to avoid cyclic imports as the worker package uses the central Config struct.
 */
 type Workers struct {
-	HashWorkers      int `yaml:"hash_workers" env:"HASH_WORKERS"`
-	FileWorkers      int `yaml:"file_workers" env:"FILE_WORKERS"`
-	DirectoryWorkers int `yaml:"directory_workers" env:"DIRECTORY_WORKERS"`
+	HashWorkers      int           `yaml:"hash_workers" env:"HASH_WORKERS"`
+	FileWorkers      int           `yaml:"file_workers" env:"FILE_WORKERS"`
+	DirectoryWorkers int           `yaml:"directory_workers" env:"DIRECTORY_WORKERS"`
+	MaxAttempts      int           `yaml:"max_attempts"`   // Attempts to crawl a resource before it is dead-lettered.
+	RetryDelay       time.Duration `yaml:"retry_delay"`    // Delay before retrying a failed resource, multiplied by the number of attempts.
+	PauseTime        time.Duration `yaml:"pause_time"`     // Time a worker pauses when a backend is unavailable.
+	ShutdownGrace    time.Duration `yaml:"shutdown_grace"` // Time in-flight crawls are given to finish on shutdown.
 }
 
 // WorkersDefaults returns the default configuration for the workerpool.
@@ -17,5 +25,9 @@ func WorkersDefaults() Workers {
 		HashWorkers:      70,
 		FileWorkers:      120,
 		DirectoryWorkers: 70,
+		MaxAttempts:      5,
+		RetryDelay:       time.Minute,
+		PauseTime:        30 * time.Second,
+		ShutdownGrace:    time.Minute,
 	}
 }
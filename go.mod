@@ -3,11 +3,19 @@ module github.com/ipfs-search/ipfs-search
 require (
 	github.com/Netflix/go-env v0.0.0-20210116210345-8f74e74141f7
 	github.com/alanshaw/ipfs-hookds v0.3.0
+	github.com/alicebob/miniredis/v2 v2.23.0
+	github.com/blevesearch/bleve/v2 v2.0.0
 	github.com/c2h5oh/datasize v0.0.0-20200112174442-28bbd4740fee
 	github.com/dankinder/httpmock v1.0.1
+	github.com/go-redis/redis/v7 v7.4.1
+	github.com/hashicorp/golang-lru v0.5.4
+	github.com/ipfs/go-block-format v0.0.2
 	github.com/ipfs/go-cid v0.0.7
 	github.com/ipfs/go-datastore v0.4.5
 	github.com/ipfs/go-ipfs-api v0.0.3
+	github.com/ipfs/go-ipld-cbor v0.0.2
+	github.com/ipfs/go-ipld-format v0.0.2
+	github.com/ipfs/go-merkledag v0.2.3
 	github.com/ipfs/go-unixfs v0.2.4
 	github.com/kr/text v0.2.0 // indirect
 	github.com/libp2p/go-eventbus v0.2.1
@@ -19,15 +27,22 @@ require (
 	github.com/streadway/amqp v0.0.0-20200108173154-1c71cc93ed71
 	github.com/stretchr/objx v0.2.0 // indirect
 	github.com/stretchr/testify v1.6.1
+	go.etcd.io/bbolt v1.3.5
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.13.0
 	go.opentelemetry.io/otel v0.13.0
+	go.opentelemetry.io/otel/exporters/metric/prometheus v0.13.0
+	go.opentelemetry.io/otel/exporters/otlp v0.13.0
+	go.opentelemetry.io/otel/exporters/stdout v0.13.0
 	go.opentelemetry.io/otel/exporters/trace/jaeger v0.13.0
 	go.opentelemetry.io/otel/sdk v0.13.0
+	go.opentelemetry.io/proto/otlp v0.7.0
 	golang.org/x/sync v0.0.0-20200625203802-6e8e738ad208
+	google.golang.org/grpc v1.36.0
+	google.golang.org/protobuf v1.25.0
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 	gopkg.in/urfave/cli.v1 v1.20.0
 	gopkg.in/yaml.v2 v2.3.0
 	gopkg.in/yaml.v3 v3.0.0-20200603094226-e3079894b1e8 // indirect
 )
 
-go 1.13
+go 1.16
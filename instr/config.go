@@ -1,15 +1,70 @@
 package instr
 
+// Trace exporters.
+const (
+	NoExporter       = "none"      // Do not export spans.
+	StdoutExporter   = "stdout"    // Write spans to standard output as JSON.
+	FileExporter     = "file"      // Write spans to TraceFile as JSON.
+	JaegerExporter   = "jaeger"    // Send spans to a Jaeger collector at JaegerEndpoint.
+	OTLPGRPCExporter = "otlp-grpc" // Send spans to an OTLP collector at OTLPEndpoint over gRPC.
+	OTLPHTTPExporter = "otlp-http" // Send spans to an OTLP collector at OTLPEndpoint over HTTP.
+)
+
+// Default OTLP collector addresses, used when OTLPEndpoint is empty; gRPC and HTTP use different ports.
+const (
+	DefaultOTLPGRPCEndpoint = "localhost:4317"
+	DefaultOTLPHTTPEndpoint = "localhost:4318"
+)
+
 // Config specifies the configuration for the instrumentation.
 type Config struct {
-	SamplingRatio  float64 // Parent-based sampling ratio (fraction of sniffed hashes traced).
-	JaegerEndpoint string  // Send spans to Jaeger HTTP endpoint.
+	Exporter           string             // Trace exporter; one of "none", "stdout", "file", "jaeger", "otlp-grpc" or "otlp-http".
+	SamplingRatio      float64            // Parent-based sampling ratio (fraction of sniffed hashes traced).
+	SamplingRatios     map[string]float64 // Sampling ratio by exporter, overriding SamplingRatio.
+	JaegerEndpoint     string             // Send spans to Jaeger HTTP endpoint.
+	OTLPEndpoint       string             // Address (host:port) of the OTLP collector; defaults to the exporter's port on localhost.
+	OTLPInsecure       bool               // Connect to the OTLP collector without TLS.
+	TraceFile          string             // File to which the file exporter appends spans.
+	ResourceAttributes map[string]string  // Attributes added to all spans, besides service name, version and hostname.
+
+	MetricsAddress        string // Serve Prometheus metrics of the crawler on this address; disabled when empty.
+	SnifferMetricsAddress string // Serve Prometheus metrics of the sniffer on this address; disabled when empty.
 }
 
 // DefaultConfig returns the default configuration for the instrumentation.
 func DefaultConfig() *Config {
 	return &Config{
+		Exporter:       JaegerExporter,
 		SamplingRatio:  0.01,
 		JaegerEndpoint: "http://localhost:14268/api/traces",
+		OTLPInsecure:   true,
+		TraceFile:      "traces.json",
+		ResourceAttributes: map[string]string{
+			"service.namespace": "ipfs-search",
+		},
+
+		MetricsAddress:        ":9464",
+		SnifferMetricsAddress: ":9465",
+	}
+}
+
+// samplingRatio returns the sampling ratio for the configured exporter.
+func (c *Config) samplingRatio() float64 {
+	if ratio, ok := c.SamplingRatios[c.Exporter]; ok {
+		return ratio
+	}
+
+	return c.SamplingRatio
+}
+
+// otlpEndpoint returns the address of the OTLP collector for the configured exporter.
+func (c *Config) otlpEndpoint() string {
+	switch {
+	case c.OTLPEndpoint != "":
+		return c.OTLPEndpoint
+	case c.Exporter == OTLPHTTPExporter:
+		return DefaultOTLPHTTPEndpoint
+	default:
+		return DefaultOTLPGRPCEndpoint
 	}
 }
@@ -0,0 +1,46 @@
+package instr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+
+	cfg *Config
+}
+
+func (s *ConfigTestSuite) SetupTest() {
+	s.cfg = DefaultConfig()
+}
+
+func (s *ConfigTestSuite) TestOTLPEndpointDefaults() {
+	s.cfg.Exporter = OTLPGRPCExporter
+	s.Equal(DefaultOTLPGRPCEndpoint, s.cfg.otlpEndpoint())
+
+	s.cfg.Exporter = OTLPHTTPExporter
+	s.Equal(DefaultOTLPHTTPEndpoint, s.cfg.otlpEndpoint())
+}
+
+func (s *ConfigTestSuite) TestOTLPEndpointConfigured() {
+	s.cfg.Exporter = OTLPHTTPExporter
+	s.cfg.OTLPEndpoint = "collector:4317"
+
+	s.Equal("collector:4317", s.cfg.otlpEndpoint())
+}
+
+func (s *ConfigTestSuite) TestSamplingRatioPerExporter() {
+	s.cfg.SamplingRatios = map[string]float64{StdoutExporter: 1}
+
+	s.cfg.Exporter = StdoutExporter
+	s.Equal(1.0, s.cfg.samplingRatio())
+
+	s.cfg.Exporter = JaegerExporter
+	s.Equal(0.01, s.cfg.samplingRatio())
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
@@ -0,0 +1,62 @@
+package instr
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/exporters/trace/jaeger"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// newExporter returns the span exporter specified in config, and an optional io.Closer to be called after the exporter has been shut down.
+func newExporter(config *Config, serviceName string) (export.SpanExporter, io.Closer, error) {
+	switch config.Exporter {
+	case StdoutExporter:
+		e, err := stdout.NewExporter(stdout.WithWriter(os.Stdout), stdout.WithoutMetricExport())
+		return e, nil, err
+
+	case FileExporter:
+		f, err := os.OpenFile(config.TraceFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		e, err := stdout.NewExporter(stdout.WithWriter(f), stdout.WithoutMetricExport())
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+
+		return e, f, nil
+
+	case JaegerExporter:
+		e, err := jaeger.NewRawExporter(
+			jaeger.WithCollectorEndpoint(config.JaegerEndpoint),
+			jaeger.WithProcess(jaeger.Process{ServiceName: serviceName}),
+		)
+		return e, nil, err
+
+	case OTLPGRPCExporter:
+		opts := []otlp.ExporterOption{otlp.WithAddress(config.otlpEndpoint())}
+
+		if config.OTLPInsecure {
+			opts = append(opts, otlp.WithInsecure())
+		} else {
+			opts = append(opts, otlp.WithTLSCredentials(credentials.NewTLS(&tls.Config{})))
+		}
+
+		e, err := otlp.NewExporter(opts...)
+		return e, nil, err
+
+	case OTLPHTTPExporter:
+		return newOTLPHTTPExporter(config.otlpEndpoint(), config.OTLPInsecure), nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter: %s", config.Exporter)
+	}
+}
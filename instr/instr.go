@@ -2,15 +2,20 @@
 package instr
 
 import (
+	"context"
 	"log"
+	"os"
+	"runtime/debug"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/api/global"
 	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/api/trace"
-	"go.opentelemetry.io/otel/exporters/trace/jaeger"
+	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/propagators"
+	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
 )
 
 const (
@@ -23,21 +28,73 @@ type Instrumentation struct {
 	Meter  metric.Meter
 }
 
-// Install configures and installs a Jaeger tracing pipeline. The first returned argument is a flusher, which should be called on program exit.
+// Install configures and installs a tracing pipeline with the exporter specified in config. The first returned argument is a flusher, which should be called on program exit.
 func Install(config *Config, serviceName string) (func(), error) {
-	log.Printf("Creating Jaeger pipeline for service '%s' at ratio %f to endpoint %s", serviceName, config.SamplingRatio, config.JaegerEndpoint)
-
 	// Configure context propagation
 	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
 
+	if config.Exporter == NoExporter {
+		log.Printf("Not exporting traces for service '%s'", serviceName)
+		return func() {}, nil
+	}
+
+	ratio := config.samplingRatio()
+
+	log.Printf("Creating %s trace pipeline for service '%s' at ratio %f", config.Exporter, serviceName, ratio)
+
+	exporter, closer, err := newExporter(config, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
 	// Configure sampler; default 1% of incoming requests (sniffed hashes)
-	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SamplingRatio))
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+	processor := sdktrace.NewBatchSpanProcessor(exporter)
 
-	return jaeger.InstallNewPipeline(
-		jaeger.WithCollectorEndpoint(config.JaegerEndpoint),
-		jaeger.WithProcess(jaeger.Process{ServiceName: serviceName}),
-		jaeger.WithSDK(&sdktrace.Config{DefaultSampler: sampler}),
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sampler}),
+		sdktrace.WithResource(newResource(config, serviceName)),
+		sdktrace.WithSpanProcessor(processor),
 	)
+	global.SetTracerProvider(provider)
+
+	flusher := func() {
+		// Unregistering shuts down the processor, exporting queued spans.
+		provider.UnregisterSpanProcessor(processor)
+
+		if err := exporter.Shutdown(context.Background()); err != nil {
+			log.Printf("Error shutting down trace exporter: %v", err)
+		}
+
+		if closer != nil {
+			if err := closer.Close(); err != nil {
+				log.Printf("Error closing trace file: %v", err)
+			}
+		}
+	}
+
+	return flusher, nil
+}
+
+// newResource returns a resource describing the service, its version and host, along with configured attributes.
+func newResource(config *Config, serviceName string) *resource.Resource {
+	labels := []label.KeyValue{
+		semconv.ServiceNameKey.String(serviceName),
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		labels = append(labels, semconv.ServiceVersionKey.String(info.Main.Version))
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		labels = append(labels, semconv.HostNameKey.String(hostname))
+	}
+
+	for k, v := range config.ResourceAttributes {
+		labels = append(labels, label.String(k, v))
+	}
+
+	return resource.New(labels...)
 }
 
 // New generates a representation of instrumentation containing the globally registered tracer and meter.
@@ -0,0 +1,42 @@
+package instr
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/metric/prometheus"
+
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// durationBoundaries are histogram buckets for durations, in seconds.
+var durationBoundaries = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// ServeMetrics installs a Prometheus exporter as the global meter provider and serves metrics on `/metrics` at
+// address until ctx is done. Instruments created by New before calling ServeMetrics are exported as well.
+// Metrics are not served when address is empty.
+func ServeMetrics(ctx context.Context, address string) error {
+	if address == "" {
+		log.Printf("Not serving metrics")
+		return nil
+	}
+
+	exporter, err := prometheus.InstallNewPipeline(prometheus.Config{
+		DefaultHistogramBoundaries: durationBoundaries,
+	})
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+
+	return utils.Serve(ctx, address, mux, "metrics")
+}
+
+// Since returns the time elapsed since start in seconds, as recorded by duration histograms.
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
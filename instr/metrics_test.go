@@ -0,0 +1,40 @@
+package instr
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cancel func()
+}
+
+func (s *MetricsTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *MetricsTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *MetricsTestSuite) TestDisabled() {
+	s.NoError(ServeMetrics(s.ctx, ""))
+}
+
+func (s *MetricsTestSuite) TestAddressInUse() {
+	l, err := net.Listen("tcp", "localhost:0")
+	s.Require().NoError(err)
+	defer l.Close()
+
+	s.Error(ServeMetrics(s.ctx, l.Addr().String()))
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
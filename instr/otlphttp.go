@@ -0,0 +1,257 @@
+package instr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpHTTPPath is the path at which OTLP collectors accept spans over HTTP.
+const otlpHTTPPath = "/v1/traces"
+
+// otlpHTTPExporter sends spans as protobuf-encoded ExportTraceServiceRequest to an OTLP collector over HTTP.
+type otlpHTTPExporter struct {
+	url    string
+	client *http.Client
+}
+
+// newOTLPHTTPExporter returns an exporter sending spans to the OTLP collector at endpoint (host:port).
+func newOTLPHTTPExporter(endpoint string, insecure bool) *otlpHTTPExporter {
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+
+	return &otlpHTTPExporter{
+		url:    scheme + "://" + endpoint + otlpHTTPPath,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ExportSpans sends spans to the collector, implementing export.SpanExporter.
+func (e *otlpHTTPExporter) ExportSpans(ctx context.Context, spans []*export.SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := marshalSpans(spans)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain body to allow connection reuse.
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status from OTLP collector at %s: %s", e.url, resp.Status)
+	}
+
+	return nil
+}
+
+// Shutdown implements export.SpanExporter.
+func (e *otlpHTTPExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// marshalSpans encodes spans as an ExportTraceServiceRequest, which consists of repeated ResourceSpans in field 1.
+func marshalSpans(spans []*export.SpanData) ([]byte, error) {
+	var b []byte
+
+	for _, rs := range resourceSpans(spans) {
+		m, err := proto.Marshal(rs)
+		if err != nil {
+			return nil, err
+		}
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, m)
+	}
+
+	return b, nil
+}
+
+// resourceSpans groups spans by resource and instrumentation library.
+func resourceSpans(spans []*export.SpanData) []*tracepb.ResourceSpans {
+	var (
+		result    []*tracepb.ResourceSpans
+		resources = make(map[*resource.Resource]*tracepb.ResourceSpans)
+		libraries = make(map[*resource.Resource]map[instrumentation.Library]*tracepb.InstrumentationLibrarySpans)
+	)
+
+	for _, s := range spans {
+		rs, ok := resources[s.Resource]
+		if !ok {
+			rs = &tracepb.ResourceSpans{
+				Resource: &resourcepb.Resource{
+					Attributes: keyValues(s.Resource.Attributes()),
+				},
+			}
+			resources[s.Resource] = rs
+			libraries[s.Resource] = make(map[instrumentation.Library]*tracepb.InstrumentationLibrarySpans)
+			result = append(result, rs)
+		}
+
+		ils, ok := libraries[s.Resource][s.InstrumentationLibrary]
+		if !ok {
+			ils = &tracepb.InstrumentationLibrarySpans{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+					Name:    s.InstrumentationLibrary.Name,
+					Version: s.InstrumentationLibrary.Version,
+				},
+			}
+			libraries[s.Resource][s.InstrumentationLibrary] = ils
+			rs.InstrumentationLibrarySpans = append(rs.InstrumentationLibrarySpans, ils)
+		}
+
+		ils.Spans = append(ils.Spans, span(s))
+	}
+
+	return result
+}
+
+func span(s *export.SpanData) *tracepb.Span {
+	traceID, spanID := s.SpanContext.TraceID, s.SpanContext.SpanID
+
+	p := &tracepb.Span{
+		TraceId:                traceID[:],
+		SpanId:                 spanID[:],
+		Name:                   s.Name,
+		Kind:                   spanKind(s.SpanKind),
+		StartTimeUnixNano:      uint64(s.StartTime.UnixNano()),
+		EndTimeUnixNano:        uint64(s.EndTime.UnixNano()),
+		Attributes:             keyValues(s.Attributes),
+		DroppedAttributesCount: uint32(s.DroppedAttributeCount),
+		DroppedEventsCount:     uint32(s.DroppedMessageEventCount),
+		DroppedLinksCount:      uint32(s.DroppedLinkCount),
+		Status: &tracepb.Status{
+			Code:    statusCode(s.StatusCode),
+			Message: s.StatusMessage,
+		},
+	}
+
+	if s.ParentSpanID.IsValid() {
+		parentID := s.ParentSpanID
+		p.ParentSpanId = parentID[:]
+	}
+
+	for _, e := range s.MessageEvents {
+		p.Events = append(p.Events, &tracepb.Span_Event{
+			TimeUnixNano: uint64(e.Time.UnixNano()),
+			Name:         e.Name,
+			Attributes:   keyValues(e.Attributes),
+		})
+	}
+
+	for _, l := range s.Links {
+		traceID, spanID := l.TraceID, l.SpanID
+
+		p.Links = append(p.Links, &tracepb.Span_Link{
+			TraceId:    traceID[:],
+			SpanId:     spanID[:],
+			Attributes: keyValues(l.Attributes),
+		})
+	}
+
+	return p
+}
+
+func spanKind(k trace.SpanKind) tracepb.Span_SpanKind {
+	switch k {
+	case trace.SpanKindInternal:
+		return tracepb.Span_SPAN_KIND_INTERNAL
+	case trace.SpanKindServer:
+		return tracepb.Span_SPAN_KIND_SERVER
+	case trace.SpanKindClient:
+		return tracepb.Span_SPAN_KIND_CLIENT
+	case trace.SpanKindProducer:
+		return tracepb.Span_SPAN_KIND_PRODUCER
+	case trace.SpanKindConsumer:
+		return tracepb.Span_SPAN_KIND_CONSUMER
+	default:
+		return tracepb.Span_SPAN_KIND_UNSPECIFIED
+	}
+}
+
+func statusCode(c codes.Code) tracepb.Status_StatusCode {
+	switch c {
+	case codes.Ok:
+		return tracepb.Status_STATUS_CODE_OK
+	case codes.Error:
+		return tracepb.Status_STATUS_CODE_ERROR
+	default:
+		return tracepb.Status_STATUS_CODE_UNSET
+	}
+}
+
+func keyValues(labels []label.KeyValue) []*commonpb.KeyValue {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	kvs := make([]*commonpb.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		kvs = append(kvs, &commonpb.KeyValue{
+			Key:   string(l.Key),
+			Value: anyValue(l.Value),
+		})
+	}
+
+	return kvs
+}
+
+func anyValue(v label.Value) *commonpb.AnyValue {
+	switch v.Type() {
+	case label.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case label.INT32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v.AsInt32())}}
+	case label.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case label.UINT32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v.AsUint32())}}
+	case label.UINT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v.AsUint64())}}
+	case label.FLOAT32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: float64(v.AsFloat32())}}
+	case label.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	default:
+		// Strings, and arrays in their string representation.
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Emit()}}
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ export.SpanExporter = &otlpHTTPExporter{}
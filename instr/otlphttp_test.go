@@ -0,0 +1,167 @@
+package instr
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+type OTLPHTTPTestSuite struct {
+	suite.Suite
+
+	ctx      context.Context
+	srv      *httptest.Server
+	exporter *otlpHTTPExporter
+
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (s *OTLPHTTPTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.status = http.StatusOK
+	s.requests = nil
+	s.bodies = nil
+
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		s.NoError(err)
+
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+
+		w.WriteHeader(s.status)
+	}))
+
+	s.exporter = newOTLPHTTPExporter(strings.TrimPrefix(s.srv.URL, "http://"), true)
+}
+
+func (s *OTLPHTTPTestSuite) TearDownTest() {
+	s.srv.Close()
+}
+
+// decode parses an ExportTraceServiceRequest into its ResourceSpans.
+func (s *OTLPHTTPTestSuite) decode(b []byte) []*tracepb.ResourceSpans {
+	var result []*tracepb.ResourceSpans
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		s.Require().True(n > 0)
+		s.Require().Equal(protowire.Number(1), num)
+		s.Require().Equal(protowire.BytesType, typ)
+		b = b[n:]
+
+		m, n := protowire.ConsumeBytes(b)
+		s.Require().True(n > 0)
+		b = b[n:]
+
+		rs := &tracepb.ResourceSpans{}
+		s.Require().NoError(proto.Unmarshal(m, rs))
+		result = append(result, rs)
+	}
+
+	return result
+}
+
+func (s *OTLPHTTPTestSuite) TestExportSpans() {
+	start := time.Now()
+
+	span := &export.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID: trace.ID{1},
+			SpanID:  trace.SpanID{2},
+		},
+		ParentSpanID: trace.SpanID{3},
+		SpanKind:     trace.SpanKindConsumer,
+		Name:         "crawler.Crawl",
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		Attributes:   []label.KeyValue{label.String("cid", "Qm"), label.Int64("size", 5)},
+		MessageEvents: []export.Event{
+			{Name: "large-directory", Time: start},
+		},
+		StatusCode:             codes.Error,
+		StatusMessage:          "failed",
+		Resource:               resource.New(label.String("service.name", "test")),
+		InstrumentationLibrary: instrumentation.Library{Name: "github.com/ipfs-search"},
+	}
+
+	err := s.exporter.ExportSpans(s.ctx, []*export.SpanData{span})
+	s.NoError(err)
+
+	s.Require().Len(s.requests, 1)
+	s.Equal(http.MethodPost, s.requests[0].Method)
+	s.Equal(otlpHTTPPath, s.requests[0].URL.Path)
+	s.Equal("application/x-protobuf", s.requests[0].Header.Get("Content-Type"))
+
+	rss := s.decode(s.bodies[0])
+	s.Require().Len(rss, 1)
+
+	attrs := rss[0].Resource.Attributes
+	s.Require().Len(attrs, 1)
+	s.Equal("service.name", attrs[0].Key)
+	s.Equal("test", attrs[0].Value.GetStringValue())
+
+	s.Require().Len(rss[0].InstrumentationLibrarySpans, 1)
+	ils := rss[0].InstrumentationLibrarySpans[0]
+	s.Equal("github.com/ipfs-search", ils.InstrumentationLibrary.Name)
+
+	s.Require().Len(ils.Spans, 1)
+	p := ils.Spans[0]
+
+	traceID, spanID, parentID := span.SpanContext.TraceID, span.SpanContext.SpanID, span.ParentSpanID
+	s.Equal(traceID[:], p.TraceId)
+	s.Equal(spanID[:], p.SpanId)
+	s.Equal(parentID[:], p.ParentSpanId)
+	s.Equal("crawler.Crawl", p.Name)
+	s.Equal(tracepb.Span_SPAN_KIND_CONSUMER, p.Kind)
+	s.Equal(uint64(start.UnixNano()), p.StartTimeUnixNano)
+	s.Equal(uint64(span.EndTime.UnixNano()), p.EndTimeUnixNano)
+	s.Equal(tracepb.Status_STATUS_CODE_ERROR, p.Status.Code)
+	s.Equal("failed", p.Status.Message)
+
+	s.Require().Len(p.Attributes, 2)
+	s.Equal("Qm", p.Attributes[0].Value.GetStringValue())
+	s.Equal(int64(5), p.Attributes[1].Value.GetIntValue())
+
+	s.Require().Len(p.Events, 1)
+	s.Equal("large-directory", p.Events[0].Name)
+}
+
+func (s *OTLPHTTPTestSuite) TestExportNoSpans() {
+	err := s.exporter.ExportSpans(s.ctx, nil)
+	s.NoError(err)
+	s.Empty(s.requests)
+}
+
+func (s *OTLPHTTPTestSuite) TestExportError() {
+	s.status = http.StatusServiceUnavailable
+
+	span := &export.SpanData{
+		Name:     "crawler.Crawl",
+		Resource: resource.New(),
+	}
+
+	err := s.exporter.ExportSpans(s.ctx, []*export.SpanData{span})
+	s.Error(err)
+}
+
+func TestOTLPHTTPTestSuite(t *testing.T) {
+	suite.Run(t, new(OTLPHTTPTestSuite))
+}
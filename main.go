@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"github.com/ipfs-search/ipfs-search/commands"
 	"github.com/ipfs-search/ipfs-search/config"
+	t "github.com/ipfs-search/ipfs-search/types"
 	"gopkg.in/urfave/cli.v1"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 )
 
@@ -26,10 +29,26 @@ func main() {
 
 	app.Commands = []cli.Command{
 		{
-			Name:    "add",
-			Aliases: []string{"a"},
-			Usage:   "add `HASH` to crawler queue",
-			Action:  add,
+			Name:      "add",
+			Aliases:   []string{"a"},
+			Usage:     "add CIDs or IPFS paths to crawler queues, from arguments, a file or standard input",
+			ArgsUsage: "[CID|/ipfs/path...]",
+			Action:    add,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "read CIDs or paths, one per line, from `FILE`; - for standard input",
+				},
+				cli.StringFlag{
+					Name:  "type, t",
+					Usage: "add to the queue for `TYPE` file or directory",
+				},
+				cli.UintFlag{
+					Name:  "priority, p",
+					Value: 9,
+					Usage: "queue priority from 0 to 9, higher is crawled first",
+				},
+			},
 		},
 		{
 			Name:    "crawl",
@@ -37,6 +56,50 @@ func main() {
 			Usage:   "start crawler",
 			Action:  crawl,
 		},
+		{
+			Name:      "crawl-one",
+			Usage:     "crawl a single resource in-process, printing the results",
+			ArgsUsage: "CID|/ipfs/path",
+			Action:    crawlOne,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "do not write to the indexes",
+				},
+			},
+		},
+		{
+			Name:  "index",
+			Usage: "manage indexes",
+			Subcommands: []cli.Command{
+				{
+					Name:   "init",
+					Usage:  "create indexes which do not exist yet",
+					Action: indexCommand(commands.IndexInit),
+				},
+				{
+					Name:   "migrate",
+					Usage:  "migrate indexes to current mappings",
+					Action: indexCommand(commands.IndexMigrate),
+				},
+				{
+					Name:   "status",
+					Usage:  "show index versions and document counts",
+					Action: indexCommand(commands.IndexStatus),
+				},
+			},
+		},
+		{
+			Name:  "queue",
+			Usage: "manage queues",
+			Subcommands: []cli.Command{
+				{
+					Name:   "requeue-dead",
+					Usage:  "move dead-lettered resources back to their queues",
+					Action: requeueDead,
+				},
+			},
+		},
 		{
 			Name:    "config",
 			Aliases: []string{},
@@ -121,25 +184,84 @@ func dumpConfig(c *cli.Context) error {
 	return cfg.Dump()
 }
 
+// getAddInput returns the input for the add command: the arguments, optionally followed by the file given by the
+// file flag, or standard input when neither is given.
+func getAddInput(c *cli.Context) (io.Reader, func() error, error) {
+	var readers []io.Reader
+
+	closer := func() error { return nil }
+
+	if c.NArg() > 0 {
+		readers = append(readers, strings.NewReader(strings.Join(c.Args(), "\n")+"\n"))
+	}
+
+	switch filename := c.String("file"); filename {
+	case "":
+		if len(readers) == 0 {
+			readers = append(readers, os.Stdin)
+		}
+	case "-":
+		readers = append(readers, os.Stdin)
+	default:
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		readers = append(readers, f)
+		closer = f.Close
+	}
+
+	return io.MultiReader(readers...), closer, nil
+}
+
+// parseType returns the resource type for a type hint.
+func parseType(hint string) (t.ResourceType, error) {
+	switch hint {
+	case "":
+		return t.UndefinedType, nil
+	case t.FileType.String():
+		return t.FileType, nil
+	case t.DirectoryType.String():
+		return t.DirectoryType, nil
+	default:
+		return t.UndefinedType, fmt.Errorf("invalid type '%s', should be file or directory", hint)
+	}
+}
+
 func add(c *cli.Context) error {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Allow SIGTERM / Control-C quit through context
 	onSigTerm(cancel)
 
-	if c.NArg() != 1 {
-		return cli.NewExitError("Please supply one hash as argument.", 1)
+	rType, err := parseType(c.String("type"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	priority := c.Uint("priority")
+	if priority > 9 {
+		return cli.NewExitError("Priority should be between 0 and 9.", 1)
 	}
-	hash := c.Args().Get(0)
 
 	cfg, err := getConfig(c)
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	fmt.Printf("Adding hash '%s' to queue\n", hash)
+	input, closeInput, err := getAddInput(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	defer closeInput()
+
+	opts := &commands.AddOptions{
+		Type:     rType,
+		Priority: uint8(priority),
+	}
 
-	err = commands.AddHash(ctx, cfg, hash)
+	err = commands.Add(ctx, cfg, input, opts)
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
@@ -147,6 +269,45 @@ func add(c *cli.Context) error {
 	return nil
 }
 
+func requeueDead(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	if err := commands.RequeueDead(ctx, cfg); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+// indexCommand returns an action running an index management command.
+func indexCommand(f func(context.Context, *config.Config) error) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Allow SIGTERM / Control-C quit through context
+		onSigTerm(cancel)
+
+		cfg, err := getConfig(c)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+
+		if err := f(ctx, cfg); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+
+		return nil
+	}
+}
+
 // onSigTerm calls f() when SIGTERM (control-C) is received
 func onSigTerm(f func()) {
 	sigChan := make(chan os.Signal, 2)
@@ -169,6 +330,30 @@ func onSigTerm(f func()) {
 	go quit()
 }
 
+// onSigHup returns a channel on which the configuration is sent after reloading it when SIGHUP is received.
+func onSigHup(c *cli.Context) <-chan *config.Config {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+
+	reloads := make(chan *config.Config)
+
+	go func() {
+		for range sigChan {
+			fmt.Println("Received SIGHUP, reloading configuration.")
+
+			cfg, err := getConfig(c)
+			if err != nil {
+				log.Printf("Error reloading configuration: %v", err)
+				continue
+			}
+
+			reloads <- cfg
+		}
+	}()
+
+	return reloads
+}
+
 func crawl(c *cli.Context) error {
 	fmt.Println("Starting worker")
 
@@ -182,7 +367,30 @@ func crawl(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	err = commands.Crawl(ctx, cfg)
+	err = commands.Crawl(ctx, cfg, onSigHup(c))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func crawlOne(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	if c.NArg() != 1 {
+		return cli.NewExitError("Please supply one CID or IPFS path as argument.", 1)
+	}
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	err = commands.CrawlOne(ctx, cfg, c.Args().Get(0), c.Bool("dry-run"))
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
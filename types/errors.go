@@ -1,7 +1,10 @@
 package types
 
 import (
+	"context"
 	"errors"
+	"net"
+	"syscall"
 )
 
 // WrappedError is a generic error type wrapping underlying errors.
@@ -26,4 +29,97 @@ var (
 
 	// ErrUnsupportedType is returned when the type of a resource is currently unsupported.
 	ErrUnsupportedType = WrappedError{ErrInvalidResource, "unsupported type"}
+
+	// ErrTransient is returned for temporary failures, which are likely to succeed when retried later.
+	ErrTransient = errors.New("transient error")
+
+	// ErrBackendUnavailable is returned when a backend (IPFS, Tika, index or queue) is unreachable or overloaded.
+	ErrBackendUnavailable = errors.New("backend unavailable")
+
+	// ErrPermanent is returned for failures which will not succeed when retried.
+	ErrPermanent = errors.New("permanent error")
 )
+
+// ClassifiedError annotates an underlying error with one of the error classes (ErrTransient,
+// ErrBackendUnavailable, ErrInvalidResource or ErrPermanent), retaining the original error chain.
+type ClassifiedError struct {
+	Class error
+	Err   error
+}
+
+// Unwrap returns the underlying error.
+func (e ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true when target is the class of the error.
+func (e ClassifiedError) Is(target error) bool {
+	return target == e.Class
+}
+
+// Error returns the class, followed by the message of the underlying error.
+func (e ClassifiedError) Error() string {
+	return e.Class.Error() + ": " + e.Err.Error()
+}
+
+// Transient classifies err as ErrTransient.
+func Transient(err error) error {
+	return ClassifiedError{ErrTransient, err}
+}
+
+// BackendUnavailable classifies err as ErrBackendUnavailable.
+func BackendUnavailable(err error) error {
+	return ClassifiedError{ErrBackendUnavailable, err}
+}
+
+// Invalid classifies err as ErrInvalidResource.
+func Invalid(err error) error {
+	return ClassifiedError{ErrInvalidResource, err}
+}
+
+// Permanent classifies err as ErrPermanent.
+func Permanent(err error) error {
+	return ClassifiedError{ErrPermanent, err}
+}
+
+// ErrorClass returns the class of an error: ErrTransient, ErrBackendUnavailable, ErrInvalidResource or ErrPermanent.
+// Errors which have not been explicitly classified are considered ErrBackendUnavailable when failing to connect,
+// ErrTransient otherwise. Returns nil for nil errors.
+func ErrorClass(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// The outermost classification takes precedence.
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if c, ok := e.(ClassifiedError); ok {
+			return c.Class
+		}
+
+		switch e {
+		case ErrInvalidResource, ErrPermanent, ErrBackendUnavailable, ErrTransient:
+			return e
+		}
+	}
+
+	if isConnErr(err) {
+		return ErrBackendUnavailable
+	}
+
+	// Includes context.DeadlineExceeded; unclassified errors are retried rather than losing resources.
+	return ErrTransient
+}
+
+// isConnErr returns true when err is the result of failing to connect to a server.
+func isConnErr(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
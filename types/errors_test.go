@@ -0,0 +1,54 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorsTestSuite struct {
+	suite.Suite
+}
+
+func (s *ErrorsTestSuite) TestClassified() {
+	cause := errors.New("cause")
+
+	err := fmt.Errorf("wrapped: %w", Transient(cause))
+
+	s.True(errors.Is(err, ErrTransient))
+	s.True(errors.Is(err, cause))
+	s.False(errors.Is(err, ErrPermanent))
+	s.Equal("wrapped: transient error: cause", err.Error())
+}
+
+func (s *ErrorsTestSuite) TestErrorClass() {
+	s.Nil(ErrorClass(nil))
+	s.Equal(ErrTransient, ErrorClass(Transient(errors.New("cause"))))
+	s.Equal(ErrBackendUnavailable, ErrorClass(BackendUnavailable(errors.New("cause"))))
+	s.Equal(ErrPermanent, ErrorClass(Permanent(errors.New("cause"))))
+	s.Equal(ErrInvalidResource, ErrorClass(Invalid(errors.New("cause"))))
+	s.Equal(ErrInvalidResource, ErrorClass(ErrUnsupportedType))
+	s.Equal(ErrInvalidResource, ErrorClass(fmt.Errorf("%w: cause", ErrInvalidResource)))
+}
+
+func (s *ErrorsTestSuite) TestErrorClassOutermost() {
+	err := Permanent(fmt.Errorf("%w: cause", ErrInvalidResource))
+
+	s.Equal(ErrPermanent, ErrorClass(err))
+}
+
+func (s *ErrorsTestSuite) TestErrorClassUnclassified() {
+	s.Equal(ErrTransient, ErrorClass(errors.New("unknown")))
+	s.Equal(ErrTransient, ErrorClass(context.DeadlineExceeded))
+
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	s.Equal(ErrBackendUnavailable, ErrorClass(fmt.Errorf("request: %w", dialErr)))
+}
+
+func TestErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsTestSuite))
+}
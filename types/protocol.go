@@ -6,14 +6,18 @@ type Protocol uint8
 const (
 	// InvalidProtocol (default) value signifies an invalid protocol.
 	InvalidProtocol Protocol = iota
-	// IPFSProtocol (currently only supported protocol)
+	// IPFSProtocol is the Interplanetary Filesystem.
 	IPFSProtocol
+	// FilesystemProtocol is a local filesystem, resources being identified by their absolute path within its root.
+	FilesystemProtocol
 )
 
 func (p Protocol) String() string {
 	switch p {
 	case IPFSProtocol:
 		return "ipfs"
+	case FilesystemProtocol:
+		return "file"
 	default:
 		panic("Invalid value for Protocol.")
 	}
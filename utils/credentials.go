@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrConflictingCredentials is returned when more than one kind of credentials is specified.
+	ErrConflictingCredentials = errors.New("only one of basic authentication, API key or bearer token can be used")
+
+	// ErrCrossHostRedirect is returned when a backend authenticated with credentials redirects to another host.
+	ErrCrossHostRedirect = errors.New("refusing redirect to another host")
+)
+
+// maxRedirects is the number of redirects followed, as by http.Client by default.
+const maxRedirects = 10
+
+// Credentials specifies credentials for authenticating with a backend. Secrets can be read from files, e.g.
+// mounted secrets, which take precedence over values specified directly.
+type Credentials struct {
+	Username        string // Username for basic authentication.
+	Password        string // Password for basic authentication.
+	PasswordFile    string // File containing the password for basic authentication.
+	APIKey          string // API key, sent as `Authorization: ApiKey <key>`, e.g. for Elasticsearch.
+	APIKeyFile      string // File containing the API key.
+	BearerToken     string // Bearer token, sent as `Authorization: Bearer <token>`, e.g. for Kubo.
+	BearerTokenFile string // File containing the bearer token.
+}
+
+// readSecret returns the contents of file without surrounding whitespace, or value when no file is specified.
+func readSecret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+
+	secret, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("reading secret: %w", err)
+	}
+
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// Load returns the credentials with secrets read from their files, or nil when no credentials are specified.
+func (c *Credentials) Load() (*Credentials, error) {
+	if c == nil || *c == (Credentials{}) {
+		return nil, nil
+	}
+
+	var (
+		loaded = Credentials{Username: c.Username}
+		err    error
+	)
+
+	if loaded.Password, err = readSecret(c.Password, c.PasswordFile); err != nil {
+		return nil, err
+	}
+
+	if loaded.APIKey, err = readSecret(c.APIKey, c.APIKeyFile); err != nil {
+		return nil, err
+	}
+
+	if loaded.BearerToken, err = readSecret(c.BearerToken, c.BearerTokenFile); err != nil {
+		return nil, err
+	}
+
+	kinds := 0
+	for _, used := range []bool{loaded.IsBasic(), loaded.APIKey != "", loaded.BearerToken != ""} {
+		if used {
+			kinds++
+		}
+	}
+
+	if kinds > 1 {
+		return nil, ErrConflictingCredentials
+	}
+
+	return &loaded, nil
+}
+
+// IsBasic returns whether the credentials are for basic authentication.
+func (c *Credentials) IsBasic() bool {
+	return c.Username != "" || c.Password != ""
+}
+
+// authorize sets the Authorization header of a request for loaded credentials.
+func (c *Credentials) authorize(req *http.Request) {
+	switch {
+	case c.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	case c.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.APIKey)
+	case c.IsBasic():
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// authTransport authenticates requests with credentials, unless they are already authorized.
+type authTransport struct {
+	http.RoundTripper
+	credentials *Credentials
+}
+
+// checkRedirect refuses redirects to hosts other than the one originally requested, as authTransport would send
+// them the credentials for the backend; http.Client only strips these when it set them itself.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Host != via[0].URL.Host {
+		return fmt.Errorf("%w: %s", ErrCrossHostRedirect, req.URL.Host)
+	}
+
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	return nil
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		// Round trippers should not modify requests.
+		req = req.Clone(req.Context())
+		t.credentials.authorize(req)
+	}
+
+	return t.RoundTripper.RoundTrip(req)
+}
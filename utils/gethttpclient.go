@@ -11,16 +11,41 @@ import (
 
 // GetHTTPClient initializes a HTTP client with OpenTelemetry transport for tracing.
 func GetHTTPClient(dialcontext func(ctx context.Context, network, address string) (net.Conn, error), maxConns int) *http.Client {
-	transport := otelhttp.NewTransport(&http.Transport{
+	client, _ := GetSecureHTTPClient(dialcontext, maxConns, nil, nil)
+	return client
+}
+
+// GetSecureHTTPClient initializes a HTTP client like GetHTTPClient, connecting with TLS settings and authenticating
+// requests with credentials when specified. Certificates and secrets are read from their files once.
+func GetSecureHTTPClient(dialcontext func(ctx context.Context, network, address string) (net.Conn, error), maxConns int, tlsConfig *TLSConfig, credentials *Credentials) (*http.Client, error) {
+	tlsClientConfig, err := tlsConfig.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := credentials.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var transport http.RoundTripper = &http.Transport{
 		Proxy:               nil,
 		DialContext:         dialcontext,
+		TLSClientConfig:     tlsClientConfig,
 		ForceAttemptHTTP2:   false,
 		MaxIdleConns:        maxConns,
 		MaxIdleConnsPerHost: maxConns,
 		IdleConnTimeout:     90 * time.Second,
-	})
+	}
+
+	client := &http.Client{}
 
-	return &http.Client{
-		Transport: transport,
+	if loaded != nil {
+		transport = &authTransport{transport, loaded}
+		client.CheckRedirect = checkRedirect
 	}
+
+	client.Transport = otelhttp.NewTransport(transport)
+
+	return client, nil
 }
@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SecureHTTPClientTestSuite struct {
+	suite.Suite
+
+	dir    string
+	server *httptest.Server
+	auth   chan string
+	dialer *net.Dialer
+}
+
+func (s *SecureHTTPClientTestSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "ipfs-search-tls")
+	s.Require().NoError(err)
+
+	s.auth = make(chan string, 1)
+	s.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.auth <- r.Header.Get("Authorization")
+	}))
+
+	s.dialer = &net.Dialer{}
+}
+
+func (s *SecureHTTPClientTestSuite) TearDownTest() {
+	s.server.Close()
+	os.RemoveAll(s.dir)
+}
+
+// writeFile writes data to a file in the test directory, returning its path.
+func (s *SecureHTTPClientTestSuite) writeFile(name string, data []byte) string {
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(ioutil.WriteFile(path, data, 0600))
+
+	return path
+}
+
+// caFile writes the certificate of the test server to a PEM file.
+func (s *SecureHTTPClientTestSuite) caFile() string {
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.server.Certificate().Raw})
+	return s.writeFile("ca.pem", cert)
+}
+
+// get requests the test server, returning the Authorization header it received.
+func (s *SecureHTTPClientTestSuite) get(client *http.Client) (string, error) {
+	resp, err := client.Get(s.server.URL)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return <-s.auth, nil
+}
+
+func (s *SecureHTTPClientTestSuite) TestUnknownAuthority() {
+	client, err := GetSecureHTTPClient(s.dialer.DialContext, 1, nil, nil)
+	s.Require().NoError(err)
+
+	_, err = s.get(client)
+	s.Error(err)
+}
+
+func (s *SecureHTTPClientTestSuite) TestCAFile() {
+	client, err := GetSecureHTTPClient(s.dialer.DialContext, 1, &TLSConfig{CAFile: s.caFile()}, nil)
+	s.Require().NoError(err)
+
+	auth, err := s.get(client)
+	s.NoError(err)
+	s.Empty(auth)
+}
+
+func (s *SecureHTTPClientTestSuite) TestServerName() {
+	// The test certificate is valid for example.com but not for example.org.
+	tlsConfig := &TLSConfig{CAFile: s.caFile(), ServerName: "example.com"}
+	client, err := GetSecureHTTPClient(s.dialer.DialContext, 1, tlsConfig, nil)
+	s.Require().NoError(err)
+
+	_, err = s.get(client)
+	s.NoError(err)
+
+	tlsConfig.ServerName = "example.org"
+	client, err = GetSecureHTTPClient(s.dialer.DialContext, 1, tlsConfig, nil)
+	s.Require().NoError(err)
+
+	_, err = s.get(client)
+	s.Error(err)
+}
+
+func (s *SecureHTTPClientTestSuite) TestInvalidCAFile() {
+	_, err := GetSecureHTTPClient(s.dialer.DialContext, 1, &TLSConfig{CAFile: s.writeFile("ca.pem", []byte("junk"))}, nil)
+	s.Error(err)
+
+	_, err = GetSecureHTTPClient(s.dialer.DialContext, 1, &TLSConfig{CAFile: filepath.Join(s.dir, "missing.pem")}, nil)
+	s.Error(err)
+}
+
+func (s *SecureHTTPClientTestSuite) TestCredentials() {
+	tlsConfig := &TLSConfig{CAFile: s.caFile()}
+
+	for _, c := range []struct {
+		credentials *Credentials
+		expected    string
+	}{
+		{&Credentials{Username: "user", Password: "pass"}, "Basic dXNlcjpwYXNz"},
+		{&Credentials{Username: "user", PasswordFile: s.writeFile("password", []byte("pass\n"))}, "Basic dXNlcjpwYXNz"},
+		{&Credentials{APIKey: "key"}, "ApiKey key"},
+		{&Credentials{APIKeyFile: s.writeFile("key", []byte("key\n"))}, "ApiKey key"},
+		{&Credentials{BearerToken: "token"}, "Bearer token"},
+		{&Credentials{BearerTokenFile: s.writeFile("token", []byte(" token "))}, "Bearer token"},
+	} {
+		client, err := GetSecureHTTPClient(s.dialer.DialContext, 1, tlsConfig, c.credentials)
+		s.Require().NoError(err)
+
+		auth, err := s.get(client)
+		s.NoError(err)
+		s.Equal(c.expected, auth)
+	}
+}
+
+func (s *SecureHTTPClientTestSuite) TestExistingAuthorization() {
+	client, err := GetSecureHTTPClient(s.dialer.DialContext, 1, &TLSConfig{CAFile: s.caFile()}, &Credentials{APIKey: "key"})
+	s.Require().NoError(err)
+
+	req, err := http.NewRequest(http.MethodGet, s.server.URL, nil)
+	s.Require().NoError(err)
+	req.Header.Set("Authorization", "Bearer other")
+
+	resp, err := client.Do(req)
+	s.Require().NoError(err)
+	resp.Body.Close()
+
+	s.Equal("Bearer other", <-s.auth)
+}
+
+func (s *SecureHTTPClientTestSuite) TestRedirect() {
+	redirecting := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirected" {
+			s.auth <- r.Header.Get("Authorization")
+			return
+		}
+
+		http.Redirect(w, r, "/redirected", http.StatusFound)
+	}))
+	defer redirecting.Close()
+
+	client, err := GetSecureHTTPClient(s.dialer.DialContext, 1, &TLSConfig{CAFile: s.caFile()}, &Credentials{APIKey: "key"})
+	s.Require().NoError(err)
+
+	resp, err := client.Get(redirecting.URL)
+	s.Require().NoError(err)
+	resp.Body.Close()
+
+	// Same host; still authorized.
+	s.Equal("ApiKey key", <-s.auth)
+}
+
+func (s *SecureHTTPClientTestSuite) TestCrossHostRedirect() {
+	redirecting := httptest.NewTLSServer(http.RedirectHandler(s.server.URL, http.StatusFound))
+	defer redirecting.Close()
+
+	client, err := GetSecureHTTPClient(s.dialer.DialContext, 1, &TLSConfig{CAFile: s.caFile()}, &Credentials{APIKey: "key"})
+	s.Require().NoError(err)
+
+	_, err = client.Get(redirecting.URL)
+	s.True(errors.Is(err, ErrCrossHostRedirect))
+
+	// Credentials not sent to the other host.
+	select {
+	case auth := <-s.auth:
+		s.Failf("unexpected request", "to other host, authorization: %q", auth)
+	default:
+	}
+}
+
+func (s *SecureHTTPClientTestSuite) TestConflictingCredentials() {
+	_, err := GetSecureHTTPClient(s.dialer.DialContext, 1, nil, &Credentials{Username: "user", APIKey: "key"})
+	s.Equal(ErrConflictingCredentials, err)
+}
+
+func (s *SecureHTTPClientTestSuite) TestMissingSecretFile() {
+	_, err := GetSecureHTTPClient(s.dialer.DialContext, 1, nil, &Credentials{BearerTokenFile: filepath.Join(s.dir, "missing")})
+	s.Error(err)
+}
+
+func TestSecureHTTPClientTestSuite(t *testing.T) {
+	suite.Run(t, new(SecureHTTPClientTestSuite))
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ShutdownTimeout is the time servers started by Serve wait for requests in progress when shutting down.
+const ShutdownTimeout = 5 * time.Second
+
+// Serve serves handler on address in the background until ctx is done, after which the server is shut down
+// gracefully. It listens before returning, so that an unavailable address is reported. Name describes what is
+// served, for logging.
+func Serve(ctx context.Context, address string, handler http.Handler, name string) error {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Handler: handler}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server for %s: %v", name, err)
+		}
+	}()
+
+	go func() {
+		log.Printf("Serving %s on http://%s/", name, l.Addr())
+
+		if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Printf("Error serving %s: %v", name, err)
+		}
+	}()
+
+	return nil
+}
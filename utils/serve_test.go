@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ServeTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cancel func()
+	addr   string
+}
+
+func (s *ServeTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	// Find a free address.
+	l, err := net.Listen("tcp", "localhost:0")
+	s.Require().NoError(err)
+	s.addr = l.Addr().String()
+	l.Close()
+}
+
+func (s *ServeTestSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *ServeTestSuite) TestServeUntilDone() {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	s.Require().NoError(Serve(s.ctx, s.addr, handler, "test"))
+
+	resp, err := http.Get("http://" + s.addr + "/")
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	s.cancel()
+
+	s.Eventually(func() bool {
+		_, err := net.Dial("tcp", s.addr)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *ServeTestSuite) TestAddressInUse() {
+	s.Require().NoError(Serve(s.ctx, s.addr, http.NotFoundHandler(), "test"))
+	s.Error(Serve(s.ctx, s.addr, http.NotFoundHandler(), "test"))
+}
+
+func TestServeTestSuite(t *testing.T) {
+	suite.Run(t, new(ServeTestSuite))
+}
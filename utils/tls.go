@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// ErrNoCertificates is returned when a CA file contains no PEM certificates.
+var ErrNoCertificates = errors.New("no certificates found")
+
+// TLSConfig specifies TLS settings for connecting to a backend.
+type TLSConfig struct {
+	CAFile     string // PEM file with CA certificates to verify the server with, instead of the system roots.
+	CertFile   string // PEM file with a client certificate, for mutual TLS.
+	KeyFile    string // PEM file with the private key of the client certificate.
+	ServerName string // Name to verify the server certificate for, when it differs from the host connected to.
+}
+
+// Load returns the TLS configuration with certificates read from their files, or nil when no settings are
+// specified, such that defaults apply.
+func (c *TLSConfig) Load() (*tls.Config, error) {
+	if c == nil || *c == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName: c.ServerName,
+	}
+
+	if c.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w in %s", ErrNoCertificates, c.CAFile)
+		}
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}